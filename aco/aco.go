@@ -0,0 +1,374 @@
+// Package aco provides ant colony optimization for permutation problems.
+//
+// An ant colony searches for good permutations, e.g. short tours of a traveling
+// salesman problem, by repeated probabilistic construction. Each ant builds a
+// permutation one element at a time, choosing the next element with
+// probability proportional to the pheromone on the edge raised to the power
+// Alpha, times the heuristic desirability of the edge raised to the power Beta.
+// After every iteration, the pheromone evaporates and the best ants deposit
+// pheromone along their permutations, so later ants favor the edges of good
+// solutions. This package implements the rank-based ant system of Bullnheimer,
+// Hartl, and Strauss, with a lower bound on the pheromone as in the MAX-MIN ant
+// system to keep every edge reachable.
+//
+// A Colony is an evo.Population whose members are the *Tour genomes built by
+// the ants. The EvolveFn of the colony is applied to each tour after it is
+// built, with the other tours as suitors, which is the usual place for local
+// search:
+//
+//	colony := aco.New(dim,
+//		func(i, j int) float64 { return 1 / problem.Dist(i, j) },
+//		func(path []int) float64 { return -length(path) })
+//	colony.Evolve(make([]evo.Genome, 32), func(current evo.Genome, _ []evo.Genome) evo.Genome {
+//		t := current.(*aco.Tour)
+//		twoOpt(t.Path)
+//		t.Invalidate()
+//		return t
+//	})
+package aco
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Tour is a permutation built by an ant.
+type Tour struct {
+	evo.Cache
+	Path    []int
+	fitness func(path []int) float64
+}
+
+// Fitness returns the fitness of the path, as given by the colony.
+func (t *Tour) Fitness() float64 {
+	return t.Cache.Fitness(func() float64 {
+		return t.fitness(t.Path)
+	})
+}
+
+// A Colony is a population of ants. The exported parameters may be changed
+// before Evolve is called.
+type Colony struct {
+	Alpha float64 // the weight of the pheromone, default 1
+	Beta  float64 // the weight of the heuristic, default 2
+	Rho   float64 // the rate of evaporation, default 0.1
+	Rank  int     // the number of ranked ants which deposit, default 6
+	Min   float64 // the lower bound of the pheromone, default 1e-3
+
+	n         int
+	heuristic func(i, j int) float64
+	fitness   func(path []int) float64
+
+	mu      sync.Mutex
+	tau     [][]float64  // the pheromone of each edge
+	members []evo.Genome // the tours of the last iteration
+
+	meter *evo.Meter
+	best  *evo.Tracker
+	iters *atomic.Int64
+	stopc chan chan struct{}
+}
+
+// New returns a colony for permutations of n elements. The heuristic gives
+// the desirability of moving from element i to element j, e.g. the inverse of
+// the distance between two cities, and must be positive. The fitness of a
+// permutation is maximized.
+func New(n int, heuristic func(i, j int) float64, fitness func(path []int) float64) *Colony {
+	return &Colony{
+		Alpha:     1,
+		Beta:      2,
+		Rho:       0.1,
+		Rank:      6,
+		Min:       1e-3,
+		n:         n,
+		heuristic: heuristic,
+		fitness:   fitness,
+	}
+}
+
+// NewTour returns a tour of the path whose fitness is given by the colony,
+// e.g. to seed the colony with known solutions.
+func (c *Colony) NewTour(path []int) *Tour {
+	return &Tour{Path: path, fitness: c.fitness}
+}
+
+// Evolve starts the colony in a separate goroutine with one ant for each
+// member. The members are replaced by the tours of the ants after every
+// iteration. Members which are already *Tour genomes of the right size seed
+// the pheromone before the first iteration. The body is applied to each new
+// tour, with the other tours as suitors, and must return a *Tour; a body which
+// modifies the path of a tour must invalidate its cache.
+func (c *Colony) Evolve(members []evo.Genome, body evo.EvolveFn) {
+	c.members = members
+	c.tau = make([][]float64, c.n)
+	for i := range c.tau {
+		c.tau[i] = make([]float64, c.n)
+		for j := range c.tau[i] {
+			c.tau[i][j] = 1
+		}
+	}
+	var seed []*Tour
+	for _, m := range members {
+		if t, ok := m.(*Tour); ok && len(t.Path) == c.n {
+			if t.fitness == nil {
+				t.fitness = c.fitness
+			}
+			seed = append(seed, t)
+		}
+	}
+	if len(seed) > 0 {
+		c.deposit(seed)
+	}
+	c.meter = evo.NewMeter()
+	c.best = evo.NewTracker(c.meter)
+	c.iters = new(atomic.Int64)
+	c.stopc = make(chan chan struct{}, 1)
+	go c.run(body)
+}
+
+// Stop terminates the colony.
+func (c *Colony) Stop() {
+	ch := make(chan struct{})
+	c.stopc <- ch
+	<-ch
+}
+
+// Poll executes a function at some frequency for the duration of the
+// current optimization. If the function returns true, the current optimization
+// is halted.
+func (c *Colony) Poll(freq time.Duration, cond evo.ConditionFn) {
+	done := c.stopc
+	go func() {
+		for {
+			select {
+			case <-time.After(freq):
+				if cond() {
+					c.Stop()
+					return
+				}
+			case ch := <-done:
+				done <- ch
+				return
+			}
+		}
+	}()
+}
+
+// Wait blocks until the evolution terminates.
+func (c *Colony) Wait() {
+	c.stopc <- <-c.stopc
+}
+
+// Stats returns statistics on the fitness of the tours of the last iteration,
+// tagged with the number of iterations.
+func (c *Colony) Stats() (s evo.Stats) {
+	gen := c.Generation()
+	v := c.View()
+	s = v.Stats().WithGeneration(gen)
+	v.Close()
+	return s
+}
+
+// View returns a snapshot of the tours of the last iteration.
+func (c *Colony) View() evo.View {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return evo.NewView(c.members)
+}
+
+// Fitness returns the maximum fitness of the tours of the last iteration.
+func (c *Colony) Fitness() float64 {
+	return c.Stats().Max()
+}
+
+// Throughput returns the throughput of the colony since Evolve was called. Each
+// tour built counts as an iteration and an evaluation.
+func (c *Colony) Throughput() evo.Throughput {
+	return c.meter.Throughput()
+}
+
+// Generation returns the number of iterations of the colony.
+func (c *Colony) Generation() int {
+	if c.iters == nil {
+		return 0
+	}
+	return int(c.iters.Load())
+}
+
+// Best returns the record of the best tour built since Evolve was called.
+func (c *Colony) Best() evo.Record {
+	if c.best == nil {
+		return evo.Record{}
+	}
+	return c.best.Best()
+}
+
+// Pheromone returns the pheromone on the edge from element i to element j.
+func (c *Colony) Pheromone(i, j int) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.tau[i][j]
+}
+
+// run implements the main goroutine.
+func (c *Colony) run(body evo.EvolveFn) {
+	ants := len(c.members)
+	eta := make([][]float64, c.n)
+	for i := range eta {
+		eta[i] = make([]float64, c.n)
+		for j := range eta[i] {
+			if i != j {
+				eta[i][j] = math.Pow(c.heuristic(i, j), c.Beta)
+			}
+		}
+	}
+
+	for {
+		select {
+		case ch := <-c.stopc:
+			ch <- struct{}{}
+			c.stopc <- ch
+			return
+		default:
+		}
+
+		// build the tours in parallel
+		c.mu.Lock()
+		weight := make([][]float64, c.n)
+		for i := range weight {
+			weight[i] = make([]float64, c.n)
+			for j := range weight[i] {
+				weight[i][j] = math.Pow(c.tau[i][j], c.Alpha) * eta[i][j]
+			}
+		}
+		c.mu.Unlock()
+		built := make([]evo.Genome, ants)
+		var wg sync.WaitGroup
+		for k := range built {
+			wg.Add(1)
+			go func(k int) {
+				built[k] = c.NewTour(c.construct(weight))
+				wg.Done()
+			}(k)
+		}
+		wg.Wait()
+
+		// apply the body to each tour
+		tours := make([]*Tour, ants)
+		for k := range tours {
+			wg.Add(1)
+			go func(k int) {
+				t := built[k]
+				if body != nil {
+					t = body(t, built)
+				}
+				tours[k] = t.(*Tour)
+				if tours[k].fitness == nil {
+					tours[k].fitness = c.fitness
+				}
+				tours[k].Fitness()
+				c.best.Observe(tours[k])
+				c.meter.Iterate()
+				c.meter.Evaluate()
+				wg.Done()
+			}(k)
+		}
+		wg.Wait()
+
+		c.mu.Lock()
+		for k := range tours {
+			c.members[k] = tours[k]
+		}
+		c.mu.Unlock()
+		c.deposit(tours)
+		c.iters.Add(1)
+	}
+}
+
+// construct builds a permutation, choosing each step with probability
+// proportional to the weight of the edge.
+func (c *Colony) construct(weight [][]float64) []int {
+	path := make([]int, 0, c.n)
+	visited := make([]bool, c.n)
+	cur := rand.Intn(c.n)
+	path = append(path, cur)
+	visited[cur] = true
+	for len(path) < c.n {
+		var total float64
+		for j := range weight[cur] {
+			if !visited[j] {
+				total += weight[cur][j]
+			}
+		}
+		next := -1
+		x := rand.Float64() * total
+		for j := range weight[cur] {
+			if visited[j] {
+				continue
+			}
+			next = j
+			if x < weight[cur][j] {
+				break
+			}
+			x -= weight[cur][j]
+		}
+		path = append(path, next)
+		visited[next] = true
+		cur = next
+	}
+	return path
+}
+
+// deposit evaporates the pheromone and lets the best tours deposit. The r-th
+// best of the Rank-1 best tours deposits Rank-r, and the best tour so far
+// deposits Rank. Deposits are scaled so that the pheromone stays near 1.
+func (c *Colony) deposit(tours []*Tour) {
+	ranked := make([]*Tour, len(tours))
+	copy(ranked, tours)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].Fitness() > ranked[j].Fitness()
+	})
+	w := c.Rank
+	if w < 1 {
+		w = 1
+	}
+	norm := c.Rho / float64(w*(w+1)/2)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i := range c.tau {
+		for j := range c.tau[i] {
+			c.tau[i][j] *= 1 - c.Rho
+		}
+	}
+	lay := func(path []int, amount float64) {
+		for i := range path {
+			a, b := path[i], path[(i+1)%len(path)]
+			c.tau[a][b] += amount
+			c.tau[b][a] += amount
+		}
+	}
+	for r := 0; r < w-1 && r < len(ranked); r++ {
+		lay(ranked[r].Path, float64(w-r-1)*norm)
+	}
+	if c.best != nil {
+		if best, ok := c.best.Best().Genome.(*Tour); ok {
+			lay(best.Path, float64(w)*norm)
+		}
+	} else if len(ranked) > 0 {
+		lay(ranked[0].Path, float64(w)*norm)
+	}
+	for i := range c.tau {
+		for j := range c.tau[i] {
+			if c.tau[i][j] < c.Min {
+				c.tau[i][j] = c.Min
+			}
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package aco_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/aco"
+)
+
+var _ evo.Population = new(aco.Colony)
+
+// circle returns n cities evenly spaced on the unit circle, visited in a random
+// order by their indices so the optimal tour is not the identity.
+func circle(n int) (dist func(i, j int) float64, optimum float64) {
+	x := make([]float64, n)
+	y := make([]float64, n)
+	for i := range x {
+		k := (i * 7) % n
+		x[i] = math.Cos(2 * math.Pi * float64(k) / float64(n))
+		y[i] = math.Sin(2 * math.Pi * float64(k) / float64(n))
+	}
+	dist = func(i, j int) float64 {
+		return math.Hypot(x[i]-x[j], y[i]-y[j])
+	}
+	return dist, float64(n) * dist(0, 3) // 3*7 = 1 mod 20: adjacent cities
+}
+
+func TestColony(t *testing.T) {
+	const n = 20
+	dist, optimum := circle(n)
+	length := func(path []int) (l float64) {
+		for i := range path {
+			l += dist(path[i], path[(i+1)%n])
+		}
+		return l
+	}
+	colony := aco.New(n,
+		func(i, j int) float64 { return 1 / dist(i, j) },
+		func(path []int) float64 { return -length(path) })
+	colony.Evolve(make([]evo.Genome, 16), nil)
+	colony.Poll(time.Millisecond, func() bool {
+		return 50 <= colony.Generation()
+	})
+	colony.Wait()
+
+	best := colony.Best()
+	if -best.Fitness > optimum*1.05 {
+		t.Errorf("best tour %v, optimum %v", -best.Fitness, optimum)
+	}
+	if colony.Pheromone(0, 3) <= colony.Pheromone(0, 10) {
+		t.Error("no pheromone on the optimal edges")
+	}
+	if colony.View().Len() != 16 {
+		t.Fail()
+	}
+}
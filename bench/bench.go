@@ -0,0 +1,206 @@
+// Package bench provides canonical continuous benchmark functions.
+//
+// Each benchmark is a Function to be minimized over a box, with a known global
+// optimum. Functions create genomes whose fitness is the negative of the
+// function, ready to be evolved with the operators of the real package:
+//
+//	seed := make([]evo.Genome, 40)
+//	for i := range seed {
+//		seed[i] = bench.Rastrigin.Random(30)
+//	}
+//
+// The functions are centered and separable or nearly so, which flatters some
+// operators. A Suite generates shifted and rotated instances of them, with
+// the domains and biases of the BBOB and CEC'17 suites:
+//
+//	for _, f := range bench.CEC17.Functions(30, seed) {
+//		compare(f)
+//	}
+package bench
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/real"
+)
+
+// A Function is a benchmark function to be minimized. The global minimum is the
+// same in every dimension, unless it is located by At, and the value at the
+// minimum is Min.
+type Function struct {
+	Name         string
+	Eval         func(x []float64) float64
+	Lower, Upper float64     // the bounds of each dimension
+	Opt          float64     // the location of the minimum in each dimension
+	At           real.Vector // the location of the minimum, if not Opt, e.g. when shifted
+	Min          float64     // the value of the minimum
+}
+
+// Optimum returns the location of the global minimum in the given dimension.
+// For functions located by At, the dimension must be that of At.
+func (f *Function) Optimum(dim int) real.Vector {
+	if f.At != nil {
+		return f.At.Copy()
+	}
+	x := make(real.Vector, dim)
+	for i := range x {
+		x[i] = f.Opt
+	}
+	return x
+}
+
+// New returns a genome for the given point.
+func (f *Function) New(x real.Vector) *Genome {
+	return &Genome{X: x, Fn: f}
+}
+
+// Random returns a genome for a point chosen uniformly within the bounds.
+func (f *Function) Random(dim int) *Genome {
+	x := make(real.Vector, dim)
+	for i := range x {
+		x[i] = f.Lower + rand.Float64()*(f.Upper-f.Lower)
+	}
+	return f.New(x)
+}
+
+// A Genome is a point to be evaluated by a benchmark function. Its fitness is
+// the negative of the function, computed once.
+type Genome struct {
+	evo.Cache
+	X  real.Vector
+	Fn *Function
+}
+
+// Fitness returns the negative of the function at the point.
+func (g *Genome) Fitness() float64 {
+	return g.Cache.Fitness(func() float64 {
+		return -g.Fn.Eval(g.X)
+	})
+}
+
+// Error returns the distance between the function at the point and the global
+// minimum.
+func (g *Genome) Error() float64 {
+	return -g.Fitness() - g.Fn.Min
+}
+
+// Clamp bounds the point to the domain of the function.
+func (g *Genome) Clamp() {
+	g.X.HighBound(g.Fn.Upper)
+	g.X.LowBound(g.Fn.Lower)
+}
+
+// The benchmark functions.
+var (
+	// Sphere is the sum of squares. It is unimodal and separable.
+	Sphere = &Function{
+		Name:  "sphere",
+		Eval:  sphere,
+		Lower: -5.12,
+		Upper: 5.12,
+	}
+
+	// Rosenbrock has a narrow curved valley. It is unimodal in low dimension
+	// and not separable.
+	Rosenbrock = &Function{
+		Name:  "rosenbrock",
+		Eval:  rosenbrock,
+		Lower: -5,
+		Upper: 10,
+		Opt:   1,
+	}
+
+	// Rastrigin is highly multimodal with a regular grid of local minima. It
+	// is separable.
+	Rastrigin = &Function{
+		Name:  "rastrigin",
+		Eval:  rastrigin,
+		Lower: -5.12,
+		Upper: 5.12,
+	}
+
+	// Schwefel is multimodal and deceptive: the global minimum is near the
+	// corner of the domain, far from the next best local minima.
+	Schwefel = &Function{
+		Name:  "schwefel",
+		Eval:  schwefel,
+		Lower: -500,
+		Upper: 500,
+		Opt:   420.9687,
+	}
+
+	// Griewank is multimodal, but the local minima become shallow in high
+	// dimension. It is not separable.
+	Griewank = &Function{
+		Name:  "griewank",
+		Eval:  griewank,
+		Lower: -600,
+		Upper: 600,
+	}
+
+	// Ackley is multimodal with a nearly flat outer region and a deep hole at
+	// the origin.
+	Ackley = &Function{
+		Name:  "ackley",
+		Eval:  ackley,
+		Lower: -32.768,
+		Upper: 32.768,
+	}
+)
+
+// Functions lists all benchmark functions.
+var Functions = []*Function{Sphere, Rosenbrock, Rastrigin, Schwefel, Griewank, Ackley}
+
+func sphere(x []float64) (sum float64) {
+	for _, xi := range x {
+		sum += xi * xi
+	}
+	return sum
+}
+
+func rosenbrock(x []float64) (sum float64) {
+	for i := 0; i+1 < len(x); i++ {
+		a := x[i+1] - x[i]*x[i]
+		b := 1 - x[i]
+		sum += 100*a*a + b*b
+	}
+	return sum
+}
+
+func rastrigin(x []float64) float64 {
+	sum := 10 * float64(len(x))
+	for _, xi := range x {
+		sum += xi*xi - 10*math.Cos(2*math.Pi*xi)
+	}
+	return sum
+}
+
+func schwefel(x []float64) float64 {
+	sum := 418.9829 * float64(len(x))
+	for _, xi := range x {
+		sum -= xi * math.Sin(math.Sqrt(math.Abs(xi)))
+	}
+	return sum
+}
+
+func griewank(x []float64) float64 {
+	sum, prod := 0.0, 1.0
+	for i, xi := range x {
+		sum += xi * xi / 4000
+		prod *= math.Cos(xi / math.Sqrt(float64(i+1)))
+	}
+	return 1 + sum - prod
+}
+
+func ackley(x []float64) float64 {
+	const a, b = 20, 0.2
+	var sum1, sum2 float64
+	n := float64(len(x))
+	for _, xi := range x {
+		sum1 += xi * xi
+		sum2 += math.Cos(2 * math.Pi * xi)
+	}
+	return -a*math.Exp(-b*math.Sqrt(sum1/n)) - math.Exp(sum2/n) + a + math.E
+}
@@ -0,0 +1,76 @@
+package bench_test
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/cbarrick/evo/bench"
+)
+
+// bench.go
+// -------------------------
+
+func TestOptimum(t *testing.T) {
+	for _, f := range bench.Functions {
+		opt := f.New(f.Optimum(10))
+		if err := opt.Error(); err < -1e-3 || 1e-3 < err {
+			t.Errorf("%s: error %v at optimum", f.Name, err)
+		}
+		g := f.Random(10)
+		if g.Fitness() > opt.Fitness() {
+			t.Errorf("%s: random point better than optimum", f.Name)
+		}
+		for _, x := range g.X {
+			if x < f.Lower || f.Upper < x {
+				t.Errorf("%s: random point out of bounds", f.Name)
+			}
+		}
+	}
+}
+
+// suite.go
+// -------------------------
+
+func TestSuite(t *testing.T) {
+	for _, s := range []*bench.Suite{bench.BBOB, bench.CEC17} {
+		for i, f := range s.Functions(10, 1) {
+			opt := f.New(f.Optimum(10))
+			if err := opt.Error(); err < -1e-3 || 1e-3 < err {
+				t.Errorf("%s: error %v at optimum", f.Name, err)
+			}
+			for _, x := range opt.X {
+				if x < f.Lower || f.Upper < x {
+					t.Errorf("%s: optimum out of bounds", f.Name)
+				}
+			}
+			g := f.Random(10)
+			if g.Fitness() > opt.Fitness() {
+				t.Errorf("%s: random point better than optimum", f.Name)
+			}
+			again := s.Functions(10, 1)[i]
+			if again.Eval(g.X) != f.Eval(g.X) {
+				t.Errorf("%s: not reproducible from the seed", f.Name)
+			}
+		}
+	}
+}
+
+func TestRotation(t *testing.T) {
+	rot := bench.Rotation(5, rand.New(rand.NewSource(1)))
+	for i := range rot {
+		for j := range rot {
+			var dot float64
+			for k := range rot[i] {
+				dot += rot[i][k] * rot[j][k]
+			}
+			want := 0.0
+			if i == j {
+				want = 1
+			}
+			if math.Abs(dot-want) > 1e-9 {
+				t.Errorf("rows %d and %d have product %v", i, j, dot)
+			}
+		}
+	}
+}
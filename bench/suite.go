@@ -0,0 +1,151 @@
+package bench
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+
+	"github.com/cbarrick/evo/real"
+)
+
+// A Suite generates instances of the benchmark functions in the manner of the
+// standard suites of continuous optimization, BBOB and CEC'17. Each instance
+// is a Function of a fixed dimension whose search domain is that of the suite,
+// and whose minimum is moved to a random point of the inner 80% of the domain,
+// with a value given by the bias of the suite. Within the domain, the instance
+// is the original function around its minimum, scaled to span its own domain
+// and, if the suite rotates, rotated by a random orthogonal matrix, which makes
+// separable functions non-separable.
+//
+// The shifts, rotations, and biases are generated from a seed rather than read
+// from the data files of the official suites, so instances are reproducible but
+// their values are not comparable with published tables.
+type Suite struct {
+	Name         string
+	Lower, Upper float64 // the search domain of every instance
+	Rotate       bool    // whether instances are rotated
+
+	// Bias returns the value of the minimum of the ith function of the suite.
+	Bias func(i int, rng *rand.Rand) float64
+}
+
+// The suites.
+var (
+	// BBOB searches [-5,5] in every dimension, with minima of random value
+	// in [-1000,1000], rounded to hundredths.
+	BBOB = &Suite{
+		Name:   "bbob",
+		Lower:  -5,
+		Upper:  5,
+		Rotate: true,
+		Bias: func(i int, rng *rand.Rand) float64 {
+			return math.Round(100*(2000*rng.Float64()-1000)) / 100
+		},
+	}
+
+	// CEC17 searches [-100,100] in every dimension, and the minimum of the
+	// ith function is 100i, counting from 1.
+	CEC17 = &Suite{
+		Name:   "cec17",
+		Lower:  -100,
+		Upper:  100,
+		Rotate: true,
+		Bias: func(i int, rng *rand.Rand) float64 {
+			return float64(100 * (i + 1))
+		},
+	}
+)
+
+// Functions returns an instance of each of the benchmark Functions in the given
+// dimension. The same seed gives the same instances.
+func (s *Suite) Functions(dim int, seed int64) []*Function {
+	rng := rand.New(rand.NewSource(seed))
+	instances := make([]*Function, len(Functions))
+	for i, f := range Functions {
+		instances[i] = s.instance(f, dim, s.Bias(i, rng), rng)
+	}
+	return instances
+}
+
+// Instance returns an instance of a function in the given dimension, with the
+// bias of the first function of the suite. The same seed gives the same
+// instance.
+func (s *Suite) Instance(f *Function, dim int, seed int64) *Function {
+	rng := rand.New(rand.NewSource(seed))
+	return s.instance(f, dim, s.Bias(0, rng), rng)
+}
+
+// instance returns an instance of f with the given bias.
+func (s *Suite) instance(f *Function, dim int, bias float64, rng *rand.Rand) *Function {
+	mid, width := (s.Lower+s.Upper)/2, s.Upper-s.Lower
+	shift := make(real.Vector, dim)
+	for i := range shift {
+		shift[i] = mid + 0.8*width*(rng.Float64()-0.5)
+	}
+	var rot [][]float64
+	if s.Rotate {
+		rot = Rotation(dim, rng)
+	}
+	scale := (f.Upper - f.Lower) / width
+
+	return &Function{
+		Name: fmt.Sprintf("%s/%s-%d", s.Name, f.Name, dim),
+		Eval: func(x []float64) float64 {
+			d := make([]float64, dim)
+			for i := range d {
+				d[i] = x[i] - shift[i]
+			}
+			z := make([]float64, dim)
+			for i := range z {
+				if rot == nil {
+					z[i] = d[i]
+				} else {
+					for j := range d {
+						z[i] += rot[i][j] * d[j]
+					}
+				}
+				z[i] = f.Opt + scale*z[i]
+			}
+			return f.Eval(z) - f.Min + bias
+		},
+		Lower: s.Lower,
+		Upper: s.Upper,
+		At:    shift,
+		Min:   bias,
+	}
+}
+
+// Rotation returns a random orthogonal matrix of the given dimension, drawn
+// uniformly by the Gram-Schmidt orthonormalization of a Gaussian matrix.
+func Rotation(dim int, rng *rand.Rand) [][]float64 {
+	rot := make([][]float64, dim)
+	for i := range rot {
+		for {
+			row := make([]float64, dim)
+			for j := range row {
+				row[j] = rng.NormFloat64()
+			}
+			for _, prev := range rot[:i] {
+				var dot float64
+				for j := range row {
+					dot += row[j] * prev[j]
+				}
+				for j := range row {
+					row[j] -= dot * prev[j]
+				}
+			}
+			var norm float64
+			for _, x := range row {
+				norm += x * x
+			}
+			if norm = math.Sqrt(norm); 1e-9 < norm {
+				for j := range row {
+					row[j] /= norm
+				}
+				rot[i] = row
+				break
+			}
+		}
+	}
+	return rot
+}
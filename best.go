@@ -0,0 +1,66 @@
+package evo
+
+import (
+	"sync"
+	"time"
+)
+
+// A Tracker tracks the best genome observed during an optimization. Populations
+// observe the genomes they evolve, so the best genome can be retrieved after
+// the population stops, even if it was replaced in the meantime. Trackers are
+// safe for concurrent use.
+type Tracker struct {
+	mu    sync.Mutex
+	start time.Time
+	meter *Meter // counts the evaluations, may be nil
+	best  Record
+}
+
+// A Record describes the best genome observed by a Tracker.
+type Record struct {
+	Genome      Genome        // the best genome, nil if none was observed
+	Fitness     float64       // the fitness of the genome
+	Evaluations int           // evaluations recorded by the meter of the tracker
+	Elapsed     time.Duration // time since the tracker was created
+}
+
+// NewTracker returns a tracker which measures time from now, and evaluations by
+// the meter of the population, if not nil.
+func NewTracker(m *Meter) *Tracker {
+	return &Tracker{
+		start: time.Now(),
+		meter: m,
+	}
+}
+
+// Observe records the genome if it is more fit than the best genome so far and
+// reports whether it is. Observe evaluates the fitness of the genome, outside
+// of the lock of the tracker.
+func (t *Tracker) Observe(g Genome) bool {
+	return t.Put(g, g.Fitness())
+}
+
+// Put is like Observe, but takes the fitness of the genome rather than
+// evaluating it, for populations which have already computed the fitness, e.g.
+// for their statistics.
+func (t *Tracker) Put(g Genome, fit float64) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.best.Genome != nil && fit <= t.best.Fitness {
+		return false
+	}
+	t.best = Record{
+		Genome:      g,
+		Fitness:     fit,
+		Evaluations: t.meter.Evaluations(),
+		Elapsed:     time.Since(t.start),
+	}
+	return true
+}
+
+// Best returns the record of the best genome observed.
+func (t *Tracker) Best() Record {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.best
+}
@@ -0,0 +1,31 @@
+package evo_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestTracker(t *testing.T) {
+	tracker := evo.NewTracker(nil)
+	if tracker.Best().Genome != nil {
+		t.Fail()
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			tracker.Observe(point(i % 37))
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	best := tracker.Best()
+	if best.Genome != point(36) || best.Fitness != 36 {
+		t.Error(best)
+	}
+	if tracker.Observe(point(36)) || !tracker.Observe(point(37)) {
+		t.Fail()
+	}
+}
@@ -0,0 +1,47 @@
+// Package binary provides operators for genomes represented as bit strings.
+//
+// Bit strings are represented as []bool. Mutation rates may be encoded in the
+// genome and self-adapted, like the step sizes of evolution strategies:
+//
+//	type genome struct {
+//		gene []bool
+//		rate float64 // the mutation rate, inherited and adapted
+//	}
+//
+//	child := &genome{gene: make([]bool, n), rate: (mom.rate + dad.rate) / 2}
+//	binary.UniformX(child.gene, mom.gene, dad.gene)
+//	child.rate = binary.AdaptFlip(child.gene, child.rate)
+package binary
+
+import "math/rand"
+
+// Random returns a random bit string of length n.
+func Random(n int) []bool {
+	gene := make([]bool, n)
+	for i := range gene {
+		gene[i] = rand.Intn(2) == 0
+	}
+	return gene
+}
+
+// Count returns the number of set bits.
+func Count(gene []bool) (n int) {
+	for _, b := range gene {
+		if b {
+			n++
+		}
+	}
+	return n
+}
+
+// Alleles returns the bit string as alleles 0 and 1, e.g. to implement
+// evo.Discrete.
+func Alleles(gene []bool) []int {
+	alleles := make([]int, len(gene))
+	for i, b := range gene {
+		if b {
+			alleles[i] = 1
+		}
+	}
+	return alleles
+}
@@ -0,0 +1,93 @@
+package binary_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/binary"
+)
+
+// binary.go
+// -------------------------
+
+func TestRandom(t *testing.T) {
+	gene := binary.Random(1000)
+	if n := binary.Count(gene); len(gene) != 1000 || n < 400 || 600 < n {
+		t.Fail()
+	}
+}
+
+func TestAlleles(t *testing.T) {
+	alleles := binary.Alleles([]bool{true, false, true})
+	if len(alleles) != 3 || alleles[0] != 1 || alleles[1] != 0 || alleles[2] != 1 {
+		t.Error(alleles)
+	}
+}
+
+// cross.go
+// -------------------------
+
+func TestUniformX(t *testing.T) {
+	mom := make([]bool, 8)
+	dad := make([]bool, 8)
+	for i := range dad {
+		dad[i] = true
+	}
+	child := make([]bool, 8)
+	binary.UniformX(child, mom, dad)
+	for i := range child {
+		if child[i] != mom[i] && child[i] != dad[i] {
+			t.Fail()
+		}
+	}
+}
+
+func TestPointX(t *testing.T) {
+	mom := make([]bool, 8)
+	dad := make([]bool, 8)
+	for i := range dad {
+		dad[i] = true
+	}
+	child := make([]bool, 8)
+	binary.PointX(7, child, mom, dad)
+	for i := 1; i < len(child); i++ {
+		if child[i] == child[i-1] {
+			t.Fail()
+		}
+	}
+}
+
+// mutation.go
+// -------------------------
+
+func TestFlip(t *testing.T) {
+	gene := make([]bool, 100)
+	binary.Flip(gene, 1)
+	binary.Flip(gene, 0)
+	if binary.Count(gene) != 100 {
+		t.Fail()
+	}
+}
+
+func TestAdaptFlip(t *testing.T) {
+	// a (1,10)-EA with a self-adaptive rate on OneMax
+	const n = 64
+	gene, rate := make([]bool, n), 0.25
+	for i := 0; i < 2000 && binary.Count(gene) < n; i++ {
+		var best []bool
+		var bestRate float64
+		for j := 0; j < 10; j++ {
+			child := append([]bool(nil), gene...)
+			childRate := binary.AdaptFlip(child, rate)
+			if childRate < 1.0/(n*n) || 0.5 < childRate {
+				t.Fatalf("rate %v out of bounds", childRate)
+			}
+			if best == nil || binary.Count(best) < binary.Count(child) {
+				best, bestRate = child, childRate
+			}
+		}
+		gene, rate = best, bestRate
+	}
+	if binary.Count(gene) < n {
+		t.Errorf("OneMax reached only %d of %d, rate %v", binary.Count(gene), n, rate)
+	}
+}
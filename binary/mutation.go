@@ -0,0 +1,37 @@
+package binary
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cbarrick/evo/real"
+)
+
+// Flip flips each bit of the gene with the given probability.
+func Flip(gene []bool, rate float64) {
+	for i := range gene {
+		if rand.Float64() < rate {
+			gene[i] = !gene[i]
+		}
+	}
+}
+
+// AdaptRate self-adapts the mutation rate of a gene of length n with a
+// logistic-normal scaling and a learning rate of 1/sqrt(n), see real.AdaptRate.
+// The new rate is kept within [1/n², 1/2], so that mutation neither stops
+// entirely nor randomizes the gene.
+func AdaptRate(rate float64, n int) float64 {
+	lo, hi := 1/float64(n*n), 0.5
+	rate = math.Min(math.Max(rate, lo), hi)
+	rate = real.AdaptRate(rate, 1/math.Sqrt(float64(n)))
+	return math.Min(math.Max(rate, lo), hi)
+}
+
+// AdaptFlip self-adapts the mutation rate, see AdaptRate, then flips the bits
+// of the gene with the new rate. It returns the new rate, which should be
+// stored with the gene.
+func AdaptFlip(gene []bool, rate float64) float64 {
+	rate = AdaptRate(rate, len(gene))
+	Flip(gene, rate)
+	return rate
+}
@@ -0,0 +1,145 @@
+// Package bitbench provides classical benchmark problems for bit strings.
+//
+// Each problem is a function of a bit string to be maximized, with a known
+// optimum: the string of all ones. The problems range from the easiest, OneMax,
+// to deceptive traps which lead hill climbers and weak selection away from the
+// optimum, which makes them suited to validating binary operators and to
+// comparing selection schemes under controlled deception:
+//
+//	trap := bitbench.Trap(5, 20)
+//	seed := make([]evo.Genome, 100)
+//	for i := range seed {
+//		seed[i] = trap.Random()
+//	}
+package bitbench
+
+import (
+	"fmt"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/binary"
+)
+
+// A Problem is a benchmark function of bit strings of a fixed length, to be
+// maximized. The optimum is the string of all ones, with a fitness of Max.
+type Problem struct {
+	Name string
+	Len  int // the length of the bit strings
+	Eval func(x []bool) float64
+	Max  float64 // the fitness of the optimum
+}
+
+// OneMax returns the problem of counting the set bits of a string of length n.
+// It is unimodal and separable, the baseline against which the others are
+// compared.
+func OneMax(n int) *Problem {
+	return &Problem{
+		Name: fmt.Sprintf("onemax-%d", n),
+		Len:  n,
+		Eval: func(x []bool) float64 { return float64(binary.Count(x)) },
+		Max:  float64(n),
+	}
+}
+
+// Trap returns the concatenation of the given number of deceptive traps of k
+// bits each. A block of u set bits scores k if u is k, and k-1-u otherwise, so
+// every block but the optimal one leads towards all zeros. Traps of k = 4 or 5
+// are deceptive: search is only guided to the optimum by recombining whole
+// blocks. Trap panics unless k and blocks are positive.
+func Trap(k, blocks int) *Problem {
+	check(k, blocks)
+	return &Problem{
+		Name: fmt.Sprintf("trap-%dx%d", k, blocks),
+		Len:  k * blocks,
+		Eval: func(x []bool) (sum float64) {
+			for b := 0; b < len(x); b += k {
+				u := binary.Count(x[b : b+k])
+				if u == k {
+					sum += float64(k)
+				} else {
+					sum += float64(k - 1 - u)
+				}
+			}
+			return sum
+		},
+		Max: float64(k * blocks),
+	}
+}
+
+// RoyalRoad returns the royal road function R1 of Mitchell, Forrest, and
+// Holland, over the given number of blocks of k bits each. A block scores k
+// when all of its bits are set, and 0 otherwise. The fitness landscape is made
+// of plateaus, where selection gives no guidance until a whole block is found.
+// RoyalRoad panics unless k and blocks are positive.
+func RoyalRoad(k, blocks int) *Problem {
+	check(k, blocks)
+	return &Problem{
+		Name: fmt.Sprintf("royalroad-%dx%d", k, blocks),
+		Len:  k * blocks,
+		Eval: func(x []bool) (sum float64) {
+			for b := 0; b < len(x); b += k {
+				if binary.Count(x[b:b+k]) == k {
+					sum += float64(k)
+				}
+			}
+			return sum
+		},
+		Max: float64(k * blocks),
+	}
+}
+
+// check panics unless the block parameters are positive.
+func check(k, blocks int) {
+	if k < 1 || blocks < 1 {
+		panic(fmt.Sprintf("bitbench: invalid blocks %dx%d", k, blocks))
+	}
+}
+
+// Optimum returns the optimal bit string, all ones.
+func (p *Problem) Optimum() []bool {
+	x := make([]bool, p.Len)
+	for i := range x {
+		x[i] = true
+	}
+	return x
+}
+
+// New returns a genome for the given bit string, which must be of length Len.
+func (p *Problem) New(x []bool) *Genome {
+	return &Genome{X: x, P: p}
+}
+
+// Random returns a genome for a random bit string.
+func (p *Problem) Random() *Genome {
+	return p.New(binary.Random(p.Len))
+}
+
+// A Genome is a bit string evaluated by a problem. Its fitness is computed
+// once.
+type Genome struct {
+	evo.Cache
+	X []bool
+	P *Problem
+}
+
+// Fitness returns the value of the problem for the bit string.
+func (g *Genome) Fitness() float64 {
+	return g.Cache.Fitness(func() float64 {
+		return g.P.Eval(g.X)
+	})
+}
+
+// Error returns the distance between the fitness and that of the optimum.
+func (g *Genome) Error() float64 {
+	return g.P.Max - g.Fitness()
+}
+
+// Solved returns true if the genome is optimal.
+func (g *Genome) Solved() bool {
+	return g.Fitness() == g.P.Max
+}
+
+// Alleles returns the bits as 0 and 1, implementing evo.Discrete.
+func (g *Genome) Alleles() []int {
+	return binary.Alleles(g.X)
+}
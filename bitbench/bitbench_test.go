@@ -0,0 +1,54 @@
+package bitbench_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/bitbench"
+)
+
+func TestOptimum(t *testing.T) {
+	problems := []*bitbench.Problem{
+		bitbench.OneMax(20),
+		bitbench.Trap(4, 5),
+		bitbench.RoyalRoad(4, 5),
+	}
+	for _, p := range problems {
+		opt := p.New(p.Optimum())
+		if !opt.Solved() || opt.Error() != 0 {
+			t.Errorf("%s: optimum has error %v", p.Name, opt.Error())
+		}
+		for i := 0; i < 100; i++ {
+			g := p.Random()
+			if len(g.X) != p.Len {
+				t.Fatalf("%s: random genome of length %d", p.Name, len(g.X))
+			}
+			if g.Fitness() > opt.Fitness() || g.Error() < 0 {
+				t.Errorf("%s: random genome better than optimum", p.Name)
+			}
+		}
+	}
+}
+
+func TestTrap(t *testing.T) {
+	trap := bitbench.Trap(4, 2)
+	for _, c := range []struct {
+		x   []bool
+		fit float64
+	}{
+		{[]bool{false, false, false, false, false, false, false, false}, 6},
+		{[]bool{true, false, false, false, false, false, false, false}, 5},
+		{[]bool{true, true, true, false, true, true, true, true}, 4},
+	} {
+		if fit := trap.Eval(c.x); fit != c.fit {
+			t.Errorf("trap of %v is %v, want %v", c.x, fit, c.fit)
+		}
+	}
+}
+
+func TestRoyalRoad(t *testing.T) {
+	road := bitbench.RoyalRoad(2, 3)
+	x := []bool{true, true, true, false, false, true}
+	if fit := road.Eval(x); fit != 2 {
+		t.Errorf("royal road of %v is %v, want 2", x, fit)
+	}
+}
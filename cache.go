@@ -0,0 +1,67 @@
+package evo
+
+import (
+	"sync"
+)
+
+// A Cache memoizes the fitness of a genome. The fitness is computed at most
+// once, on first use, until the cache is invalidated. Caches are safe for
+// concurrent use; concurrent callers wait for a single computation. The zero
+// value is an empty cache.
+//
+// Caches are meant to be embedded in genome types:
+//
+//	type genome struct {
+//		evo.Cache
+//		gene []int
+//	}
+//
+//	func (g *genome) Fitness() float64 {
+//		return g.Cache.Fitness(g.evaluate)
+//	}
+type Cache struct {
+	mu    sync.Mutex
+	valid bool
+	fit   float64
+}
+
+// Fitness returns the cached fitness, computing it with fn if the cache is
+// empty.
+func (c *Cache) Fitness(fn func() float64) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.valid {
+		c.fit = fn()
+		c.valid = true
+	}
+	return c.fit
+}
+
+// Invalidate empties the cache, e.g. after the genome is modified. The next
+// call to Fitness recomputes the fitness.
+func (c *Cache) Invalidate() {
+	c.mu.Lock()
+	c.valid = false
+	c.mu.Unlock()
+}
+
+// A CachedGenome wraps a genome to memoize its fitness.
+type CachedGenome struct {
+	Genome
+	cache Cache
+}
+
+// Cached wraps a genome to memoize its fitness.
+func Cached(g Genome) *CachedGenome {
+	return &CachedGenome{Genome: g}
+}
+
+// Fitness returns the cached fitness of the underlying genome.
+func (c *CachedGenome) Fitness() float64 {
+	return c.cache.Fitness(c.Genome.Fitness)
+}
+
+// Invalidate empties the cache.
+func (c *CachedGenome) Invalidate() {
+	c.cache.Invalidate()
+}
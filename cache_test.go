@@ -0,0 +1,26 @@
+package evo_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+type counter int
+
+func (c *counter) Fitness() float64 {
+	*c++
+	return float64(*c)
+}
+
+func TestCached(t *testing.T) {
+	var c counter
+	g := evo.Cached(&c)
+	if g.Fitness() != 1 || g.Fitness() != 1 {
+		t.Fail()
+	}
+	g.Invalidate()
+	if g.Fitness() != 2 || c != 2 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,157 @@
+// Package cgp provides Cartesian genetic programming.
+//
+// In CGP, a program is a fixed grid of nodes. Each node has a function gene and
+// a number of connection genes which refer to program inputs or to the outputs
+// of nodes in earlier columns. Additional output genes choose which nodes
+// provide the outputs of the program. Nodes which are not connected to an
+// output are inactive and do not affect the program, giving CGP a great deal of
+// neutrality.
+//
+// Genomes are plain []int slices, laid out node by node. Each node occupies
+// Arity+1 genes: the function gene followed by the connection genes. The output
+// genes follow the nodes. The values of inputs are numbered [0,Inputs), and the
+// value of the node at grid position i is numbered Inputs+i, where nodes are
+// numbered column by column.
+package cgp
+
+import (
+	"math/rand"
+)
+
+// A Func is a node function. It receives exactly Arity arguments, though it
+// is free to ignore some of them.
+type Func func(args []float64) float64
+
+// Params describes the shape of a CGP genome.
+type Params struct {
+	Inputs     int    // number of program inputs
+	Outputs    int    // number of program outputs
+	Rows, Cols int    // dimensions of the node grid
+	LevelsBack int    // how many columns back a node may connect; 0 means all
+	Arity      int    // number of connection genes per node
+	Funcs      []Func // the function set
+}
+
+// nodes returns the number of nodes in the grid.
+func (p *Params) nodes() int {
+	return p.Rows * p.Cols
+}
+
+// Len returns the length of genomes with these parameters.
+func (p *Params) Len() int {
+	return p.nodes()*(p.Arity+1) + p.Outputs
+}
+
+// bounds returns the range [lo,hi) of valid values for the ith gene. Program
+// inputs are always valid connections, so when LevelsBack excludes the nodes
+// just after the inputs, the inputs are valid in addition to [lo,hi).
+func (p *Params) bounds(i int) (lo, hi int) {
+	width := p.Arity + 1
+	if i >= p.nodes()*width {
+		return 0, p.Inputs + p.nodes()
+	}
+	if i%width == 0 {
+		return 0, len(p.Funcs)
+	}
+	col := i / width / p.Rows
+	hi = p.Inputs + col*p.Rows
+	if p.LevelsBack <= 0 || col <= p.LevelsBack {
+		return 0, hi
+	}
+	return p.Inputs + (col-p.LevelsBack)*p.Rows, hi
+}
+
+// randGene returns a random valid value for the ith gene.
+func (p *Params) randGene(i int) int {
+	lo, hi := p.bounds(i)
+	if lo == 0 {
+		return rand.Intn(hi)
+	}
+	// the valid values are [0,Inputs) and [lo,hi)
+	n := rand.Intn(p.Inputs + hi - lo)
+	if n < p.Inputs {
+		return n
+	}
+	return n - p.Inputs + lo
+}
+
+// New returns a random genome.
+func (p *Params) New() (genes []int) {
+	genes = make([]int, p.Len())
+	for i := range genes {
+		genes[i] = p.randGene(i)
+	}
+	return genes
+}
+
+// Mutate performs n point mutations, each replacing a random gene with a
+// random valid value.
+func (p *Params) Mutate(genes []int, n int) {
+	for ; 0 < n; n-- {
+		i := rand.Intn(len(genes))
+		genes[i] = p.randGene(i)
+	}
+}
+
+// ActiveMutate performs point mutations until an active gene is changed. This
+// "single active mutation" avoids wasting evaluations on genomes which only
+// differ in inactive nodes.
+func (p *Params) ActiveMutate(genes []int) {
+	active := p.Active(genes)
+	width := p.Arity + 1
+	for {
+		i := rand.Intn(len(genes))
+		old := genes[i]
+		genes[i] = p.randGene(i)
+		isActive := i >= p.nodes()*width || active[i/width]
+		if isActive && genes[i] != old {
+			return
+		}
+	}
+}
+
+// Active returns which nodes of the grid contribute to the outputs.
+func (p *Params) Active(genes []int) (active []bool) {
+	width := p.Arity + 1
+	active = make([]bool, p.nodes())
+	var mark func(v int)
+	mark = func(v int) {
+		n := v - p.Inputs
+		if n < 0 || active[n] {
+			return
+		}
+		active[n] = true
+		for j := 1; j < width; j++ {
+			mark(genes[n*width+j])
+		}
+	}
+	for _, v := range genes[p.nodes()*width:] {
+		mark(v)
+	}
+	return active
+}
+
+// Eval executes the program on the given inputs and returns the outputs.
+// Only active nodes are evaluated.
+func (p *Params) Eval(genes []int, inputs []float64) (outputs []float64) {
+	width := p.Arity + 1
+	active := p.Active(genes)
+	values := make([]float64, p.Inputs+p.nodes())
+	copy(values, inputs)
+	args := make([]float64, p.Arity)
+	for n := range active {
+		if !active[n] {
+			continue
+		}
+		node := genes[n*width : (n+1)*width]
+		for j := range args {
+			args[j] = values[node[j+1]]
+		}
+		values[p.Inputs+n] = p.Funcs[node[0]](args)
+	}
+	outputs = make([]float64, p.Outputs)
+	for i, v := range genes[p.nodes()*width:] {
+		outputs[i] = values[v]
+	}
+	return outputs
+}
@@ -0,0 +1,79 @@
+package cgp_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/cgp"
+)
+
+func params() *cgp.Params {
+	return &cgp.Params{
+		Inputs:     2,
+		Outputs:    1,
+		Rows:       2,
+		Cols:       3,
+		LevelsBack: 1,
+		Arity:      2,
+		Funcs: []cgp.Func{
+			func(args []float64) float64 { return args[0] + args[1] },
+			func(args []float64) float64 { return args[0] * args[1] },
+		},
+	}
+}
+
+// valid fails the test if any connection gene refers forward in the grid.
+func valid(t *testing.T, p *cgp.Params, genes []int) {
+	if len(genes) != p.Len() {
+		t.Fail()
+		return
+	}
+	for n := 0; n < p.Rows*p.Cols; n++ {
+		node := genes[n*3 : n*3+3]
+		if node[0] < 0 || len(p.Funcs) <= node[0] {
+			t.Fail()
+		}
+		col := n / p.Rows
+		for _, c := range node[1:] {
+			ccol := (c - p.Inputs) / p.Rows
+			if c >= p.Inputs && (col <= ccol || ccol < col-p.LevelsBack) {
+				t.Fail()
+			}
+		}
+	}
+}
+
+func TestNew(t *testing.T) {
+	p := params()
+	for i := 0; i < 100; i++ {
+		genes := p.New()
+		valid(t, p, genes)
+		p.Mutate(genes, 5)
+		valid(t, p, genes)
+		p.ActiveMutate(genes)
+		valid(t, p, genes)
+	}
+}
+
+func TestEval(t *testing.T) {
+	p := params()
+	genes := []int{
+		0, 0, 1, // node 2: x + y
+		1, 0, 1, // node 3: x * y
+		1, 2, 3, // node 4: (x + y) * (x * y)
+		0, 0, 0, // node 5: unused
+		0, 4, 4, // node 6: 2 * node 4
+		0, 5, 5, // node 7: unused
+		6, // output
+	}
+	out := p.Eval(genes, []float64{2, 3})
+	if len(out) != 1 || out[0] != 60 {
+		t.Fail()
+	}
+	active := p.Active(genes)
+	expect := []bool{true, true, true, false, true, false}
+	for i := range expect {
+		if active[i] != expect[i] {
+			t.Fail()
+		}
+	}
+}
@@ -0,0 +1,110 @@
+package evo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// A Clock tells the time and schedules wake-ups. Populations and migration
+// policies wait on a Clock for their delays and polling, which defaults to the
+// system clock. Injecting a FakeClock makes schedules deterministic in tests,
+// and lets simulations run faster than real time.
+type Clock interface {
+	// Now returns the current time.
+	Now() time.Time
+
+	// After returns a channel which receives the current time once the
+	// duration has elapsed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the Clock of the time package.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time                         { return time.Now() }
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// A FakeClock is a Clock whose time only moves when it is advanced. FakeClocks
+// are safe for concurrent use.
+type FakeClock struct {
+	mu      sync.Mutex
+	now     time.Time
+	waiters []waiter
+	added   chan struct{} // closed and replaced whenever a waiter is added
+}
+
+// A waiter is a pending call to After.
+type waiter struct {
+	at time.Time
+	ch chan time.Time
+}
+
+// NewFakeClock returns a fake clock set to the given time.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now, added: make(chan struct{})}
+}
+
+// Now returns the time of the clock.
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel which receives the time of the clock once it has been
+// advanced by at least the duration. A duration of 0 or less fires at once.
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- c.now
+		return ch
+	}
+	c.waiters = append(c.waiters, waiter{c.now.Add(d), ch})
+	close(c.added)
+	c.added = make(chan struct{})
+	return ch
+}
+
+// Advance moves the clock forward by the duration, firing the wake-ups which
+// are due in the order of their deadlines.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	sort.SliceStable(c.waiters, func(i, j int) bool {
+		return c.waiters[i].at.Before(c.waiters[j].at)
+	})
+	n := 0
+	for n < len(c.waiters) && !c.now.Before(c.waiters[n].at) {
+		c.waiters[n].ch <- c.now
+		n++
+	}
+	c.waiters = append(c.waiters[:0], c.waiters[n:]...)
+}
+
+// Waiters returns the number of pending wake-ups.
+func (c *FakeClock) Waiters() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.waiters)
+}
+
+// BlockUntil blocks until there are at least n pending wake-ups, e.g. until
+// every island of a model is waiting for its next migration, so that advancing
+// the clock afterwards fires them deterministically.
+func (c *FakeClock) BlockUntil(n int) {
+	for {
+		c.mu.Lock()
+		pending, added := len(c.waiters), c.added
+		c.mu.Unlock()
+		if n <= pending {
+			return
+		}
+		<-added
+	}
+}
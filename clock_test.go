@@ -0,0 +1,48 @@
+package evo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := evo.NewFakeClock(start)
+	late := c.After(2 * time.Second)
+	early := c.After(1 * time.Second)
+	select {
+	case <-c.After(0):
+	default:
+		t.Error("a zero duration did not fire at once")
+	}
+	if c.Waiters() != 2 {
+		t.Errorf("got %d waiters, want 2", c.Waiters())
+	}
+
+	c.Advance(time.Second)
+	select {
+	case now := <-early:
+		if !now.Equal(start.Add(time.Second)) {
+			t.Errorf("fired at %v, want %v", now, start.Add(time.Second))
+		}
+	default:
+		t.Error("a due wake-up did not fire")
+	}
+	select {
+	case <-late:
+		t.Error("a wake-up fired early")
+	default:
+	}
+
+	c.Advance(time.Second)
+	select {
+	case <-late:
+	default:
+		t.Error("a due wake-up did not fire")
+	}
+	if c.Waiters() != 0 {
+		t.Errorf("got %d waiters, want 0", c.Waiters())
+	}
+}
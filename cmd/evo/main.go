@@ -0,0 +1,102 @@
+// Command evo runs optimization experiments described by JSON configuration
+// files. See package experiment for the format of the configuration.
+//
+// Usage:
+//
+//	evo [-out dir] [-compare] [-plugin file.so]... config.json...
+//	evo [-plugin file.so]... -list
+//
+// Each configuration is run in turn, and a summary of each repetition is
+// printed, followed by an aggregate over the repetitions. Reports are written
+// to the output directory of the configuration, which can be overridden with
+// -out. With -compare, the best fitness of the first configuration is compared
+// against each of the others with the Mann-Whitney U test.
+//
+// User problems are loaded from Go plugins with -plugin, which may be
+// repeated; see experiment.LoadPlugin. The kinds of problem available, built in
+// and loaded, are listed with -list.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/cbarrick/evo/experiment"
+)
+
+// plugins is a flag which may be repeated.
+type plugins []string
+
+func (p *plugins) String() string { return strings.Join(*p, ",") }
+
+func (p *plugins) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
+
+func main() {
+	var load plugins
+	out := flag.String("out", "", "write reports to this directory")
+	compare := flag.Bool("compare", false, "compare the first experiment against the others")
+	list := flag.Bool("list", false, "list the kinds of problem and exit")
+	flag.Var(&load, "plugin", "load problems from a Go plugin (repeatable)")
+	flag.Usage = func() {
+		fmt.Fprintln(os.Stderr, "usage: evo [-out dir] [-compare] [-plugin file.so]... config.json...")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	for _, path := range load {
+		if err := experiment.LoadPlugin(path); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	if *list {
+		for _, kind := range experiment.Kinds() {
+			fmt.Println(kind)
+		}
+		return
+	}
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var (
+		failed  bool
+		reports []*experiment.Report
+	)
+	for _, path := range flag.Args() {
+		cfg, err := experiment.Load(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		if *out != "" {
+			cfg.Output = *out
+		}
+		report, err := experiment.Run(cfg)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			failed = true
+			continue
+		}
+		for _, res := range report.Results {
+			fmt.Printf("%s\t%d\tbest=%g\tevals=%d\ttime=%.2fs\tsuccess=%v\n",
+				cfg.Name, res.Repetition, res.Best, res.Evaluations, res.Seconds, res.Success)
+		}
+		fmt.Printf("%s\t%v\n", cfg.Name, report.Summary())
+		reports = append(reports, report)
+	}
+	if *compare && !failed {
+		for _, r := range reports[1:] {
+			fmt.Printf("%s vs %s\t%v\n", reports[0].Config.Name, r.Config.Name, experiment.Compare(reports[0], r))
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
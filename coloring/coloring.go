@@ -0,0 +1,260 @@
+// Package coloring provides helpers for the graph coloring problem.
+//
+// The k-coloring problem is to assign one of k colors to each vertex of a graph
+// so that no edge joins two vertices of the same color. Colorings are
+// represented as integer vectors, where the i-th value is the color of the
+// i-th vertex, and are evolved to minimize the number of conflicting edges.
+//
+// Besides the generic operators of the integer package, this package provides
+// greedy partition crossover (GPX), which inherits whole color classes rather
+// than individual colors, and a local search by Kempe chain interchanges.
+package coloring
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/integer"
+)
+
+// A Graph is an undirected graph given by adjacency lists.
+type Graph struct {
+	Name string
+	Adj  [][]int
+}
+
+// NewGraph returns a graph of n vertices with the given edges.
+func NewGraph(n int, edges [][2]int) *Graph {
+	g := &Graph{Adj: make([][]int, n)}
+	for _, e := range edges {
+		g.Adj[e[0]] = append(g.Adj[e[0]], e[1])
+		g.Adj[e[1]] = append(g.Adj[e[1]], e[0])
+	}
+	return g
+}
+
+// Load reads a graph from a file.
+func Load(path string) (*Graph, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	g, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	g.Name = path
+	return g, nil
+}
+
+// Parse reads a graph in the DIMACS format. The problem line "p edge n m" gives
+// the number of vertices, and each edge line "e u v" gives an edge between
+// vertices numbered from 1. Duplicate edges are ignored.
+func Parse(r io.Reader) (*Graph, error) {
+	var (
+		n       = -1
+		edges   [][2]int
+		seen    = make(map[[2]int]bool)
+		scanner = bufio.NewScanner(r)
+	)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "p":
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("coloring: bad problem line")
+			}
+			if _, err := fmt.Sscan(fields[2], &n); err != nil {
+				return nil, fmt.Errorf("coloring: bad problem line: %v", err)
+			}
+		case "e":
+			var u, v int
+			if len(fields) < 3 {
+				return nil, fmt.Errorf("coloring: bad edge line")
+			}
+			if _, err := fmt.Sscan(fields[1], &u); err != nil {
+				return nil, fmt.Errorf("coloring: bad edge: %v", err)
+			}
+			if _, err := fmt.Sscan(fields[2], &v); err != nil {
+				return nil, fmt.Errorf("coloring: bad edge: %v", err)
+			}
+			if n < 0 || u < 1 || n < u || v < 1 || n < v {
+				return nil, fmt.Errorf("coloring: edge %d-%d out of range", u, v)
+			}
+			e := [2]int{min(u, v) - 1, max(u, v) - 1}
+			if !seen[e] && u != v {
+				seen[e] = true
+				edges = append(edges, e)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("coloring: missing problem line")
+	}
+	return NewGraph(n, edges), nil
+}
+
+// Len returns the number of vertices.
+func (g *Graph) Len() int {
+	return len(g.Adj)
+}
+
+// Conflicts returns the number of edges joining vertices of the same color.
+func (g *Graph) Conflicts(colors []int) (n int) {
+	for u := range g.Adj {
+		for _, v := range g.Adj[u] {
+			if u < v && colors[u] == colors[v] {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// conflicts returns the number of neighbors of u with the color c.
+func (g *Graph) conflicts(colors []int, u, c int) (n int) {
+	for _, v := range g.Adj[u] {
+		if colors[v] == c {
+			n++
+		}
+	}
+	return n
+}
+
+// GPX performs greedy partition crossover of two k-colorings. The child
+// inherits color classes alternately from each parent, each time taking the
+// largest class of the parent, counting only the vertices not yet colored.
+// Vertices left uncolored after k classes receive random colors.
+func GPX(k int, child, mom, dad []int) {
+	parents := [2][]int{mom, dad}
+	if rand.Intn(2) == 0 {
+		parents[0], parents[1] = dad, mom
+	}
+	for i := range child {
+		child[i] = -1
+	}
+	size := make([]int, k)
+	for c := 0; c < k; c++ {
+		p := parents[c%2]
+
+		// find the largest class among the uncolored vertices
+		for i := range size {
+			size[i] = 0
+		}
+		for i := range p {
+			if child[i] < 0 {
+				size[p[i]]++
+			}
+		}
+		best := 0
+		for i := range size {
+			if size[best] < size[i] {
+				best = i
+			}
+		}
+
+		// give it the next color
+		for i := range p {
+			if child[i] < 0 && p[i] == best {
+				child[i] = c
+			}
+		}
+	}
+	for i := range child {
+		if child[i] < 0 {
+			child[i] = rand.Intn(k)
+		}
+	}
+}
+
+// Kempe performs a Kempe chain interchange. The chain is the connected
+// component containing u of the subgraph induced by the vertices colored
+// either colors[u] or c. The two colors are swapped throughout the chain.
+func (g *Graph) Kempe(colors []int, u, c int) {
+	a := colors[u]
+	if a == c {
+		return
+	}
+	chain := map[int]bool{u: true}
+	stack := []int{u}
+	for len(stack) > 0 {
+		v := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, w := range g.Adj[v] {
+			if !chain[w] && (colors[w] == a || colors[w] == c) {
+				chain[w] = true
+				stack = append(stack, w)
+			}
+		}
+	}
+	for v := range chain {
+		if colors[v] == a {
+			colors[v] = c
+		} else {
+			colors[v] = a
+		}
+	}
+}
+
+// KempeSearch performs a local search by Kempe chain interchanges. At each of
+// the given number of steps, a random conflicting vertex and a random color are
+// chosen, and the interchange is kept only if it does not increase the number
+// of conflicts. The coloring is modified in place.
+func (g *Graph) KempeSearch(k int, colors []int, steps int) {
+	conflicts := g.Conflicts(colors)
+	backup := make([]int, len(colors))
+	for s := 0; s < steps && conflicts > 0; s++ {
+		var bad []int
+		for u := range colors {
+			if g.conflicts(colors, u, colors[u]) > 0 {
+				bad = append(bad, u)
+			}
+		}
+		u := bad[rand.Intn(len(bad))]
+		copy(backup, colors)
+		g.Kempe(colors, u, rand.Intn(k))
+		if n := g.Conflicts(colors); n <= conflicts {
+			conflicts = n
+		} else {
+			copy(colors, backup)
+		}
+	}
+}
+
+// A Coloring is a genome for the k-coloring of a graph. The fitness is the
+// negative number of conflicts, computed once.
+type Coloring struct {
+	evo.Cache
+	Gene  []int
+	Graph *Graph
+	K     int
+}
+
+// New returns a genome for the given k-coloring.
+func (g *Graph) New(k int, gene []int) *Coloring {
+	return &Coloring{Gene: gene, Graph: g, K: k}
+}
+
+// Random returns a genome for a random k-coloring.
+func (g *Graph) Random(k int) *Coloring {
+	return g.New(k, integer.Random(g.Len(), k))
+}
+
+// Fitness returns the negative number of conflicts.
+func (c *Coloring) Fitness() float64 {
+	return c.Cache.Fitness(func() float64 {
+		return -float64(c.Graph.Conflicts(c.Gene))
+	})
+}
@@ -0,0 +1,73 @@
+package coloring_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo/coloring"
+)
+
+// a 5-cycle, which needs 3 colors
+const cycle = `c the 5-cycle
+p edge 5 5
+e 1 2
+e 2 3
+e 3 4
+e 4 5
+e 5 1
+e 1 5
+`
+
+func parse(t *testing.T) *coloring.Graph {
+	g, err := coloring.Parse(strings.NewReader(cycle))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return g
+}
+
+func TestParse(t *testing.T) {
+	g := parse(t)
+	if g.Len() != 5 || len(g.Adj[0]) != 2 {
+		t.Fail()
+	}
+	if g.Conflicts([]int{0, 1, 0, 1, 2}) != 0 || g.Conflicts([]int{0, 0, 0, 0, 0}) != 5 {
+		t.Fail()
+	}
+	if _, err := coloring.Parse(strings.NewReader("p edge 2 1\ne 1 3\n")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestGPX(t *testing.T) {
+	mom := []int{0, 1, 0, 1, 2}
+	dad := []int{2, 0, 2, 0, 1}
+	child := make([]int, 5)
+	coloring.GPX(3, child, mom, dad)
+	g := parse(t)
+	if g.Conflicts(child) != 0 {
+		t.Fail()
+	}
+}
+
+func TestKempe(t *testing.T) {
+	g := parse(t)
+	colors := []int{0, 1, 0, 1, 2}
+	g.Kempe(colors, 0, 1)
+	if colors[0] != 1 || colors[1] != 0 || colors[2] != 1 || colors[3] != 0 || colors[4] != 2 {
+		t.Fail()
+	}
+	if g.Conflicts(colors) != 0 {
+		t.Fail()
+	}
+}
+
+func TestKempeSearch(t *testing.T) {
+	g := parse(t)
+	c := g.Random(3)
+	before := c.Fitness()
+	g.KempeSearch(3, c.Gene, 100)
+	if -float64(g.Conflicts(c.Gene)) < before {
+		t.Fail()
+	}
+}
@@ -0,0 +1,178 @@
+// Package coop provides cooperative coevolution.
+//
+// Cooperative coevolution scales evolutionary algorithms to high dimensional
+// problems by decomposition. A large genome, e.g. a 1000-dimensional real
+// vector, is split into subcomponents, and each subcomponent is evolved in its
+// own subpopulation. A part can not be evaluated alone, so it is evaluated
+// jointly with collaborators from the other subpopulations.
+//
+// This package maintains a context: the best known complete solution. A part is
+// evaluated by substituting it into the context. When that improves the
+// context, the part becomes the collaborator for its subcomponent. The
+// subpopulations are ordinary populations evolved concurrently, and the parts
+// they evolve are wrapped with the Evolve method. For example, a 1000-d problem
+// split into 10 subpopulations of 100-d parts:
+//
+//	ctx := coop.New(objective, initial)
+//	for i := range subpops {
+//		subpops[i].Evolve(seeds[i], ctx.Evolve(i, body))
+//	}
+//
+// Within the body, suitors are *Part values whose fitness is the joint fitness.
+// The body may return either a new part or an unwrapped genome, which is
+// wrapped automatically.
+package coop
+
+import (
+	"math/rand"
+	"sync"
+
+	"github.com/cbarrick/evo"
+)
+
+// An Objective evaluates a complete solution, given one part for each
+// subcomponent.
+type Objective func(parts []evo.Genome) float64
+
+// A Context is the best known complete solution. Contexts are safe for
+// concurrent use and implement evo.Genome; the fitness of a context is the
+// fitness of the best known solution.
+type Context struct {
+	objective Objective
+
+	mu      sync.RWMutex
+	parts   []evo.Genome
+	fit     float64
+	version int // incremented whenever the context improves
+}
+
+// New returns a context for the given objective, initialized with one part for
+// each subcomponent.
+func New(objective Objective, initial []evo.Genome) *Context {
+	parts := append([]evo.Genome(nil), initial...)
+	return &Context{
+		objective: objective,
+		parts:     parts,
+		fit:       objective(parts),
+	}
+}
+
+// Fitness returns the fitness of the best known solution.
+func (c *Context) Fitness() float64 {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.fit
+}
+
+// Best returns the parts of the best known solution.
+func (c *Context) Best() (parts []evo.Genome) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return append(parts, c.parts...)
+}
+
+// Part wraps a genome as a part of the i-th subcomponent.
+func (c *Context) Part(i int, g evo.Genome) *Part {
+	return &Part{Genome: g, ctx: c, idx: i}
+}
+
+// Evolve returns an evolve function for the subpopulation of the i-th
+// subcomponent. The body is called as usual, and the replacement it returns is
+// wrapped as a part when it is not one already.
+func (c *Context) Evolve(i int, body evo.EvolveFn) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		if _, ok := current.(*Part); !ok {
+			current = c.Part(i, current)
+		}
+		parts := make([]evo.Genome, len(suitors))
+		for j := range suitors {
+			parts[j] = suitors[j]
+			if _, ok := parts[j].(*Part); !ok {
+				parts[j] = c.Part(i, parts[j])
+			}
+		}
+		val := body(current, parts)
+		if _, ok := val.(*Part); !ok {
+			val = c.Part(i, val)
+		}
+		return val
+	}
+}
+
+// evaluate returns the joint fitness of g as the i-th part of the context,
+// updating the context if the fitness improves upon it. If the context changes
+// during the evaluation, the evaluation is repeated with the new context.
+func (c *Context) evaluate(i int, g evo.Genome) (fit float64) {
+	for {
+		c.mu.RLock()
+		parts := append([]evo.Genome(nil), c.parts...)
+		version := c.version
+		c.mu.RUnlock()
+
+		parts[i] = g
+		fit = c.objective(parts)
+
+		c.mu.Lock()
+		if c.version == version {
+			if c.fit < fit {
+				c.parts = parts
+				c.fit = fit
+				c.version++
+			}
+			c.mu.Unlock()
+			return fit
+		}
+		c.mu.Unlock()
+	}
+}
+
+// A Part wraps the genome of a subcomponent. The fitness of a part is computed
+// once, by evaluating it jointly with the context.
+type Part struct {
+	evo.Genome
+	ctx  *Context
+	idx  int
+	fit  float64
+	once sync.Once
+}
+
+// Fitness returns the joint fitness of the part.
+func (p *Part) Fitness() float64 {
+	p.once.Do(func() {
+		p.fit = p.ctx.evaluate(p.idx, p.Genome)
+	})
+	return p.fit
+}
+
+// Groups decomposes the indices [0,dim) into n contiguous groups of nearly
+// equal size.
+func Groups(dim, n int) [][]int {
+	groups := make([][]int, n)
+	size, extra := dim/n, dim%n
+	for i, start := 0, 0; i < n; i++ {
+		end := start + size
+		if i < extra {
+			end++
+		}
+		groups[i] = make([]int, 0, end-start)
+		for j := start; j < end; j++ {
+			groups[i] = append(groups[i], j)
+		}
+		start = end
+	}
+	return groups
+}
+
+// RandomGroups decomposes the indices [0,dim) into n groups of nearly equal
+// size, assigning the indices randomly. Regrouping randomly every few cycles
+// increases the chance that interacting variables are optimized together.
+func RandomGroups(dim, n int) [][]int {
+	perm := rand.Perm(dim)
+	groups := Groups(dim, n)
+	for _, g := range groups {
+		for j := range g {
+			g[j] = perm[g[j]]
+		}
+	}
+	return groups
+}
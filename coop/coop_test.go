@@ -0,0 +1,70 @@
+package coop_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/coop"
+)
+
+type part float64
+
+func (p part) Fitness() float64 { return 0 }
+
+// sphere is the negative sum of squares of the parts.
+func sphere(parts []evo.Genome) (fit float64) {
+	for _, p := range parts {
+		x := float64(p.(part))
+		fit -= x * x
+	}
+	return fit
+}
+
+func TestContext(t *testing.T) {
+	ctx := coop.New(sphere, []evo.Genome{part(1), part(2), part(3)})
+	if ctx.Fitness() != -14 {
+		t.Fail()
+	}
+	if ctx.Part(1, part(0)).Fitness() != -10 {
+		t.Fail()
+	}
+	if ctx.Part(0, part(5)).Fitness() != -34 {
+		t.Fail()
+	}
+	best := ctx.Best()
+	if ctx.Fitness() != -10 || best[0] != part(1) || best[1] != part(0) {
+		t.Fail()
+	}
+}
+
+func TestEvolve(t *testing.T) {
+	ctx := coop.New(sphere, []evo.Genome{part(1), part(2)})
+	body := ctx.Evolve(0, func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		if _, ok := suitors[0].(*coop.Part); !ok {
+			t.Fail()
+		}
+		return part(0)
+	})
+	val := body(part(1), []evo.Genome{part(1)})
+	if val.(*coop.Part).Genome != part(0) || val.Fitness() != -4 {
+		t.Fail()
+	}
+}
+
+func TestGroups(t *testing.T) {
+	groups := coop.RandomGroups(10, 3)
+	if len(groups[0]) != 4 || len(groups[1]) != 3 || len(groups[2]) != 3 {
+		t.Fail()
+	}
+	var all []int
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	sort.Ints(all)
+	for i := range all {
+		if all[i] != i {
+			t.Fail()
+		}
+	}
+}
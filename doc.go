@@ -14,7 +14,11 @@
 // representations are provided as subpackages of Evo.
 //
 // Populations model the evolution patterns of genomes. A few different
-// population types are provided by Evo under the package `evo/pop`. Populations
+// population types are provided by Evo under the package `evo/pop`: the
+// generational `gen.Population`, and the spatial `graph.Graph`, which covers the
+// diffusion model, along with `graph.Serial`, its single-threaded counterpart.
+// Every population type implements the same Population interface, with Evolve,
+// Stop, Poll, Wait, Stats, and View, so they are interchangeable. Populations
 // themselves implement the Genome interface, making them composeable. Migration
 // functions are provided to be used in this context, allowing go novel
 // architectures like the island model.
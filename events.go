@@ -0,0 +1,160 @@
+package evo
+
+import (
+	"sync"
+	"time"
+)
+
+// An EventKind identifies the kind of an optimization event.
+type EventKind int
+
+// The kinds of events published by populations.
+const (
+	// NewBest is published when a generation improves on the best fitness
+	// seen so far. The event carries the new best genome.
+	NewBest EventKind = iota
+
+	// GenerationDone is published after each generation, or each sweep of a
+	// graph population.
+	GenerationDone
+
+	// MigrationDone is published by an island after migrating individuals to
+	// a neighboring island.
+	MigrationDone
+
+	// Stagnation is published once the best fitness has not improved for the
+	// configured number of generations. It is published again only after the
+	// next improvement.
+	Stagnation
+
+	// Stopped is published when the population stops evolving.
+	Stopped
+
+	// Tuned is published by a tuner when it recommends a population size or
+	// concurrency. The note of the event describes the decision.
+	Tuned
+)
+
+// String returns the name of the kind.
+func (k EventKind) String() string {
+	switch k {
+	case NewBest:
+		return "NewBest"
+	case GenerationDone:
+		return "GenerationDone"
+	case MigrationDone:
+		return "MigrationDone"
+	case Stagnation:
+		return "Stagnation"
+	case Stopped:
+		return "Stopped"
+	case Tuned:
+		return "Tuned"
+	}
+	return "EventKind(?)"
+}
+
+// An Event describes a milestone of the optimization.
+type Event struct {
+	Kind       EventKind
+	Time       time.Time
+	Generation int    // the generation of the population
+	Stats      Stats  // the statistics of the population, if known
+	Best       Genome // the new best genome, for NewBest events
+	Note       string // a description of the event, for Tuned events
+}
+
+// Events publishes optimization events to subscribers. Populations use Events
+// to implement their Subscribe method, which decouples monitoring, archiving,
+// and adaptive control from the evolution loop. The zero value is ready to use.
+// Events are safe for concurrent use.
+type Events struct {
+	mu       sync.Mutex
+	subs     []chan<- Event
+	clock    Clock   // times the events, SystemClock if nil
+	patience int     // generations without improvement before stagnation
+	best     float64 // the best fitness seen so far
+	seen     bool    // true once the first generation is seen
+	improved int     // the generation of the last improvement
+	stagnant bool    // true once stagnation is published
+}
+
+// Subscribe registers a channel to receive events. Events are sent without
+// blocking the evolution, so events are dropped when the channel is full; use a
+// buffered channel sized to the expected rate of events.
+func (e *Events) Subscribe(ch chan<- Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.subs = append(e.subs, ch)
+}
+
+// SetPatience sets the number of generations without improvement after which
+// Stagnation is published. A patience of 0 disables stagnation events.
+func (e *Events) SetPatience(n int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.patience = n
+}
+
+// SetClock sets the clock which times the events, by default the system clock,
+// e.g. to the clock of the population, so that events published under a
+// FakeClock have deterministic times.
+func (e *Events) SetClock(c Clock) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.clock = c
+}
+
+// Publish sends an event to the subscribers. The time of the event is set by
+// the clock of the events if it is zero.
+func (e *Events) Publish(ev Event) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.publish(ev)
+}
+
+// Generation publishes GenerationDone for a generation with the given
+// statistics, preceded by NewBest if the maximum fitness improved, and followed
+// by Stagnation if the patience ran out. The best function returns the best
+// genome of the generation; it is only called for NewBest events.
+func (e *Events) Generation(gen int, stats Stats, best func() Genome) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if len(e.subs) == 0 {
+		return
+	}
+	now := e.now()
+	if !e.seen || e.best < stats.Max() {
+		e.seen = true
+		e.best = stats.Max()
+		e.improved = gen
+		e.stagnant = false
+		e.publish(Event{Kind: NewBest, Time: now, Generation: gen, Stats: stats, Best: best()})
+	}
+	e.publish(Event{Kind: GenerationDone, Time: now, Generation: gen, Stats: stats})
+	if 0 < e.patience && !e.stagnant && e.patience <= gen-e.improved {
+		e.stagnant = true
+		e.publish(Event{Kind: Stagnation, Time: now, Generation: gen, Stats: stats})
+	}
+}
+
+// publish sends an event while holding the lock.
+func (e *Events) publish(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = e.now()
+	}
+	for _, ch := range e.subs {
+		select {
+		case ch <- ev:
+		default:
+		}
+	}
+}
+
+// now returns the time of the clock of the events.
+func (e *Events) now() time.Time {
+	if e.clock == nil {
+		return SystemClock.Now()
+	}
+	return e.clock.Now()
+}
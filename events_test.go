@@ -0,0 +1,78 @@
+package evo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestEvents(t *testing.T) {
+	var events evo.Events
+	ch := make(chan evo.Event, 16)
+	events.Subscribe(ch)
+	events.SetPatience(2)
+
+	best := func() evo.Genome { return nil }
+	for gen, max := range []float64{1, 2, 2, 2, 2, 3} {
+		var stats evo.Stats
+		events.Generation(gen+1, stats.Put(max), best)
+	}
+	events.Publish(evo.Event{Kind: evo.Stopped})
+	close(ch)
+
+	var kinds []evo.EventKind
+	for ev := range ch {
+		kinds = append(kinds, ev.Kind)
+		if ev.Time.IsZero() {
+			t.Error("missing time")
+		}
+	}
+	want := []evo.EventKind{
+		evo.NewBest, evo.GenerationDone, // 1
+		evo.NewBest, evo.GenerationDone, // 2
+		evo.GenerationDone,                 // 3
+		evo.GenerationDone, evo.Stagnation, // 4
+		evo.GenerationDone,              // 5
+		evo.NewBest, evo.GenerationDone, // 6
+		evo.Stopped,
+	}
+	if len(kinds) != len(want) {
+		t.Fatal(kinds)
+	}
+	for i := range want {
+		if kinds[i] != want[i] {
+			t.Fatal(kinds)
+		}
+	}
+}
+
+func TestEventsFull(t *testing.T) {
+	var events evo.Events
+	ch := make(chan evo.Event)
+	events.Subscribe(ch)
+	events.Publish(evo.Event{Kind: evo.Stopped}) // must not block
+}
+
+func TestEventsClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := evo.NewFakeClock(start)
+	var events evo.Events
+	ch := make(chan evo.Event, 4)
+	events.Subscribe(ch)
+	events.SetClock(clock)
+
+	events.Generation(1, evo.Stats{}.Put(1), func() evo.Genome { return nil })
+	clock.Advance(time.Minute)
+	events.Publish(evo.Event{Kind: evo.Stopped})
+	close(ch)
+
+	want := []time.Time{start, start, start.Add(time.Minute)}
+	var i int
+	for ev := range ch {
+		if !ev.Time.Equal(want[i]) {
+			t.Errorf("%v at %v, want %v", ev.Kind, ev.Time, want[i])
+		}
+		i++
+	}
+}
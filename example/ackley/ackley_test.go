@@ -38,10 +38,9 @@ var (
 // optimizes the ackley function. Each genome also contains a vector of strategy
 // parameters used with a self-adaptive evolution strategy.
 type ackley struct {
-	gene  real.Vector // the object vector to optimize
-	steps real.Vector // strategy parameters for mutation
-	fit   float64     // the ackley function of the gene
-	once  sync.Once   // used to compute fitness lazily
+	evo.Cache             // memoizes the ackley function of the gene
+	gene      real.Vector // the object vector to optimize
+	steps     real.Vector // strategy parameters for mutation
 }
 
 // Returns the fitness as a string.
@@ -52,10 +51,10 @@ func (ack *ackley) String() string {
 // Fitness returns the ackley function of the gene. We are trying to solve a
 // minimization problem, so we return the negative of the traditional formula.
 // The fitness of a genome is only computed once across all calls to Fitness by
-// using a sync.Once.
+// using an evo.Cache.
 func (ack *ackley) Fitness() float64 {
 	const a, b = 20, 0.2
-	ack.once.Do(func() {
+	return ack.Cache.Fitness(func() (fit float64) {
 		var sum1, sum2 float64
 		n := float64(dim)
 		for _, x := range ack.gene {
@@ -63,18 +62,18 @@ func (ack *ackley) Fitness() float64 {
 			sum2 += math.Cos(2 * math.Pi * x)
 		}
 
-		ack.fit -= a
-		ack.fit *= math.Exp(-b * math.Sqrt(sum1/n))
-		ack.fit -= math.Exp(sum2 / n)
-		ack.fit += a
-		ack.fit += math.E
-		ack.fit *= -1
+		fit -= a
+		fit *= math.Exp(-b * math.Sqrt(sum1/n))
+		fit -= math.Exp(sum2 / n)
+		fit += a
+		fit += math.E
+		fit *= -1
 
 		count.Lock()
 		count.n++
 		count.Unlock()
+		return fit
 	})
-	return ack.fit
 }
 
 // Evolve implements the inner loop of the evolutionary algorithm.
@@ -168,14 +167,5 @@ func TestAckley(t *testing.T) {
 
 	pop.Wait()
 	selector.Close()
-	best := seed[0]
-	bestFit := seed[0].Fitness()
-	for i := range seed {
-		fit := seed[i].Fitness()
-		if fit > bestFit {
-			best = seed[i]
-			bestFit = fit
-		}
-	}
-	fmt.Println("\nSolution:", best)
+	fmt.Println("\nSolution:", pop.Best().Genome)
 }
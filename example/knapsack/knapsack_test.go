@@ -0,0 +1,104 @@
+package knapsack
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/knapsack"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/sel"
+)
+
+// Tuneables
+const (
+	items = 200 // the number of items
+	size  = 100 // the size of the population
+)
+
+// Global objects
+var (
+	// A random instance of the 0/1 knapsack problem. Solutions are bit strings
+	// choosing which items to pack. Infeasible solutions are greedily repaired.
+	inst = knapsack.Random(items, 100)
+)
+
+// Evolution implements the body of the evolution loop.
+func Evolution(current evo.Genome, suitors []evo.Genome) evo.Genome {
+	// Selection:
+	// Select each parent using a simple random binary tournament
+	mom := sel.BinaryTournament(suitors...).(*knapsack.Genome)
+	dad := sel.BinaryTournament(suitors...).(*knapsack.Genome)
+
+	// Crossover:
+	// Uniform crossover, each bit is taken from either parent
+	gene := make([]bool, items)
+	for i := range gene {
+		if rand.Intn(2) == 0 {
+			gene[i] = mom.Gene[i]
+		} else {
+			gene[i] = dad.Gene[i]
+		}
+	}
+
+	// Mutation:
+	// Each bit is flipped with probability 1/n
+	for i := range gene {
+		if rand.Intn(items) == 0 {
+			gene[i] = !gene[i]
+		}
+	}
+
+	// Replacement:
+	// Only replace if the child is better or equal
+	child := inst.New(gene, knapsack.Repair)
+	if current.Fitness() > child.Fitness() {
+		return current
+	}
+	return child
+}
+
+func TestKnapsack(t *testing.T) {
+	_, optimum := inst.Solve()
+	fmt.Printf("Pack %d items - optimal profit is %d\n", items, optimum)
+
+	// Setup:
+	// We create an initial set of random candidates
+	// and evolve them in a generational population.
+	seed := make([]evo.Genome, size)
+	for i := range seed {
+		seed[i] = inst.Random(knapsack.Repair)
+	}
+	var pop gen.Population
+	pop.Evolve(seed, Evolution)
+
+	// Continuously print statistics while the optimization runs.
+	pop.Poll(0, func() bool {
+		stats := pop.Stats()
+
+		// "\x1b[2K" is the xterm escape code to clear the line
+		fmt.Printf("\x1b[2K\rCount: %7d | Max: %5.0f | Mean: %7.1f | Min: %5.0f | RSD: %9.2e",
+			pop.Throughput().Evaluations,
+			stats.Max(),
+			stats.Mean(),
+			stats.Min(),
+			stats.RSD())
+
+		return false
+	})
+
+	// Terminate when we've found the optimum
+	pop.Poll(0, func() bool {
+		stats := pop.Stats()
+		return stats.Max() == float64(optimum)
+	})
+
+	// Terminate after 1,000,000 fitness evaluations.
+	pop.Poll(0, func() bool {
+		return pop.Throughput().Evaluations > 1e6
+	})
+
+	pop.Wait()
+	fmt.Printf("\nBest profit: %.0f of %d\n", pop.Stats().Max(), optimum)
+}
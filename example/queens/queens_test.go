@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/island"
 	"github.com/cbarrick/evo/perm"
 	"github.com/cbarrick/evo/pop/gen"
 	"github.com/cbarrick/evo/pop/graph"
@@ -36,9 +37,8 @@ var (
 // The queens type is our genome. We evolve a permuation of [0,n)
 // representing the position of queens on an n x n board
 type queens struct {
-	gene    []int     // permutation representation of an n-queens solution
-	fitness float64   // the negative of the number of conflicts in the solution
-	once    sync.Once // used to compute fitness lazily
+	evo.Cache       // memoizes the fitness
+	gene      []int // permutation representation of an n-queens solution
 }
 
 // String returns the gene contents and number of conflicts.
@@ -48,28 +48,28 @@ func (q *queens) String() string {
 
 // Fitness returns the negative of the number of conflicts in the solution.
 // The fitness of a genome is only computed once across all calls to Fitness by
-// using a sync.Once.
+// using an evo.Cache.
 func (q *queens) Fitness() float64 {
-	q.once.Do(func() {
+	return q.Cache.Fitness(func() (fitness float64) {
 		for i := range q.gene {
 			for j, k := 1, i-1; k >= 0; j, k = j+1, k-1 {
 				if q.gene[k] == q.gene[i]+j || q.gene[k] == q.gene[i]-j {
-					q.fitness--
+					fitness--
 				}
 			}
 			for j, k := 1, i+1; k < len(q.gene); j, k = j+1, k+1 {
 				if q.gene[k] == q.gene[i]+j || q.gene[k] == q.gene[i]-j {
-					q.fitness--
+					fitness--
 				}
 			}
 		}
-		q.fitness /= 2
+		fitness /= 2
 
 		count.Lock()
 		count.n++
 		count.Unlock()
+		return fitness
 	})
-	return q.fitness
 }
 
 // Evolution implements the body of the evolution loop.
@@ -107,20 +107,17 @@ func TestQueens(t *testing.T) {
 	// Setup:
 	// We create an initial set of random candidates and divide them into "islands".
 	// Each island is evolved independently in a generational population.
-	// The islands are then linked together into a graph population with
+	// The islands are then linked together into a ring, and individuals
+	// occasionally migrate between neighboring islands.
 	seed := make([]evo.Genome, size)
 	for i := range seed {
 		seed[i] = &queens{gene: perm.New(dim)}
 	}
-	islands := make([]evo.Genome, isl)
-	islSize := size / isl
-	for i := range islands {
-		var island gen.Population
-		island.Evolve(seed[i*islSize:(i+1)*islSize], Evolution)
-		islands[i] = &island
-	}
-	pop := graph.Ring(isl)
-	pop.Evolve(islands, gen.Migrate(migration, delay))
+	pop := island.New(seed, isl,
+		func() evo.Population { return new(gen.Population) },
+		graph.Ring,
+		gen.Migrate(migration, delay),
+		Evolution)
 
 	// Continuously print statistics while the optimization runs.
 	pop.Poll(0, func() bool {
@@ -2,25 +2,35 @@ package tsp
 
 import (
 	"fmt"
-	"math"
 	"math/rand"
 	"sync"
 	"testing"
 
 	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/op"
 	"github.com/cbarrick/evo/perm"
 	"github.com/cbarrick/evo/pop/graph"
 	"github.com/cbarrick/evo/sel"
+	"github.com/cbarrick/evo/tsplib"
 )
 
 // Constants
 const (
-	dim  = len(cities) // the dimension of the problem
-	size = 256         // the size of the population
+	size = 256 // the size of the population
 )
 
 // Global objects
 var (
+	// The problem instance: the capitals of the 48 contiguous American states.
+	// This is dataset ATT48 from TSPLIB, a collection of traveling salesman
+	// problem datasets maintained by Dr. Gerhard Reinelt. Distances are
+	// pseudo-euclidian, as used in the literature on this problem instance.
+	// "http://comopt.ifi.uni-heidelberg.de/software/TSPLIB95/"
+	problem = load("testdata/att48.tsp")
+
+	// The dimension of the problem.
+	dim = problem.Dimension
+
 	// The evolutionary loop managed by the population
 	pop evo.Population
 
@@ -30,6 +40,26 @@ var (
 		n int
 	}
 
+	// Local search: 10% of children undergo a step of 2-opt hillclimbing.
+	memetic = op.Memetic{
+		Search: op.LocalSearchFunc(func(g evo.Genome, budget int) evo.Genome {
+			t := g.(*tsp)
+			for i := 0; i < budget; i++ {
+				t.TwoOpt()
+			}
+			return t
+		}),
+		Rate:   0.1,
+		Budget: 1,
+	}
+
+	// Mutation: the swap rate decays from 20% to 5% over a million
+	// evaluations, exploring early and fine-tuning late. The clock counts the
+	// evaluations of the population, and is set once it is created.
+	swapRate = op.Rate{
+		Schedule: op.Exponential(0.2, 0.05, 1e6),
+	}
+
 	// A free-list used to recycle memory.
 	pool = sync.Pool{
 		New: func() interface{} {
@@ -38,26 +68,19 @@ var (
 	}
 )
 
-// A city is a coordinate pair giving the position of the city.
-type city struct {
-	x, y float64
-}
-
-// Dist returns the pseudo-euclidian distance between two cities. This is the
-// distance function used in the literature on this problem instance.
-// http://comopt.ifi.uni-heidelberg.de/software/TSPLIB95/DOC.PS
-func dist(a, b city) float64 {
-	xd := a.x - b.x
-	yd := a.y - b.y
-	d := math.Sqrt(xd*xd/10 + yd*yd/10)
-	return math.Ceil(d)
+// load loads a problem instance from a TSPLIB file.
+func load(path string) *tsplib.Problem {
+	p, err := tsplib.Load(path)
+	if err != nil {
+		panic(err)
+	}
+	return p
 }
 
 // The tsp type is our genome type.
 type tsp struct {
-	gene    []int     // permutation representation of a tour
-	fitness float64   // the negative length of the tour
-	once    sync.Once // used to compute fitness lazily
+	evo.Cache       // memoizes the negative length of the tour
+	gene      []int // permutation representation of a tour
 }
 
 // String returns the gene contents and length of the tour.
@@ -77,39 +100,36 @@ func (t *tsp) Close() {
 // (i.e. the result of pop.Stats()) are also negative: the shortest know path
 // would be -stats.Max().
 func (t *tsp) Fitness() float64 {
-	t.once.Do(func() {
-		t.fitness = 0
+	return t.Cache.Fitness(func() (fitness float64) {
 		for i := range t.gene {
-			a := cities[t.gene[i]]
-			b := cities[t.gene[(i+1)%dim]]
-			t.fitness -= dist(a, b)
+			fitness -= problem.Dist(t.gene[i], t.gene[(i+1)%dim])
 		}
 
 		count.Lock()
 		count.n++
 		count.Unlock()
+		return fitness
 	})
-	return t.fitness
 }
 
 // TwoOpt performs a 2-opt local search for improvement of the gene. The first
 // edge is selected at random and inversions between all other edges are
 // evaluated in random order. Even if an improvement is not found, the gene will
-// be rotated by an uniform-random amount. We use this search as a form of
-// mutation.
+// be rotated by an uniform-random amount. We apply this search to children as a
+// memetic hybrid.
 func (t *tsp) TwoOpt() {
-	t.once = sync.Once{}
+	t.Invalidate()
 	perm.Rotate(t.gene, rand.Intn(dim))
 	for _, i := range rand.Perm(dim) {
 		if i < 2 {
 			continue
 		}
-		a := cities[t.gene[0]]
-		b := cities[t.gene[i-1]]
-		y := cities[t.gene[i]]
-		z := cities[t.gene[dim-1]]
-		before := dist(b, y) + dist(z, a)
-		after := dist(a, y) + dist(z, b)
+		a := t.gene[0]
+		b := t.gene[i-1]
+		y := t.gene[i]
+		z := t.gene[dim-1]
+		before := problem.Dist(b, y) + problem.Dist(z, a)
+		after := problem.Dist(a, y) + problem.Dist(z, b)
 		if after < before {
 			perm.Reverse(t.gene[:i])
 			return
@@ -132,14 +152,16 @@ func Evolve(current evo.Genome, matingPool []evo.Genome) evo.Genome {
 	perm.EdgeX(child.gene, mom.gene, dad.gene)
 
 	// Mutation:
-	// There is an n% chance for the gene to have n random swaps
-	// and an n% chance to undergo n steps of a greedy 2-opt hillclimber
-	for rand.Float64() < 0.1 {
+	// There is an n% chance for the gene to have n random swaps,
+	// where n decays over the run
+	rate := swapRate.Value()
+	for rand.Float64() < rate {
 		perm.RandSwap(child.gene)
 	}
-	for rand.Float64() < 0.1 {
-		child.TwoOpt()
-	}
+
+	// Local search:
+	// The memetic hybrid runs a greedy 2-opt hillclimber on some children
+	child = memetic.Apply(child).(*tsp)
 
 	// Replacement:
 	// Only replace if the child is better or equal
@@ -159,7 +181,9 @@ func TestTSP(t *testing.T) {
 	for i := range seed {
 		seed[i] = &tsp{gene: pool.Get().([]int)}
 	}
-	pop = graph.Hypercube(size)
+	hypercube := graph.Hypercube(size)
+	swapRate.Clock = op.Evaluations(hypercube)
+	pop = hypercube
 	pop.Evolve(seed, Evolve)
 
 	// Continuously print statistics while the optimization runs.
@@ -210,58 +234,3 @@ func TestTSP(t *testing.T) {
 
 // Best is the minimum tour of the cities.
 const best = 10628
-
-// Cities is a list of the capitals of the 48 contiguous American states. The
-// minimum tour length is 10628. This is dataset ATT48 from TSPLIB, a collection
-// of traveling salesman problem datasets maintained by Dr. Gerhard Reinelt:
-// "http://comopt.ifi.uni-heidelberg.de/software/TSPLIB95/"
-var cities = [48]city{
-	{6734, 1453},
-	{2233, 10},
-	{5530, 1424},
-	{401, 841},
-	{3082, 1644},
-	{7608, 4458},
-	{7573, 3716},
-	{7265, 1268},
-	{6898, 1885},
-	{1112, 2049},
-	{5468, 2606},
-	{5989, 2873},
-	{4706, 2674},
-	{4612, 2035},
-	{6347, 2683},
-	{6107, 669},
-	{7611, 5184},
-	{7462, 3590},
-	{7732, 4723},
-	{5900, 3561},
-	{4483, 3369},
-	{6101, 1110},
-	{5199, 2182},
-	{1633, 2809},
-	{4307, 2322},
-	{675, 1006},
-	{7555, 4819},
-	{7541, 3981},
-	{3177, 756},
-	{7352, 4506},
-	{7545, 2801},
-	{3245, 3305},
-	{6426, 3173},
-	{4608, 1198},
-	{23, 2216},
-	{7248, 3779},
-	{7762, 4595},
-	{7392, 2244},
-	{3484, 2829},
-	{6271, 2135},
-	{4985, 140},
-	{1916, 1569},
-	{7280, 4899},
-	{7509, 3239},
-	{10, 2676},
-	{6807, 2993},
-	{5185, 3258},
-	{3023, 1942},
-}
@@ -0,0 +1,142 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
+
+// Resamples is the number of bootstrap resamples of summaries and comparisons.
+const Resamples = 2000
+
+// An Interval is a confidence interval.
+type Interval struct {
+	Lo, Hi float64
+	Level  float64 // the confidence level, e.g. 0.95
+}
+
+// Contains returns true if the value lies in the interval.
+func (iv Interval) Contains(x float64) bool {
+	return iv.Lo <= x && x <= iv.Hi
+}
+
+// String returns the interval as [lo,hi].
+func (iv Interval) String() string {
+	return fmt.Sprintf("[%g,%g]", iv.Lo, iv.Hi)
+}
+
+// Mean returns the arithmetic mean of the values.
+func Mean(x []float64) float64 {
+	if len(x) == 0 {
+		return math.NaN()
+	}
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// Median returns the median of the values, without modifying them.
+func Median(x []float64) float64 {
+	return median(append([]float64(nil), x...))
+}
+
+// Bootstrap returns the percentile bootstrap confidence interval of a statistic
+// of a sample, e.g. Mean or Median, from n resamples with replacement. The
+// interval is reasonable for samples of a few dozen repetitions and more; for
+// smaller samples it tends to be too narrow.
+func Bootstrap(x []float64, stat func([]float64) float64, level float64, n int) Interval {
+	if len(x) == 0 {
+		return Interval{math.NaN(), math.NaN(), level}
+	}
+	resample := make([]float64, len(x))
+	stats := make([]float64, n)
+	for i := range stats {
+		for j := range resample {
+			resample[j] = x[rand.Intn(len(x))]
+		}
+		stats[i] = stat(resample)
+	}
+	return percentiles(stats, level)
+}
+
+// BootstrapDiff returns the percentile bootstrap confidence interval of the
+// difference stat(x) - stat(y) between two independent samples, from n
+// resamples of each. An interval excluding 0 indicates a difference at the
+// given level.
+func BootstrapDiff(x, y []float64, stat func([]float64) float64, level float64, n int) Interval {
+	if len(x) == 0 || len(y) == 0 {
+		return Interval{math.NaN(), math.NaN(), level}
+	}
+	rx := make([]float64, len(x))
+	ry := make([]float64, len(y))
+	stats := make([]float64, n)
+	for i := range stats {
+		for j := range rx {
+			rx[j] = x[rand.Intn(len(x))]
+		}
+		for j := range ry {
+			ry[j] = y[rand.Intn(len(y))]
+		}
+		stats[i] = stat(rx) - stat(ry)
+	}
+	return percentiles(stats, level)
+}
+
+// percentiles returns the central interval of the bootstrap statistics at the
+// given level, sorting them in place.
+func percentiles(stats []float64, level float64) Interval {
+	sort.Float64s(stats)
+	alpha := (1 - level) / 2
+	lo := int(math.Floor(alpha * float64(len(stats)-1)))
+	hi := int(math.Ceil((1 - alpha) * float64(len(stats)-1)))
+	return Interval{stats[lo], stats[hi], level}
+}
+
+// CohenD returns Cohen's d, the difference between the means of two samples in
+// units of their pooled standard deviation. Conventionally, 0.2 is a small
+// effect, 0.5 a medium effect, and 0.8 a large effect.
+func CohenD(x, y []float64) float64 {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 < 2 || n2 < 2 {
+		return math.NaN()
+	}
+	mx, my := Mean(x), Mean(y)
+	var ssx, ssy float64
+	for _, v := range x {
+		ssx += (v - mx) * (v - mx)
+	}
+	for _, v := range y {
+		ssy += (v - my) * (v - my)
+	}
+	sd := math.Sqrt((ssx + ssy) / (n1 + n2 - 2))
+	if sd == 0 {
+		return 0
+	}
+	return (mx - my) / sd
+}
+
+// VarghaDelaney returns the Vargha-Delaney A statistic: the probability that a
+// value drawn from the first sample is greater than one drawn from the second,
+// counting ties as half. It is the effect size recommended for comparing
+// randomized algorithms; 0.5 means no effect, and 0.56, 0.64, and 0.71 are
+// conventionally small, medium, and large effects.
+func VarghaDelaney(x, y []float64) float64 {
+	if len(x) == 0 || len(y) == 0 {
+		return 0.5
+	}
+	var wins float64
+	for _, a := range x {
+		for _, b := range y {
+			switch {
+			case a > b:
+				wins++
+			case a == b:
+				wins += 0.5
+			}
+		}
+	}
+	return wins / float64(len(x)*len(y))
+}
@@ -0,0 +1,148 @@
+// Package experiment runs optimization experiments described by configuration
+// files.
+//
+// An experiment names a problem, the population which evolves it, the
+// variation operators, the termination conditions, and the number of
+// repetitions. Configurations are written in JSON:
+//
+//	{
+//		"name": "rastrigin",
+//		"problem": "bench:rastrigin",
+//		"dim": 30,
+//		"population": {"type": "graph", "size": 256, "topology": "hypercube"},
+//		"operators": {"tournament": 2, "crossover": 0.9, "mutation": 0.05},
+//		"termination": {"evaluations": 100000, "duration": "30s"},
+//		"repetitions": 10,
+//		"parallel": 4,
+//		"output": "results",
+//		"plot": true
+//	}
+//
+// Problems are named by a kind and an argument separated by a colon. The
+// built-in kinds are "bench" for the functions of the bench package, and
+// "knapsack", "tsp", "qap", and "jobshop" for instance files of the respective
+// packages. Bundled knapsack instances may be named directly, e.g.
+// "knapsack:p01". Other kinds can be added with Register, or loaded from Go
+// plugins with LoadPlugin.
+//
+// The cmd/evo tool runs experiments from the command line.
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+// A Config describes an experiment.
+type Config struct {
+	Name        string      `json:"name"`
+	Problem     string      `json:"problem"`     // kind:argument
+	Dim         int         `json:"dim"`         // dimension, for problems which need one
+	Population  Population  `json:"population"`  // the population model
+	Operators   Operators   `json:"operators"`   // the variation operators
+	Termination Termination `json:"termination"` // when to stop each repetition
+	Repetitions int         `json:"repetitions"` // independent runs, at least 1
+	Parallel    int         `json:"parallel"`    // repetitions run at once, 0 for GOMAXPROCS
+	Output      string      `json:"output"`      // directory for results, none if empty
+	Plot        bool        `json:"plot"`        // record convergence and plot each repetition
+}
+
+// A Population describes the population model.
+type Population struct {
+	Type     string `json:"type"`     // "gen" or "graph"
+	Size     int    `json:"size"`     // the number of genomes
+	Topology string `json:"topology"` // for graphs: ring, hypercube, torus, or complete
+}
+
+// Operators describe the variation operators.
+type Operators struct {
+	Tournament int     `json:"tournament"` // tournament size for selecting parents, 1 for random
+	Crossover  float64 `json:"crossover"`  // probability of crossover, else the child copies a parent
+	Mutation   float64 `json:"mutation"`   // mutation rate, interpreted by the problem
+	Elitist    bool    `json:"elitist"`    // children only replace less fit genomes
+}
+
+// Termination describes when to stop a repetition. The first condition reached
+// stops the run. At least one condition must be given.
+type Termination struct {
+	Evaluations int      `json:"evaluations"` // maximum fitness evaluations
+	Duration    Duration `json:"duration"`    // maximum wall time
+	Target      *float64 `json:"target"`      // stop when the best fitness reaches the target
+}
+
+// A Duration is a time.Duration written as a string in JSON, e.g. "30s".
+type Duration time.Duration
+
+// MarshalJSON writes the duration as a string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// UnmarshalJSON reads the duration from a string.
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	dur, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(dur)
+	return nil
+}
+
+// Load reads a configuration from a JSON file. Unset fields receive defaults.
+func Load(path string) (Config, error) {
+	var cfg Config
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return cfg, err
+	}
+	if err := json.Unmarshal(b, &cfg); err != nil {
+		return cfg, fmt.Errorf("experiment: %s: %v", path, err)
+	}
+	cfg.defaults()
+	return cfg, cfg.validate()
+}
+
+// defaults fills unset fields.
+func (cfg *Config) defaults() {
+	if cfg.Population.Type == "" {
+		cfg.Population.Type = "gen"
+	}
+	if cfg.Population.Size == 0 {
+		cfg.Population.Size = 100
+	}
+	if cfg.Population.Type == "graph" && cfg.Population.Topology == "" {
+		cfg.Population.Topology = "hypercube"
+	}
+	if cfg.Operators.Tournament == 0 {
+		cfg.Operators.Tournament = 2
+	}
+	if cfg.Repetitions == 0 {
+		cfg.Repetitions = 1
+	}
+	if cfg.Name == "" {
+		cfg.Name = cfg.Problem
+	}
+}
+
+// validate checks the configuration for errors.
+func (cfg *Config) validate() error {
+	switch {
+	case cfg.Problem == "":
+		return fmt.Errorf("experiment: no problem")
+	case cfg.Population.Type != "gen" && cfg.Population.Type != "graph":
+		return fmt.Errorf("experiment: unknown population type %q", cfg.Population.Type)
+	case cfg.Parallel < 0:
+		return fmt.Errorf("experiment: negative parallelism")
+	case cfg.Population.Size < 2:
+		return fmt.Errorf("experiment: population too small")
+	case cfg.Termination.Evaluations <= 0 && cfg.Termination.Duration <= 0 && cfg.Termination.Target == nil:
+		return fmt.Errorf("experiment: no termination condition")
+	}
+	return nil
+}
@@ -0,0 +1,190 @@
+package experiment_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/cbarrick/evo/experiment"
+)
+
+func TestRun(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "config.json")
+	err := os.WriteFile(path, []byte(`{
+		"name": "p01",
+		"problem": "knapsack:p01",
+		"population": {"type": "graph", "size": 16, "topology": "ring"},
+		"operators": {"crossover": 0.9, "mutation": 0.1, "elitist": true},
+		"termination": {"evaluations": 20000, "duration": "5s", "target": 309},
+		"repetitions": 2,
+		"output": "`+dir+`",
+		"plot": true
+	}`), 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := experiment.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, err := experiment.Run(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(report.Results) != 2 {
+		t.Fatal("wrong number of results")
+	}
+	for _, res := range report.Results {
+		if res.Best > 309 || res.Evaluations < 16 {
+			t.Fail()
+		}
+	}
+
+	var written experiment.Report
+	b, err := os.ReadFile(filepath.Join(dir, "p01.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(b, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.Config.Problem != "knapsack:p01" || len(written.Results) != 2 {
+		t.Fail()
+	}
+	for _, res := range written.Results {
+		if len(res.Trace) == 0 {
+			t.Error("missing trace")
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "p01-1.svg")); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	for _, cfg := range []experiment.Config{
+		{Problem: "bench:sphere", Dim: 2},
+		{Problem: "nope:x", Termination: experiment.Termination{Evaluations: 10}},
+		{Problem: "bench:sphere", Termination: experiment.Termination{Evaluations: 10}},
+	} {
+		if _, err := experiment.Run(cfg); err == nil {
+			t.Errorf("expected error for %+v", cfg)
+		}
+	}
+}
+
+// confidence.go
+// -------------------------
+
+func TestBootstrap(t *testing.T) {
+	x := make([]float64, 100)
+	for i := range x {
+		x[i] = float64(i)
+	}
+	iv := experiment.Bootstrap(x, experiment.Mean, 0.95, 1000)
+	if !iv.Contains(49.5) || iv.Lo < 40 || 60 < iv.Hi {
+		t.Error("mean", iv)
+	}
+	y := make([]float64, 100)
+	for i := range y {
+		y[i] = x[i] + 100
+	}
+	if iv := experiment.BootstrapDiff(y, x, experiment.Median, 0.95, 1000); !iv.Contains(100) || iv.Contains(0) {
+		t.Error("diff", iv)
+	}
+}
+
+func TestEffectSize(t *testing.T) {
+	x, y := []float64{4, 5, 6}, []float64{1, 2, 3}
+	if a := experiment.VarghaDelaney(x, y); a != 1 {
+		t.Error("A", a)
+	}
+	if a := experiment.VarghaDelaney(x, x); a != 0.5 {
+		t.Error("A", a)
+	}
+	if d := experiment.CohenD(x, y); d != 3 {
+		t.Error("d", d)
+	}
+	if c := experiment.MannWhitney(x, y); c.A != 1 {
+		t.Error("comparison", c)
+	}
+}
+
+// plugin.go
+// -------------------------
+
+func TestLoadPlugin(t *testing.T) {
+	if err := experiment.LoadPlugin(filepath.Join(t.TempDir(), "missing.so")); err == nil {
+		t.Error("loaded a missing plugin")
+	}
+}
+
+// problem.go
+// -------------------------
+
+func TestKinds(t *testing.T) {
+	experiment.Register("custom", func(arg string, cfg experiment.Config) (experiment.Problem, error) {
+		return nil, nil
+	})
+	kinds := experiment.Kinds()
+	found := false
+	for i, kind := range kinds {
+		if 0 < i && kind < kinds[i-1] {
+			t.Error("unsorted:", kinds)
+		}
+		found = found || kind == "custom"
+	}
+	if !found || len(kinds) < 6 {
+		t.Error(kinds)
+	}
+}
+
+// summary.go
+// -------------------------
+
+func TestParallel(t *testing.T) {
+	target := 0.0
+	report, err := experiment.Run(experiment.Config{
+		Problem:     "bench:sphere",
+		Dim:         2,
+		Population:  experiment.Population{Size: 8},
+		Termination: experiment.Termination{Evaluations: 500, Target: &target},
+		Repetitions: 6,
+		Parallel:    3,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, res := range report.Results {
+		// each repetition runs until its own budget is spent
+		if res.Repetition != i || res.Evaluations < 500 {
+			t.Errorf("bad result %+v", res)
+		}
+	}
+	s := report.Summary()
+	if s.Runs != 6 || s.Best.Count() != 6 || s.Successes != 0 || s.SuccessRate() != 0 {
+		t.Errorf("bad summary %v", s)
+	}
+}
+
+func TestMannWhitney(t *testing.T) {
+	c := experiment.MannWhitney([]float64{1, 2, 3}, []float64{4, 5, 6})
+	if c.U != 0 || 0 <= c.Z || math.Abs(c.P-0.0495) > 1e-3 {
+		t.Errorf("bad comparison %v", c)
+	}
+
+	c = experiment.MannWhitney([]float64{4, 5, 6}, []float64{1, 2, 3})
+	if c.U != 9 || c.Z <= 0 {
+		t.Errorf("bad comparison %v", c)
+	}
+
+	// all ties
+	c = experiment.MannWhitney([]float64{1, 1}, []float64{1, 1})
+	if c.U != 2 || c.P != 1 {
+		t.Errorf("bad comparison %v", c)
+	}
+}
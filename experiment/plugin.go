@@ -0,0 +1,33 @@
+package experiment
+
+import (
+	"fmt"
+	"plugin"
+)
+
+// LoadPlugin loads user problems from a Go plugin, built with
+// "go build -buildmode=plugin". The plugin registers its problems either from
+// an init function calling Register, or by exporting a function
+//
+//	func Register(register func(kind string, factory experiment.Factory))
+//
+// which is called with Register. The second form lets the plugin register
+// problems without sharing the package state of the loader. Plugins are only
+// supported on some platforms; elsewhere LoadPlugin returns an error.
+func LoadPlugin(path string) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("experiment: %v", err)
+	}
+	sym, err := p.Lookup("Register")
+	if err != nil {
+		// registered by init
+		return nil
+	}
+	register, ok := sym.(func(func(string, Factory)))
+	if !ok {
+		return fmt.Errorf("experiment: %s: Register has type %T", path, sym)
+	}
+	register(Register)
+	return nil
+}
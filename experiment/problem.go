@@ -0,0 +1,285 @@
+package experiment
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/bench"
+	"github.com/cbarrick/evo/jobshop"
+	"github.com/cbarrick/evo/knapsack"
+	"github.com/cbarrick/evo/perm"
+	"github.com/cbarrick/evo/qap"
+	"github.com/cbarrick/evo/real"
+	"github.com/cbarrick/evo/tsplib"
+)
+
+// A Problem creates and varies the genomes of an experiment. Genomes should
+// compute their fitness through an evo.Cache so that each is evaluated once.
+// A problem is shared by concurrent repetitions and must be safe for concurrent
+// use.
+type Problem interface {
+	// Random returns a random genome.
+	Random() evo.Genome
+
+	// Cross returns a new child of two parents.
+	Cross(mom, dad evo.Genome) evo.Genome
+
+	// Copy returns a new child copying a parent.
+	Copy(g evo.Genome) evo.Genome
+
+	// Mutate mutates a child in place, before it is evaluated.
+	Mutate(g evo.Genome, rate float64)
+}
+
+// A Factory creates a problem from the argument of the problem name.
+type Factory func(arg string, cfg Config) (Problem, error)
+
+var (
+	mu        sync.Mutex
+	factories = map[string]Factory{
+		"bench":    newBench,
+		"knapsack": newKnapsack,
+		"tsp":      newTSP,
+		"qap":      newQAP,
+		"jobshop":  newJobshop,
+	}
+)
+
+// Register adds a kind of problem. Problems named "kind:argument" are created
+// by the factory.
+func Register(kind string, factory Factory) {
+	mu.Lock()
+	defer mu.Unlock()
+	factories[kind] = factory
+}
+
+// Kinds returns the registered kinds of problem, sorted.
+func Kinds() []string {
+	mu.Lock()
+	defer mu.Unlock()
+	kinds := make([]string, 0, len(factories))
+	for kind := range factories {
+		kinds = append(kinds, kind)
+	}
+	sort.Strings(kinds)
+	return kinds
+}
+
+// NewProblem creates the problem of a configuration.
+func NewProblem(cfg Config) (Problem, error) {
+	kind, arg, _ := strings.Cut(cfg.Problem, ":")
+	mu.Lock()
+	factory, ok := factories[kind]
+	mu.Unlock()
+	if !ok {
+		return nil, fmt.Errorf("experiment: unknown problem %q", cfg.Problem)
+	}
+	return factory(arg, cfg)
+}
+
+// bench
+// -------------------------
+
+type benchProblem struct {
+	fn  *bench.Function
+	dim int
+}
+
+func newBench(arg string, cfg Config) (Problem, error) {
+	for _, fn := range bench.Functions {
+		if fn.Name == arg {
+			if cfg.Dim <= 0 {
+				return nil, fmt.Errorf("experiment: %s needs a dimension", cfg.Problem)
+			}
+			return benchProblem{fn, cfg.Dim}, nil
+		}
+	}
+	return nil, fmt.Errorf("experiment: unknown benchmark %q", arg)
+}
+
+func (p benchProblem) Random() evo.Genome {
+	return p.fn.Random(p.dim)
+}
+
+func (p benchProblem) Cross(mom, dad evo.Genome) evo.Genome {
+	child := make(real.Vector, p.dim)
+	real.ArithX(1, child, mom.(*bench.Genome).X, dad.(*bench.Genome).X)
+	return p.fn.New(child)
+}
+
+func (p benchProblem) Copy(g evo.Genome) evo.Genome {
+	return p.fn.New(g.(*bench.Genome).X.Copy())
+}
+
+// Mutate adds Gaussian noise to each coordinate, with a standard deviation of
+// the rate times the width of the domain.
+func (p benchProblem) Mutate(g evo.Genome, rate float64) {
+	b := g.(*bench.Genome)
+	sd := rate * (p.fn.Upper - p.fn.Lower)
+	for i := range b.X {
+		b.X[i] += real.Normal(sd)
+	}
+	b.Clamp()
+}
+
+// knapsack
+// -------------------------
+
+type knapsackProblem struct {
+	inst *knapsack.Instance
+}
+
+func newKnapsack(arg string, cfg Config) (Problem, error) {
+	for _, inst := range knapsack.Instances {
+		if inst.Name == arg {
+			return knapsackProblem{inst}, nil
+		}
+	}
+	inst, err := knapsack.Load(arg)
+	if err != nil {
+		return nil, err
+	}
+	return knapsackProblem{inst}, nil
+}
+
+func (p knapsackProblem) Random() evo.Genome {
+	return p.inst.Random(knapsack.Repair)
+}
+
+func (p knapsackProblem) Cross(mom, dad evo.Genome) evo.Genome {
+	a, b := mom.(*knapsack.Genome).Gene, dad.(*knapsack.Genome).Gene
+	gene := make([]bool, len(a))
+	for i := range gene {
+		if rand.Intn(2) == 0 {
+			gene[i] = a[i]
+		} else {
+			gene[i] = b[i]
+		}
+	}
+	return p.inst.New(gene, knapsack.Repair)
+}
+
+func (p knapsackProblem) Copy(g evo.Genome) evo.Genome {
+	gene := append([]bool(nil), g.(*knapsack.Genome).Gene...)
+	return p.inst.New(gene, knapsack.Repair)
+}
+
+// Mutate flips each bit with probability rate.
+func (p knapsackProblem) Mutate(g evo.Genome, rate float64) {
+	gene := g.(*knapsack.Genome).Gene
+	for i := range gene {
+		if rand.Float64() < rate {
+			gene[i] = !gene[i]
+		}
+	}
+}
+
+// tsp
+// -------------------------
+
+type tspProblem struct {
+	p *tsplib.Problem
+}
+
+func newTSP(arg string, cfg Config) (Problem, error) {
+	p, err := tsplib.Load(arg)
+	if err != nil {
+		return nil, err
+	}
+	return tspProblem{p}, nil
+}
+
+func (p tspProblem) Random() evo.Genome {
+	return p.p.Random()
+}
+
+func (p tspProblem) Cross(mom, dad evo.Genome) evo.Genome {
+	gene := make([]int, p.p.Dimension)
+	perm.EdgeX(gene, mom.(*tsplib.Tour).Gene, dad.(*tsplib.Tour).Gene)
+	return p.p.New(gene)
+}
+
+func (p tspProblem) Copy(g evo.Genome) evo.Genome {
+	return p.p.New(append([]int(nil), g.(*tsplib.Tour).Gene...))
+}
+
+// Mutate inverts a random segment of the tour with probability rate.
+func (p tspProblem) Mutate(g evo.Genome, rate float64) {
+	if rand.Float64() < rate {
+		perm.RandInvert(g.(*tsplib.Tour).Gene)
+	}
+}
+
+// qap
+// -------------------------
+
+type qapProblem struct {
+	p *qap.Problem
+}
+
+func newQAP(arg string, cfg Config) (Problem, error) {
+	p, err := qap.Load(arg)
+	if err != nil {
+		return nil, err
+	}
+	return qapProblem{p}, nil
+}
+
+func (p qapProblem) Random() evo.Genome {
+	return p.p.Random()
+}
+
+func (p qapProblem) Cross(mom, dad evo.Genome) evo.Genome {
+	gene := make([]int, p.p.N)
+	perm.PMX(gene, mom.(*qap.Assignment).Gene, dad.(*qap.Assignment).Gene)
+	return p.p.New(gene)
+}
+
+func (p qapProblem) Copy(g evo.Genome) evo.Genome {
+	return p.p.New(append([]int(nil), g.(*qap.Assignment).Gene...))
+}
+
+// Mutate swaps two random facilities with probability rate.
+func (p qapProblem) Mutate(g evo.Genome, rate float64) {
+	if rand.Float64() < rate {
+		perm.RandSwap(g.(*qap.Assignment).Gene)
+	}
+}
+
+// jobshop
+// -------------------------
+
+type jobshopProblem struct {
+	inst *jobshop.Instance
+}
+
+func newJobshop(arg string, cfg Config) (Problem, error) {
+	inst, err := jobshop.Load(arg)
+	if err != nil {
+		return nil, err
+	}
+	return jobshopProblem{inst}, nil
+}
+
+func (p jobshopProblem) Random() evo.Genome {
+	return p.inst.Random()
+}
+
+func (p jobshopProblem) Cross(mom, dad evo.Genome) evo.Genome {
+	return jobshop.Breed(mom.(*jobshop.Schedule), dad.(*jobshop.Schedule), 0)
+}
+
+func (p jobshopProblem) Copy(g evo.Genome) evo.Genome {
+	return p.inst.New(append([]int(nil), g.(*jobshop.Schedule).Gene...))
+}
+
+// Mutate moves a random operation with probability rate.
+func (p jobshopProblem) Mutate(g evo.Genome, rate float64) {
+	if rand.Float64() < rate {
+		jobshop.Insert(g.(*jobshop.Schedule).Gene)
+	}
+}
@@ -0,0 +1,240 @@
+package experiment
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/plot"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/pop/graph"
+)
+
+// A Result is the outcome of one repetition of an experiment.
+type Result struct {
+	Repetition  int        `json:"repetition"`
+	Best        float64    `json:"best"`        // the best fitness found
+	Success     bool       `json:"success"`     // whether the target was reached
+	Evaluations int        `json:"evaluations"` // fitness evaluations performed
+	Seconds     float64    `json:"seconds"`     // wall time
+	Solution    evo.Genome `json:"-"`           // the best genome found
+
+	// The convergence of the run, if the configuration enables plotting.
+	Trace []plot.Point `json:"trace,omitempty"`
+}
+
+// A Report is the outcome of an experiment.
+type Report struct {
+	Info    evo.RunInfo `json:"info"`
+	Config  Config      `json:"config"`
+	Results []Result    `json:"results"`
+}
+
+// Run runs the repetitions of an experiment, up to cfg.Parallel at a time.
+// Each repetition counts its own evaluations, so concurrent repetitions do not
+// disturb each other's budgets. If the configuration names an output
+// directory, the report is written there as <name>.json.
+func Run(cfg Config) (*Report, error) {
+	cfg.defaults()
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	problem, err := NewProblem(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	workers := cfg.Parallel
+	if workers == 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+	var (
+		report = &Report{Info: evo.Capture(cfg.Name, cfg), Config: cfg, Results: make([]Result, cfg.Repetitions)}
+		errs   = make([]error, cfg.Repetitions)
+		next   = make(chan int)
+		wg     sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				report.Results[i], errs[i] = RunOnce(cfg, problem)
+				report.Results[i].Repetition = i
+			}
+		}()
+	}
+	for i := 0; i < cfg.Repetitions; i++ {
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if cfg.Output != "" {
+		if err := report.Write(cfg.Output); err != nil {
+			return nil, err
+		}
+	}
+	return report, nil
+}
+
+// Write writes the report to <dir>/<name>.json, creating the directory if
+// needed. The convergence of each repetition with a trace is plotted to
+// <dir>/<name>-<repetition>.svg.
+func (r *Report) Write(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(r, "", "\t")
+	if err != nil {
+		return err
+	}
+	err = os.WriteFile(filepath.Join(dir, r.Config.Name+".json"), b, 0644)
+	if err != nil {
+		return err
+	}
+	for _, res := range r.Results {
+		if len(res.Trace) == 0 {
+			continue
+		}
+		name := fmt.Sprintf("%s-%d.svg", r.Config.Name, res.Repetition)
+		title := fmt.Sprintf("%s (repetition %d)", r.Config.Name, res.Repetition)
+		if err := plot.Save(filepath.Join(dir, name), title, res.Trace); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// RunOnce runs a single repetition of an experiment on the given problem.
+func RunOnce(cfg Config, problem Problem) (Result, error) {
+	var (
+		evals atomic.Int64 // counts the evaluations of this run
+		ops   = cfg.Operators
+		term  = cfg.Termination
+		start = time.Now()
+	)
+
+	// choose runs a tournament among random suitors
+	choose := func(suitors []evo.Genome) evo.Genome {
+		best := suitors[rand.Intn(len(suitors))]
+		for i := 1; i < ops.Tournament; i++ {
+			if g := suitors[rand.Intn(len(suitors))]; best.Fitness() < g.Fitness() {
+				best = g
+			}
+		}
+		return best
+	}
+
+	body := func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		var child evo.Genome
+		mom := choose(suitors)
+		if rand.Float64() < ops.Crossover {
+			child = problem.Cross(mom, choose(suitors))
+		} else {
+			child = problem.Copy(mom)
+		}
+		problem.Mutate(child, ops.Mutation)
+		child.Fitness()
+		evals.Add(1)
+		if ops.Elitist && child.Fitness() < current.Fitness() {
+			return current
+		}
+		return child
+	}
+
+	seed := make([]evo.Genome, cfg.Population.Size)
+	for i := range seed {
+		seed[i] = problem.Random()
+		seed[i].Fitness()
+		evals.Add(1)
+	}
+
+	pop, err := newPopulation(cfg.Population)
+	if err != nil {
+		return Result{}, err
+	}
+	pop.Evolve(seed, body)
+
+	var (
+		success  atomic.Bool
+		recorder *plot.Recorder
+	)
+	if cfg.Plot {
+		recorder = plot.NewRecorder()
+	}
+	pop.Poll(10*time.Millisecond, func() bool {
+		if recorder != nil {
+			recorder.Record(int(evals.Load()), pop.Stats())
+		}
+		switch {
+		case 0 < term.Evaluations && term.Evaluations <= int(evals.Load()):
+			return true
+		case 0 < term.Duration && time.Duration(term.Duration) <= time.Since(start):
+			return true
+		case term.Target != nil && *term.Target <= pop.Fitness():
+			success.Store(true)
+			return true
+		}
+		return false
+	})
+	pop.Wait()
+
+	res := Result{
+		Best:        math.Inf(-1),
+		Success:     success.Load(),
+		Evaluations: int(evals.Load()),
+		Seconds:     time.Since(start).Seconds(),
+	}
+	view := pop.View()
+	for _, g := range view.Members() {
+		if fit := g.Fitness(); res.Best < fit {
+			res.Best = fit
+			res.Solution = g
+		}
+	}
+	if recorder != nil {
+		recorder.Record(res.Evaluations, view.Stats())
+		res.Trace = recorder.Points()
+	}
+	view.Close()
+	if term.Target != nil && *term.Target <= res.Best {
+		res.Success = true
+	}
+	return res, nil
+}
+
+// newPopulation creates a population of the configured type.
+func newPopulation(p Population) (evo.Population, error) {
+	if p.Type == "gen" {
+		return new(gen.Population), nil
+	}
+	switch p.Topology {
+	case "ring":
+		return graph.Ring(p.Size), nil
+	case "hypercube":
+		return graph.Hypercube(p.Size), nil
+	case "complete":
+		return graph.Complete(p.Size), nil
+	case "torus":
+		rows := int(math.Sqrt(float64(p.Size)))
+		if rows*rows != p.Size {
+			return nil, fmt.Errorf("experiment: torus size must be a square")
+		}
+		return graph.Torus(rows, rows), nil
+	}
+	return nil, fmt.Errorf("experiment: unknown topology %q", p.Topology)
+}
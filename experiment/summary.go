@@ -0,0 +1,169 @@
+package experiment
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Summary aggregates the results of the repetitions of an experiment.
+type Summary struct {
+	Runs        int       // the number of repetitions
+	Successes   int       // the number of repetitions reaching the target
+	Best        evo.Stats // the best fitness of each repetition
+	Median      float64   // the median of the best fitness
+	MeanCI      Interval  // the 95% bootstrap interval of the mean best fitness
+	MedianCI    Interval  // the 95% bootstrap interval of the median best fitness
+	Evaluations evo.Stats // the evaluations of each repetition
+	ToTarget    evo.Stats // the evaluations of successful repetitions
+	Seconds     evo.Stats // the wall time of each repetition
+}
+
+// Summary aggregates the results of a report.
+func (r *Report) Summary() (s Summary) {
+	best := make([]float64, len(r.Results))
+	for i, res := range r.Results {
+		best[i] = res.Best
+		s.Best = s.Best.Put(res.Best)
+		s.Evaluations = s.Evaluations.Put(float64(res.Evaluations))
+		s.Seconds = s.Seconds.Put(res.Seconds)
+		if res.Success {
+			s.Successes++
+			s.ToTarget = s.ToTarget.Put(float64(res.Evaluations))
+		}
+	}
+	s.Runs = len(r.Results)
+	s.MeanCI = Bootstrap(best, Mean, 0.95, Resamples)
+	s.MedianCI = Bootstrap(best, Median, 0.95, Resamples)
+	s.Median = median(best)
+	return s
+}
+
+// SuccessRate returns the fraction of repetitions reaching the target.
+func (s Summary) SuccessRate() float64 {
+	if s.Runs == 0 {
+		return 0
+	}
+	return float64(s.Successes) / float64(s.Runs)
+}
+
+// String returns a one line description of the summary.
+func (s Summary) String() string {
+	return fmt.Sprintf("runs=%d success=%.2f best=%g median=%g mean=%g ci=%v sd=%g evals=%.0f time=%.2fs",
+		s.Runs,
+		s.SuccessRate(),
+		s.Best.Max(),
+		s.Median,
+		s.Best.Mean(),
+		s.MeanCI,
+		s.Best.SD(),
+		s.Evaluations.Mean(),
+		s.Seconds.Mean())
+}
+
+// A Comparison is the result of a rank-sum test between the best fitness of
+// the repetitions of two experiments, with effect sizes.
+type Comparison struct {
+	U float64 // the Mann-Whitney U statistic of the first sample
+	Z float64 // the normal approximation of U, positive when the first is better
+	P float64 // the two-sided p-value
+	A float64 // the Vargha-Delaney effect size, above 0.5 when the first is better
+
+	// Set by Compare only.
+	D     float64  // Cohen's d, positive when the first is better
+	Delta Interval // the 95% bootstrap interval of the difference of the means
+}
+
+// String returns a one line description of the comparison.
+func (c Comparison) String() string {
+	return fmt.Sprintf("U=%g z=%.3f p=%.4f A=%.3f d=%.3f delta=%v", c.U, c.Z, c.P, c.A, c.D, c.Delta)
+}
+
+// Compare compares the best fitness of the repetitions of two reports with the
+// Mann-Whitney U test, and measures the size of the difference.
+func Compare(a, b *Report) Comparison {
+	x := make([]float64, len(a.Results))
+	for i := range a.Results {
+		x[i] = a.Results[i].Best
+	}
+	y := make([]float64, len(b.Results))
+	for i := range b.Results {
+		y[i] = b.Results[i].Best
+	}
+	c := MannWhitney(x, y)
+	c.D = CohenD(x, y)
+	c.Delta = BootstrapDiff(x, y, Mean, 0.95, Resamples)
+	return c
+}
+
+// MannWhitney performs the Mann-Whitney U test, also called the Wilcoxon
+// rank-sum test, on two independent samples. The p-value uses the normal
+// approximation with a correction for ties, which is reasonable once both
+// samples have more than a handful of values.
+func MannWhitney(x, y []float64) Comparison {
+	n1, n2 := float64(len(x)), float64(len(y))
+	if n1 == 0 || n2 == 0 {
+		return Comparison{P: 1, A: 0.5}
+	}
+
+	type obs struct {
+		val   float64
+		first bool
+	}
+	all := make([]obs, 0, len(x)+len(y))
+	for _, v := range x {
+		all = append(all, obs{v, true})
+	}
+	for _, v := range y {
+		all = append(all, obs{v, false})
+	}
+	sort.Slice(all, func(i, j int) bool { return all[i].val < all[j].val })
+
+	// sum the ranks of the first sample, giving ties their average rank
+	var r1, ties float64
+	for i := 0; i < len(all); {
+		j := i
+		for j < len(all) && all[j].val == all[i].val {
+			j++
+		}
+		rank := float64(i+j+1) / 2
+		for k := i; k < j; k++ {
+			if all[k].first {
+				r1 += rank
+			}
+		}
+		t := float64(j - i)
+		ties += t*t*t - t
+		i = j
+	}
+
+	n := n1 + n2
+	u := r1 - n1*(n1+1)/2
+	mean := n1 * n2 / 2
+	sd := math.Sqrt(n1 * n2 / 12 * ((n + 1) - ties/(n*(n-1))))
+	if sd == 0 {
+		return Comparison{U: u, P: 1, A: u / (n1 * n2)}
+	}
+	z := (u - mean) / sd
+	return Comparison{
+		U: u,
+		Z: z,
+		P: math.Erfc(math.Abs(z) / math.Sqrt2),
+		A: u / (n1 * n2),
+	}
+}
+
+// median returns the median of the values, sorting them in place.
+func median(vals []float64) float64 {
+	if len(vals) == 0 {
+		return math.NaN()
+	}
+	sort.Float64s(vals)
+	m := len(vals) / 2
+	if len(vals)%2 == 0 {
+		return (vals[m-1] + vals[m]) / 2
+	}
+	return vals[m]
+}
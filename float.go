@@ -0,0 +1,48 @@
+package evo
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// A Float is a float64 which can be written to JSON even when it is not
+// finite. Finite values are written as numbers, and the others, which
+// encoding/json rejects, as the strings "NaN", "+Inf", and "-Inf". Records of
+// fitness and statistics should use Float, since infeasible genomes commonly
+// have a fitness of -Inf, e.g. after sel.Cleared.
+type Float float64
+
+// MarshalJSON writes the value as a number, or as a string if it is not finite.
+func (f Float) MarshalJSON() ([]byte, error) {
+	x := float64(f)
+	switch {
+	case math.IsNaN(x):
+		return []byte(`"NaN"`), nil
+	case math.IsInf(x, 1):
+		return []byte(`"+Inf"`), nil
+	case math.IsInf(x, -1):
+		return []byte(`"-Inf"`), nil
+	}
+	return json.Marshal(x)
+}
+
+// UnmarshalJSON reads the value from a number or from one of the strings
+// written by MarshalJSON.
+func (f *Float) UnmarshalJSON(b []byte) error {
+	if len(b) == 0 || b[0] != '"' {
+		return json.Unmarshal(b, (*float64)(f))
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	switch s {
+	case "NaN", "+Inf", "-Inf":
+		x, _ := strconv.ParseFloat(s, 64)
+		*f = Float(x)
+		return nil
+	}
+	return fmt.Errorf("evo: invalid float %q", s)
+}
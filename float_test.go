@@ -0,0 +1,29 @@
+package evo_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestFloat(t *testing.T) {
+	for _, x := range []float64{0, -1.5, math.Inf(1), math.Inf(-1), math.NaN()} {
+		b, err := json.Marshal(evo.Float(x))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var y evo.Float
+		if err := json.Unmarshal(b, &y); err != nil {
+			t.Fatal(err)
+		}
+		if float64(y) != x && !(math.IsNaN(x) && math.IsNaN(float64(y))) {
+			t.Errorf("%v encoded as %s decoded as %v", x, b, y)
+		}
+	}
+	var y evo.Float
+	if json.Unmarshal([]byte(`"inf"`), &y) == nil {
+		t.Error("invalid string decoded")
+	}
+}
@@ -0,0 +1,12 @@
+// Package ge provides grammatical evolution.
+//
+// Grammatical evolution maps a genome of integer codons to a program in some
+// language described by a user-supplied grammar in Backus-Naur form. This lets
+// structured outputs, like expressions, configuration files, or queries, be
+// evolved with ordinary fixed or variable length integer operators.
+//
+// Genomes are plain []int slices, so the operators of the integer package, like
+// integer.UniformX and integer.PointX, can be used directly. This package adds
+// the codon-level operators specific to grammatical evolution as well as
+// sensible initialization.
+package ge
@@ -0,0 +1,90 @@
+package ge_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/ge"
+)
+
+const bnf = `
+# simple arithmetic
+<expr> ::= <expr><op><expr> | (<expr>) | <var>
+<op>   ::= + | - | "*"
+<var>  ::= x
+         | y
+`
+
+// grammar.go
+// -------------------------
+
+func TestParse(t *testing.T) {
+	g, err := ge.Parse(bnf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if g.Start != "expr" {
+		t.Fail()
+	}
+	if g.Choices("expr") != 3 || g.Choices("op") != 3 || g.Choices("var") != 2 {
+		t.Fail()
+	}
+	if _, err := ge.Parse("<a> ::= <b>"); err == nil {
+		t.Fail()
+	}
+	if _, err := ge.Parse("<a> ::= <a>"); err == nil {
+		t.Fail()
+	}
+}
+
+func TestMap(t *testing.T) {
+	g, _ := ge.Parse(bnf)
+	prog, used, err := g.Map([]int{0, 2, 1, 2, 2, 0}, 0)
+	if err != nil || prog != "y*x" || used != 6 {
+		t.Fail()
+	}
+	_, _, err = g.Map([]int{0, 0}, 2)
+	if err != ge.ErrInvalid {
+		t.Fail()
+	}
+	prog, _, err = g.Map([]int{2, 1}, 1)
+	if err != nil || prog != "y" {
+		t.Fail()
+	}
+}
+
+// ops.go
+// -------------------------
+
+func TestInit(t *testing.T) {
+	g, _ := ge.Parse(bnf)
+	for i := 0; i < 100; i++ {
+		codons := g.Init(2+i%4, i%2 == 0, 4)
+		_, used, err := g.Map(codons, 0)
+		if err != nil || used != len(codons)-4 {
+			t.Fail()
+		}
+	}
+}
+
+func TestEffectiveX(t *testing.T) {
+	mom := []int{1, 1, 1, 1}
+	dad := []int{2, 2, 2, 2, 2, 2}
+	child := ge.EffectiveX(mom, dad, 2, 3)
+	if len(child) < 3 || 8 < len(child) {
+		t.Fail()
+	}
+	for i := 1; i < len(child); i++ {
+		if child[i-1] == 2 && child[i] == 1 {
+			t.Fail()
+		}
+	}
+}
+
+func TestPrune(t *testing.T) {
+	if len(ge.Prune([]int{1, 2, 3}, 2)) != 2 {
+		t.Fail()
+	}
+	if len(ge.Prune([]int{1, 2, 3}, 5)) != 3 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,223 @@
+package ge
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+)
+
+// ErrInvalid is returned when a genome cannot be mapped to a program, i.e. when
+// the codons are exhausted before the derivation is complete.
+var ErrInvalid = errors.New("ge: invalid genome")
+
+// A Grammar is a context-free grammar in Backus-Naur form.
+type Grammar struct {
+	Start string // the start symbol, by default the first rule
+
+	rules map[string][]production
+	depth map[string]int // minimum depth of a derivation from each rule
+}
+
+// A production is one alternative of a rule.
+type production struct {
+	syms  []symbol
+	depth int // minimum depth of a derivation from this production
+}
+
+// A symbol is either a terminal string or a nonterminal rule name.
+type symbol struct {
+	text string
+	term bool
+}
+
+// Parse parses a grammar in Backus-Naur form. Each rule has the form
+//
+//	<name> ::= alternative | alternative | ...
+//
+// A rule may be continued on the following lines by starting them with "|".
+// Within an alternative, names in angle brackets are nonterminals and all other
+// text is terminal. Terminals may be double-quoted to include the characters
+// "|", "<", or leading and trailing whitespace. Blank lines and lines beginning
+// with "#" are ignored.
+func Parse(bnf string) (g *Grammar, err error) {
+	g = &Grammar{rules: make(map[string][]production)}
+	var current string
+	scanner := bufio.NewScanner(strings.NewReader(bnf))
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || text[0] == '#' {
+			continue
+		}
+		if text[0] != '|' {
+			i := strings.Index(text, "::=")
+			if i == -1 {
+				return nil, fmt.Errorf("ge: line %d: expected ::=", line)
+			}
+			name := strings.TrimSpace(text[:i])
+			if len(name) < 3 || name[0] != '<' || name[len(name)-1] != '>' {
+				return nil, fmt.Errorf("ge: line %d: bad rule name %q", line, name)
+			}
+			current = name[1 : len(name)-1]
+			if g.Start == "" {
+				g.Start = current
+			}
+			text = text[i+3:]
+		} else if current == "" {
+			return nil, fmt.Errorf("ge: line %d: continuation without a rule", line)
+		} else {
+			text = text[1:]
+		}
+		alts, err := parseAlts(text)
+		if err != nil {
+			return nil, fmt.Errorf("ge: line %d: %v", line, err)
+		}
+		g.rules[current] = append(g.rules[current], alts...)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if g.Start == "" {
+		return nil, errors.New("ge: empty grammar")
+	}
+	for name, prods := range g.rules {
+		for _, p := range prods {
+			for _, s := range p.syms {
+				if !s.term && g.rules[s.text] == nil {
+					return nil, fmt.Errorf("ge: rule <%s> references undefined <%s>", name, s.text)
+				}
+			}
+		}
+	}
+	g.measure()
+	for name, d := range g.depth {
+		if d == math.MaxInt32 {
+			return nil, fmt.Errorf("ge: rule <%s> never terminates", name)
+		}
+	}
+	return g, nil
+}
+
+// parseAlts parses the alternatives on the right hand side of a rule.
+func parseAlts(text string) (alts []production, err error) {
+	var (
+		syms  []symbol
+		buf   []byte
+		quote bool
+	)
+	flush := func() {
+		if t := strings.TrimSpace(string(buf)); t != "" {
+			syms = append(syms, symbol{t, true})
+		}
+		buf = buf[:0]
+	}
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote && c == '"':
+			syms = append(syms, symbol{string(buf), true})
+			buf = buf[:0]
+			quote = false
+		case quote:
+			buf = append(buf, c)
+		case c == '"':
+			flush()
+			quote = true
+		case c == '<':
+			flush()
+			j := strings.IndexByte(text[i:], '>')
+			if j == -1 {
+				return nil, errors.New("unterminated nonterminal")
+			}
+			syms = append(syms, symbol{text[i+1 : i+j], false})
+			i += j
+		case c == '|':
+			flush()
+			alts = append(alts, production{syms: syms})
+			syms = nil
+		default:
+			buf = append(buf, c)
+		}
+	}
+	if quote {
+		return nil, errors.New("unterminated quote")
+	}
+	flush()
+	alts = append(alts, production{syms: syms})
+	return alts, nil
+}
+
+// measure computes the minimum derivation depth of every rule and production.
+// Rules which can never terminate are given a depth of math.MaxInt32.
+func (g *Grammar) measure() {
+	g.depth = make(map[string]int, len(g.rules))
+	for name := range g.rules {
+		g.depth[name] = math.MaxInt32
+	}
+	for changed := true; changed; {
+		changed = false
+		for name, prods := range g.rules {
+			for i := range prods {
+				d := 1
+				for _, s := range prods[i].syms {
+					if !s.term && g.depth[s.text]+1 > d {
+						d = g.depth[s.text] + 1
+					}
+				}
+				if d > math.MaxInt32 {
+					d = math.MaxInt32
+				}
+				prods[i].depth = d
+				if d < g.depth[name] {
+					g.depth[name] = d
+					changed = true
+				}
+			}
+		}
+	}
+}
+
+// Choices returns the number of alternatives of a rule.
+func (g *Grammar) Choices(rule string) int {
+	return len(g.rules[rule])
+}
+
+// Map performs the genotype to phenotype mapping. The derivation starts from
+// the start symbol and always expands the leftmost nonterminal. When a rule has
+// more than one alternative, the next codon modulo the number of alternatives
+// chooses the production; rules with a single alternative consume no codons.
+// When the codons run out, reading wraps around to the beginning of the genome
+// at most wraps times before the genome is considered invalid.
+//
+// The number of codons used by the mapping is also returned. Codons beyond
+// this point are introns; see Prune.
+func (g *Grammar) Map(codons []int, wraps int) (prog string, used int, err error) {
+	var (
+		out   strings.Builder
+		stack = []symbol{{g.Start, false}}
+		limit = len(codons) * (wraps + 1)
+	)
+	for len(stack) > 0 {
+		s := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if s.term {
+			out.WriteString(s.text)
+			continue
+		}
+		prods := g.rules[s.text]
+		choice := 0
+		if len(prods) > 1 {
+			if used >= limit {
+				return "", used, ErrInvalid
+			}
+			choice = codons[used%len(codons)] % len(prods)
+			used++
+		}
+		syms := prods[choice].syms
+		for i := len(syms) - 1; 0 <= i; i-- {
+			stack = append(stack, syms[i])
+		}
+	}
+	return out.String(), used, nil
+}
@@ -0,0 +1,123 @@
+package ge
+
+import (
+	"math/rand"
+)
+
+// CodonMax bounds the values of codons produced by this package. Codons are
+// taken from [0,CodonMax).
+var CodonMax = 256
+
+// Init creates a genome with sensible initialization. A random derivation tree
+// is grown from the start symbol no deeper than the given depth, and the codons
+// which produce that derivation are recorded. When full is true, productions
+// which can reach the maximum depth are preferred, as in the "full" method of
+// tree-based GP; otherwise any production that fits is chosen, as in the
+// "grow" method. Alternating between the two over a range of depths gives
+// ramped half-and-half initialization.
+//
+// A random tail of the given length is appended to the genome to act as
+// genetic material for crossover and wrapping.
+//
+// If the grammar cannot derive a program within the depth, the depth is
+// increased to the minimum required.
+func (g *Grammar) Init(depth int, full bool, tail int) (codons []int) {
+	if min := g.depth[g.Start]; depth < min {
+		depth = min
+	}
+	var grow func(rule string, depth int)
+	grow = func(rule string, depth int) {
+		prods := g.rules[rule]
+
+		// candidates are productions that fit within the depth
+		// in full mode, we prefer the recursive ones
+		var fits, deep []int
+		for i := range prods {
+			if prods[i].depth <= depth {
+				fits = append(fits, i)
+				if g.recursive(prods[i]) {
+					deep = append(deep, i)
+				}
+			}
+		}
+		if full && len(deep) > 0 {
+			fits = deep
+		}
+		choice := fits[rand.Intn(len(fits))]
+
+		if k := len(prods); k > 1 {
+			codons = append(codons, choice+k*rand.Intn(max(CodonMax/k, 1)))
+		}
+		for _, s := range prods[choice].syms {
+			if !s.term {
+				grow(s.text, depth-1)
+			}
+		}
+	}
+	grow(g.Start, depth)
+	for i := 0; i < tail; i++ {
+		codons = append(codons, rand.Intn(CodonMax))
+	}
+	return codons
+}
+
+// recursive returns true if the production contains a nonterminal that can
+// derive more than a single terminal level.
+func (g *Grammar) recursive(p production) bool {
+	for _, s := range p.syms {
+		if !s.term && len(g.rules[s.text]) > 1 {
+			return true
+		}
+	}
+	return false
+}
+
+// Random returns a genome of n random codons.
+func Random(n int) []int {
+	codons := make([]int, n)
+	for i := range codons {
+		codons[i] = rand.Intn(CodonMax)
+	}
+	return codons
+}
+
+// Mutate replaces each codon with a random value with the given probability.
+func Mutate(codons []int, rate float64) {
+	for i := range codons {
+		if rand.Float64() < rate {
+			codons[i] = rand.Intn(CodonMax)
+		}
+	}
+}
+
+// Prune removes the unused tail of a genome, given the number of codons used by
+// the mapping. Genomes which wrapped are left unchanged.
+func Prune(codons []int, used int) []int {
+	if used < len(codons) {
+		return codons[:used]
+	}
+	return codons
+}
+
+// Duplicate copies a random run of codons onto the end of the genome.
+func Duplicate(codons []int) []int {
+	if len(codons) == 0 {
+		return codons
+	}
+	i := rand.Intn(len(codons))
+	j := i + 1 + rand.Intn(len(codons)-i)
+	return append(codons, codons[i:j]...)
+}
+
+// EffectiveX performs a one-point crossover of variable length genomes where the
+// cut points are chosen within the effective (used) region of each parent. The
+// child takes the head of mom and the tail of dad. Restricting the cut to the
+// effective region avoids crossover events which only exchange introns.
+func EffectiveX(mom, dad []int, momUsed, dadUsed int) (child []int) {
+	i := rand.Intn(min(momUsed, len(mom)) + 1)
+	j := rand.Intn(min(dadUsed, len(dad)) + 1)
+	child = make([]int, 0, i+len(dad)-j)
+	child = append(child, mom[:i]...)
+	child = append(child, dad[j:]...)
+	return child
+}
@@ -1,26 +1,143 @@
 package integer
 
-import "math/rand"
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+)
 
-// UniformX performs a uniform crossover of some parents into a child.
+// A Crossover recombines two parents into a child of the same length. The
+// constructors of this package validate their parameters up front, so a
+// Crossover can be configured once and applied to every pair of parents.
+type Crossover func(child, mom, dad []int)
+
+// Uniform is the uniform crossover of two parents, as by UniformX.
+var Uniform Crossover = func(child, mom, dad []int) {
+	UniformX(child, mom, dad)
+}
+
+// KPoint returns the k-point crossover, as by PointX. It panics unless k is
+// positive; the crossover panics if k is not less than the length of the child.
+func KPoint(k int) Crossover {
+	if k < 1 {
+		panic("integer: the number of crossover points must be positive")
+	}
+	return func(child, mom, dad []int) {
+		PointX(k, child, mom, dad)
+	}
+}
+
+// Shuffle returns the k-point shuffle crossover, as by ShuffleX. It panics
+// unless k is positive; the crossover panics if k is not less than the length
+// of the child.
+func Shuffle(k int) Crossover {
+	if k < 1 {
+		panic("integer: the number of crossover points must be positive")
+	}
+	return func(child, mom, dad []int) {
+		ShuffleX(k, child, mom, dad)
+	}
+}
+
+// Blend returns the blend crossover for values in [lo,hi), as by BlendX. It
+// panics unless alpha is non-negative and lo is less than hi.
+func Blend(alpha float64, lo, hi int) Crossover {
+	checkBlend(alpha, lo, hi)
+	return func(child, mom, dad []int) {
+		BlendX(alpha, lo, hi, child, mom, dad)
+	}
+}
+
+// UniformX performs a uniform crossover of some parents into a child. It panics
+// without parents.
 func UniformX(child []int, parents ...[]int) {
 	n := len(parents)
+	if n == 0 {
+		panic("integer: crossover without parents")
+	}
 	for i := range child {
 		child[i] = parents[rand.Intn(n)][i]
 	}
 }
 
-// PointX performs n-point crossover of two parents into a child.
+// PointX performs n-point crossover of two parents into a child. The n cut
+// points are distinct and chosen uniformly between the values of the child,
+// and the segments between them alternate between the parents, starting with
+// either. It panics if n is negative or not less than len(child), except that
+// 0 points copy a parent whatever the length.
 func PointX(n int, child, mom, dad []int) {
+	checkPoints(n, len(child))
 	if rand.Intn(2) == 0 {
 		mom, dad = dad, mom
 	}
-	for 0 < n {
-		i := rand.Intn(len(child)-n) + 1
-		copy(child, mom[:i])
-		child = child[i:]
-		mom, dad = dad[i:], mom[i:]
-		n--
+	var cuts []int
+	if 0 < n {
+		cuts = rand.Perm(len(child) - 1)[:n]
+		sort.Ints(cuts)
+	}
+	start := 0
+	for _, cut := range cuts {
+		end := cut + 1
+		copy(child[start:end], mom[start:end])
+		mom, dad = dad, mom
+		start = end
+	}
+	copy(child[start:], mom[start:])
+}
+
+// ShuffleX performs shuffle crossover of two parents into a child: the loci are
+// shuffled by a random permutation, the shuffled parents are recombined by
+// n-point crossover, and the child is unshuffled. Shuffling removes the
+// positional bias of PointX, where nearby values tend to be inherited together.
+// It panics if n is invalid for PointX.
+func ShuffleX(n int, child, mom, dad []int) {
+	checkPoints(n, len(child))
+	order := rand.Perm(len(child))
+	smom := make([]int, len(child))
+	sdad := make([]int, len(child))
+	for i, j := range order {
+		smom[i], sdad[i] = mom[j], dad[j]
+	}
+	schild := make([]int, len(child))
+	PointX(n, schild, smom, sdad)
+	for i, j := range order {
+		child[j] = schild[i]
+	}
+}
+
+// BlendX performs blend crossover (BLX-α) of two parents into a child with
+// values in [lo,hi). Each value of the child is drawn uniformly from the
+// interval spanned by the values of the parents, extended on both sides by
+// alpha times its length, rounded to the nearest integer, and clamped to the
+// bounds. An alpha of 0.5 is common. It panics unless alpha is non-negative and
+// lo is less than hi.
+func BlendX(alpha float64, lo, hi int, child, mom, dad []int) {
+	checkBlend(alpha, lo, hi)
+	for i := range child {
+		a, b := float64(mom[i]), float64(dad[i])
+		if b < a {
+			a, b = b, a
+		}
+		d := alpha * (b - a)
+		x := math.Round(a - d + rand.Float64()*(b-a+2*d))
+		child[i] = min(max(int(x), lo), hi-1)
+	}
+}
+
+// checkPoints panics unless n cut points are valid for size values.
+func checkPoints(n, size int) {
+	if n < 0 || 0 < n && size <= n {
+		panic(fmt.Sprintf("integer: %d crossover points for %d values", n, size))
+	}
+}
+
+// checkBlend panics unless the parameters of BlendX are valid.
+func checkBlend(alpha float64, lo, hi int) {
+	if alpha < 0 || math.IsNaN(alpha) {
+		panic("integer: blend crossover with negative alpha")
+	}
+	if hi <= lo {
+		panic(fmt.Sprintf("integer: blend crossover with empty bounds [%d,%d)", lo, hi))
 	}
-	copy(child, mom)
 }
@@ -44,3 +44,119 @@ func TestPointX(t *testing.T) {
 		}
 	}
 }
+
+func TestPointXSwitches(t *testing.T) {
+	mom := []int{1, 1, 1, 1, 1, 1, 1, 1}
+	dad := []int{2, 2, 2, 2, 2, 2, 2, 2}
+	child := make([]int, 8)
+	for k := 0; k < 8; k++ {
+		integer.PointX(k, child, mom, dad)
+		switches := 0
+		for i := 1; i < len(child); i++ {
+			if child[i] != child[i-1] {
+				switches++
+			}
+		}
+		if switches != k {
+			t.Errorf("%d-point crossover switched parents %d times: %v", k, switches, child)
+		}
+	}
+}
+
+func TestPointXInvalid(t *testing.T) {
+	for _, k := range []int{-1, 8, 9} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("%d points did not panic", k)
+				}
+			}()
+			integer.PointX(k, make([]int, 8), make([]int, 8), make([]int, 8))
+		}()
+	}
+}
+
+func TestShuffleX(t *testing.T) {
+	mom := []int{1, 1, 1, 1, 1, 1, 1, 1}
+	dad := []int{2, 2, 2, 2, 2, 2, 2, 2}
+	child := make([]int, 8)
+	integer.Shuffle(3)(child, mom, dad)
+	ones := 0
+	for i := range child {
+		if child[i] != mom[i] && child[i] != dad[i] {
+			t.Fatal(child)
+		}
+		if child[i] == 1 {
+			ones++
+		}
+	}
+	if ones == 0 || ones == 8 {
+		t.Errorf("child inherited from a single parent: %v", child)
+	}
+}
+
+func TestBlendX(t *testing.T) {
+	mom := []int{0, 5, 9}
+	dad := []int{9, 5, 9}
+	child := make([]int, 3)
+	for n := 0; n < 100; n++ {
+		integer.Blend(0.5, 0, 10)(child, mom, dad)
+		if child[0] < 0 || 9 < child[0] || child[1] != 5 || child[2] != 9 {
+			t.Fatal(child)
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("empty bounds did not panic")
+		}
+	}()
+	integer.Blend(0.5, 3, 3)
+}
+
+// integer.go
+// -------------------------
+
+func TestRandom(t *testing.T) {
+	v := integer.Random(100, 3)
+	for i := range v {
+		if v[i] < 0 || 3 <= v[i] {
+			t.Fail()
+		}
+	}
+}
+
+// mutation.go
+// -------------------------
+
+func TestRandReset(t *testing.T) {
+	gene := make([]int, 100)
+	integer.RandReset(gene, 1, 1)
+	integer.RandReset(gene, 5, 0)
+	for i := range gene {
+		if gene[i] != 0 {
+			t.Fail()
+		}
+	}
+}
+
+func TestAdaptReset(t *testing.T) {
+	gene := make([]int, 100)
+	rate := 0.1
+	for i := 0; i < 100; i++ {
+		rate = integer.AdaptReset(gene, 3, rate)
+		if rate < 1e-4 || 0.5 < rate {
+			t.Fatalf("rate %v out of bounds", rate)
+		}
+	}
+	changed := false
+	for i := range gene {
+		if gene[i] < 0 || 3 <= gene[i] {
+			t.Fatal("value out of range")
+		}
+		changed = changed || gene[i] != 0
+	}
+	if !changed {
+		t.Error("gene not mutated")
+	}
+}
@@ -0,0 +1,12 @@
+package integer
+
+import "math/rand"
+
+// Random returns a random vector of length n with values uniform in [0,max).
+func Random(n, max int) []int {
+	v := make([]int, n)
+	for i := range v {
+		v[i] = rand.Intn(max)
+	}
+	return v
+}
@@ -0,0 +1,33 @@
+package integer
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cbarrick/evo/real"
+)
+
+// RandReset resets each value of the gene with the given probability to a value
+// uniform in [0,max).
+func RandReset(gene []int, max int, rate float64) {
+	for i := range gene {
+		if rand.Float64() < rate {
+			gene[i] = rand.Intn(max)
+		}
+	}
+}
+
+// AdaptReset self-adapts the mutation rate of the gene, then resets each value
+// of the gene with the new rate to a value uniform in [0,max). It returns the
+// new rate, which should be stored with the gene. The rate is adapted like
+// binary.AdaptRate: by a logistic-normal scaling with a learning rate of
+// 1/sqrt(n), kept within [1/n², 1/2] for genes of length n.
+func AdaptReset(gene []int, max int, rate float64) float64 {
+	n := float64(len(gene))
+	lo, hi := 1/(n*n), 0.5
+	rate = math.Min(math.Max(rate, lo), hi)
+	rate = real.AdaptRate(rate, 1/math.Sqrt(n))
+	rate = math.Min(math.Max(rate, lo), hi)
+	RandReset(gene, max, rate)
+	return rate
+}
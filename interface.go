@@ -50,4 +50,7 @@ type Population interface {
 
 	// Stats returns various statistics about the population.
 	Stats() Stats
+
+	// View returns a snapshot of the members of the population.
+	View() View
 }
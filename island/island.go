@@ -0,0 +1,57 @@
+// Package island provides a convenience constructor for island models.
+//
+// In the island model, the population is divided between some number of
+// sub-populations, called islands, which evolve independently and in parallel.
+// Occasionally, individuals migrate between neighboring islands to serve as
+// sources of new genes. Island models are built by composing populations: the
+// islands are themselves genomes of a graph population which evolves them with
+// a migration function. This package wires up that composition.
+package island
+
+import (
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/graph"
+)
+
+// A Factory returns a new, not yet evolving, population for an island.
+type Factory func() evo.Population
+
+// A Topology returns a graph population of the given size to link the islands,
+// e.g. graph.Ring or graph.Hypercube.
+type Topology func(size int) graph.Graph
+
+// New builds and starts an island model. The seed is divided as evenly as
+// possible among n islands, each created by the factory and evolved with the
+// body. The islands are linked by the topology and evolved with the migration
+// function, e.g. one returned by gen.Migrate. The returned graph population of
+// islands is already evolving; stopping it stops the islands. The statistics of
+// the returned population describe the best member of each island; use
+// View().Subviews() for per-island statistics, or View().Flatten() for
+// statistics over every individual.
+//
+// For example, four generational islands on a ring exchanging five random
+// members every second:
+//
+//	pop := island.New(seed, 4,
+//		func() evo.Population { return new(gen.Population) },
+//		graph.Ring,
+//		gen.Migrate(5, 1*time.Second),
+//		body)
+//	pop.Wait()
+func New(seed []evo.Genome, n int, factory Factory, topology Topology, migrate, body evo.EvolveFn) graph.Graph {
+	islands := make([]evo.Genome, n)
+	size, extra := len(seed)/n, len(seed)%n
+	for i, start := 0, 0; i < n; i++ {
+		end := start + size
+		if i < extra {
+			end++
+		}
+		isl := factory()
+		isl.Evolve(seed[start:end], body)
+		islands[i] = isl
+		start = end
+	}
+	pop := topology(n)
+	pop.Evolve(islands, migrate)
+	return pop
+}
@@ -0,0 +1,111 @@
+package island_test
+
+import (
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/island"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/pop/graph"
+)
+
+// home is a genome whose fitness is the index of the island it was seeded on.
+type home int
+
+func (h home) Fitness() float64 { return float64(h) }
+
+// island.go
+// -------------------------
+
+func TestNew(t *testing.T) {
+	const n = 4
+	seed := []evo.Genome{home(0), home(0), home(0), home(1), home(1), home(1), home(2), home(2), home(3), home(3)}
+
+	// each island sends a copy of a member to the next island of the ring
+	var (
+		mu    sync.Mutex
+		links = make(map[evo.Genome][]evo.Genome)
+		clock = evo.NewFakeClock(time.Now())
+		send  = gen.MigrateWith(gen.Policy{N: 1, Delay: time.Hour, Clock: clock, Copy: true, Directed: true})
+	)
+	migrate := func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		mu.Lock()
+		links[current] = suitors
+		mu.Unlock()
+		return send(current, suitors)
+	}
+
+	// the islands keep their members, so that migrants stay
+	stable := func() evo.Population {
+		isl := new(gen.Population)
+		isl.SetReplacement(func(members, offspring []evo.Genome) {})
+		return isl
+	}
+	pop := island.New(seed, n, stable, graph.Ring, migrate, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	defer pop.Stop()
+
+	if pop.Len() != n {
+		t.Fatalf("%d islands, want %d", pop.Len(), n)
+	}
+	islands := make(map[evo.Genome]int, n)
+	for i := 0; i < n; i++ {
+		islands[pop.Get(i)] = i
+	}
+
+	// the seed is divided evenly, in order
+	clock.BlockUntil(n)
+	for i, want := range [][]float64{{0, 0, 0}, {1, 1, 1}, {2, 2}, {3, 3}} {
+		if got := members(pop.Get(i).(*gen.Population)); !reflect.DeepEqual(got, want) {
+			t.Errorf("island %d seeded with %v, want %v", i, got, want)
+		}
+	}
+
+	// the islands are linked by the topology
+	mu.Lock()
+	for i := 0; i < n; i++ {
+		var got []int
+		for _, s := range links[pop.Get(i)] {
+			got = append(got, islands[s])
+		}
+		if want := []int{(i + 1) % n, (i + n - 1) % n}; !reflect.DeepEqual(got, want) {
+			t.Errorf("island %d linked to %v, want %v", i, got, want)
+		}
+	}
+	mu.Unlock()
+
+	// once the delay passes, every island sends a migrant, though migrants
+	// may be passed on around the ring, so that some island may end up with
+	// only its own genomes
+	clock.Advance(time.Hour)
+	clock.BlockUntil(n)
+	var migrants int
+	for i := 0; i < n; i++ {
+		if pop.Iterations(i) != 1 {
+			t.Errorf("island %d migrated %d times, want once", i, pop.Iterations(i))
+		}
+		for _, f := range members(pop.Get(i).(*gen.Population)) {
+			if f != float64(i) {
+				migrants++
+			}
+		}
+	}
+	if migrants == 0 {
+		t.Error("no migrants")
+	}
+}
+
+// members returns the sorted fitnesses of the members of an island.
+func members(isl *gen.Population) []float64 {
+	fs := make([]float64, isl.Len())
+	for i := range fs {
+		fs[i] = isl.Get(i).Fitness()
+	}
+	sort.Float64s(fs)
+	return fs
+}
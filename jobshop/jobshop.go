@@ -0,0 +1,222 @@
+// Package jobshop provides helpers for the job-shop scheduling problem.
+//
+// A job-shop instance consists of jobs, each a sequence of operations which
+// must be processed in order, each on a given machine for a given time. A
+// machine processes one operation at a time. The problem is to schedule the
+// operations so as to minimize the makespan, the time at which the last job
+// completes.
+//
+// Schedules are represented with the operation-based encoding: a permutation
+// with repetition in which each job appears once per operation. The k-th
+// occurrence of a job refers to its k-th operation, so every sequence decodes
+// to a feasible schedule. Decoding schedules each operation at the earliest
+// time allowed by its job and machine, giving a semi-active schedule.
+package jobshop
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"strings"
+
+	"github.com/cbarrick/evo"
+)
+
+// An Op is an operation of a job.
+type Op struct {
+	Machine int
+	Time    int
+}
+
+// An Instance is a job-shop scheduling problem.
+type Instance struct {
+	Name     string
+	Machines int
+	Jobs     [][]Op // the operations of each job, in order
+}
+
+// Load reads an instance from a file.
+func Load(path string) (*Instance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	inst, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	inst.Name = path
+	return inst, nil
+}
+
+// Parse reads an instance in the standard format used by the OR-Library and
+// Taillard: the number of jobs and machines, followed by one line per job
+// listing the machine and processing time of each operation. Machines are
+// numbered from 0. Lines beginning with # are ignored.
+func Parse(r io.Reader) (*Instance, error) {
+	var (
+		inst    = new(Instance)
+		lines   []string
+		scanner = bufio.NewScanner(r)
+		jobs    int
+	)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	rd := strings.NewReader(strings.Join(lines, "\n"))
+	if _, err := fmt.Fscan(rd, &jobs, &inst.Machines); err != nil {
+		return nil, fmt.Errorf("jobshop: bad header: %v", err)
+	}
+	inst.Jobs = make([][]Op, jobs)
+	for j := range inst.Jobs {
+		inst.Jobs[j] = make([]Op, inst.Machines)
+		for k := range inst.Jobs[j] {
+			op := &inst.Jobs[j][k]
+			if _, err := fmt.Fscan(rd, &op.Machine, &op.Time); err != nil {
+				return nil, fmt.Errorf("jobshop: bad operation %d of job %d: %v", k, j, err)
+			}
+			if op.Machine < 0 || inst.Machines <= op.Machine {
+				return nil, fmt.Errorf("jobshop: bad machine %d", op.Machine)
+			}
+		}
+	}
+	return inst, nil
+}
+
+// Len returns the total number of operations.
+func (inst *Instance) Len() (n int) {
+	for _, job := range inst.Jobs {
+		n += len(job)
+	}
+	return n
+}
+
+// Sequence returns a random operation-based sequence.
+func (inst *Instance) Sequence() []int {
+	seq := make([]int, 0, inst.Len())
+	for j, job := range inst.Jobs {
+		for range job {
+			seq = append(seq, j)
+		}
+	}
+	rand.Shuffle(len(seq), func(i, j int) { seq[i], seq[j] = seq[j], seq[i] })
+	return seq
+}
+
+// Decode returns the semi-active schedule of an operation-based sequence.
+// start[j][k] is the start time of the k-th operation of job j.
+func (inst *Instance) Decode(seq []int) (start [][]int, makespan int) {
+	var (
+		next     = make([]int, len(inst.Jobs)) // the next operation of each job
+		jobFree  = make([]int, len(inst.Jobs)) // when each job is ready
+		machFree = make([]int, inst.Machines)  // when each machine is ready
+	)
+	start = make([][]int, len(inst.Jobs))
+	for j := range start {
+		start[j] = make([]int, len(inst.Jobs[j]))
+	}
+	for _, j := range seq {
+		k := next[j]
+		next[j]++
+		op := inst.Jobs[j][k]
+		t := max(jobFree[j], machFree[op.Machine])
+		start[j][k] = t
+		jobFree[j] = t + op.Time
+		machFree[op.Machine] = t + op.Time
+		makespan = max(makespan, t+op.Time)
+	}
+	return start, makespan
+}
+
+// Makespan returns the makespan of an operation-based sequence.
+func (inst *Instance) Makespan(seq []int) int {
+	_, makespan := inst.Decode(seq)
+	return makespan
+}
+
+// PPX performs precedence preserving crossover of operation-based sequences.
+// The child is built by repeatedly taking the leftmost remaining operation of a
+// randomly chosen parent and deleting the same operation from both parents.
+// The relative order of the operations of each parent is preserved.
+func PPX(child, mom, dad []int) {
+	a := append([]int(nil), mom...)
+	b := append([]int(nil), dad...)
+	for i := range child {
+		var j int
+		if rand.Intn(2) == 0 {
+			j = a[0]
+		} else {
+			j = b[0]
+		}
+		child[i] = j
+		a = remove(a, j)
+		b = remove(b, j)
+	}
+}
+
+// remove deletes the first occurrence of j from the sequence.
+func remove(seq []int, j int) []int {
+	for i := range seq {
+		if seq[i] == j {
+			return append(seq[:i], seq[i+1:]...)
+		}
+	}
+	return seq
+}
+
+// Insert moves a random operation to a random position.
+func Insert(seq []int) {
+	i, j := rand.Intn(len(seq)), rand.Intn(len(seq))
+	x := seq[i]
+	if i < j {
+		copy(seq[i:j], seq[i+1:j+1])
+	} else {
+		copy(seq[j+1:i+1], seq[j:i])
+	}
+	seq[j] = x
+}
+
+// A Schedule is a genome for a job-shop instance. The fitness is the negative
+// makespan, computed once.
+type Schedule struct {
+	evo.Cache
+	Gene     []int
+	Instance *Instance
+}
+
+// New returns a genome for the given operation-based sequence.
+func (inst *Instance) New(gene []int) *Schedule {
+	return &Schedule{Gene: gene, Instance: inst}
+}
+
+// Random returns a genome for a random sequence.
+func (inst *Instance) Random() *Schedule {
+	return inst.New(inst.Sequence())
+}
+
+// Fitness returns the negative makespan of the schedule.
+func (s *Schedule) Fitness() float64 {
+	return s.Cache.Fitness(func() float64 {
+		return -float64(s.Instance.Makespan(s.Gene))
+	})
+}
+
+// Breed returns a child of two schedules by precedence preserving crossover
+// followed by an insertion mutation with the given probability.
+func Breed(mom, dad *Schedule, mutation float64) *Schedule {
+	gene := make([]int, len(mom.Gene))
+	PPX(gene, mom.Gene, dad.Gene)
+	if rand.Float64() < mutation {
+		Insert(gene)
+	}
+	return mom.Instance.New(gene)
+}
@@ -0,0 +1,64 @@
+package jobshop_test
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo/jobshop"
+)
+
+// two jobs on two machines, in opposite orders
+const instance = `# a tiny instance
+2 2
+0 3 1 2
+1 4 0 1
+`
+
+func parse(t *testing.T) *jobshop.Instance {
+	inst, err := jobshop.Parse(strings.NewReader(instance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return inst
+}
+
+// valid fails the test if seq is not an operation-based sequence of inst.
+func valid(t *testing.T, inst *jobshop.Instance, seq []int) {
+	s := append([]int(nil), seq...)
+	sort.Ints(s)
+	for i := range s {
+		if s[i] != i/inst.Machines {
+			t.Fatalf("invalid sequence %v", seq)
+		}
+	}
+}
+
+func TestDecode(t *testing.T) {
+	inst := parse(t)
+	if inst.Len() != 4 || inst.Jobs[1][0] != (jobshop.Op{Machine: 1, Time: 4}) {
+		t.Fail()
+	}
+	start, makespan := inst.Decode([]int{0, 1, 0, 1})
+	if start[0][1] != 4 || start[1][1] != 4 || makespan != 6 {
+		t.Fail()
+	}
+	if inst.Makespan([]int{0, 0, 1, 1}) != 10 {
+		t.Fail()
+	}
+	if _, err := jobshop.Parse(strings.NewReader("1 1\n2 3\n")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestOperators(t *testing.T) {
+	inst := parse(t)
+	for i := 0; i < 100; i++ {
+		mom, dad := inst.Random(), inst.Random()
+		child := jobshop.Breed(mom, dad, 1)
+		valid(t, inst, child.Gene)
+		if child.Fitness() > -6 {
+			t.Fail()
+		}
+	}
+}
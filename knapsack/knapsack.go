@@ -0,0 +1,285 @@
+// Package knapsack provides the 0/1 knapsack problem as a benchmark for binary
+// representations.
+//
+// Given a set of items, each with a weight and a profit, the problem is to
+// choose the subset of items with the greatest total profit whose total weight
+// does not exceed the capacity of the knapsack. Solutions are represented as
+// bit strings, where the i-th bit chooses the i-th item.
+//
+// Infeasible solutions, which exceed the capacity, are handled either by a
+// penalty proportional to the excess weight or by a greedy repair. Several
+// standard instances are bundled, and larger random instances can be
+// generated. Instances can be solved exactly by dynamic programming to check
+// the results of an evolutionary search.
+package knapsack
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"sort"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/binary"
+)
+
+// An Instance is a 0/1 knapsack problem.
+type Instance struct {
+	Name     string
+	Capacity int
+	Weights  []int
+	Profits  []int
+	Optimum  int // the optimal profit, or 0 if unknown
+}
+
+// The bundled instances, from the collection of John Burkardt:
+// https://people.sc.fsu.edu/~jburkardt/datasets/knapsack_01/knapsack_01.html
+var (
+	P01 = &Instance{
+		Name:     "p01",
+		Capacity: 165,
+		Weights:  []int{23, 31, 29, 44, 53, 38, 63, 85, 89, 82},
+		Profits:  []int{92, 57, 49, 68, 60, 43, 67, 84, 87, 72},
+		Optimum:  309,
+	}
+	P02 = &Instance{
+		Name:     "p02",
+		Capacity: 26,
+		Weights:  []int{12, 7, 11, 8, 9},
+		Profits:  []int{24, 13, 23, 15, 16},
+		Optimum:  51,
+	}
+	P03 = &Instance{
+		Name:     "p03",
+		Capacity: 190,
+		Weights:  []int{56, 59, 80, 64, 75, 17},
+		Profits:  []int{50, 50, 64, 46, 50, 5},
+		Optimum:  150,
+	}
+	P04 = &Instance{
+		Name:     "p04",
+		Capacity: 50,
+		Weights:  []int{31, 10, 20, 19, 4, 3, 6},
+		Profits:  []int{70, 20, 39, 37, 7, 5, 10},
+		Optimum:  107,
+	}
+	P05 = &Instance{
+		Name:     "p05",
+		Capacity: 104,
+		Weights:  []int{25, 35, 45, 5, 25, 3, 2, 2},
+		Profits:  []int{350, 400, 450, 20, 70, 8, 5, 5},
+		Optimum:  900,
+	}
+)
+
+// Instances lists the bundled instances.
+var Instances = []*Instance{P01, P02, P03, P04, P05}
+
+// Load reads an instance from a file.
+func Load(path string) (*Instance, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	inst, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	inst.Name = path
+	return inst, nil
+}
+
+// Parse reads an instance in the common plain text format. The first line gives
+// the number of items n and the capacity. Each of the next n lines gives the
+// profit and the weight of an item.
+func Parse(r io.Reader) (*Instance, error) {
+	var (
+		inst = new(Instance)
+		rd   = bufio.NewReader(r)
+		n    int
+	)
+	if _, err := fmt.Fscan(rd, &n, &inst.Capacity); err != nil {
+		return nil, fmt.Errorf("knapsack: bad header: %v", err)
+	}
+	inst.Weights = make([]int, n)
+	inst.Profits = make([]int, n)
+	for i := 0; i < n; i++ {
+		if _, err := fmt.Fscan(rd, &inst.Profits[i], &inst.Weights[i]); err != nil {
+			return nil, fmt.Errorf("knapsack: bad item %d: %v", i+1, err)
+		}
+	}
+	return inst, nil
+}
+
+// Random generates an uncorrelated instance of n items with weights and profits
+// uniform in [1,max]. The capacity is half of the total weight.
+func Random(n, max int) *Instance {
+	inst := &Instance{
+		Name:    fmt.Sprintf("random%d", n),
+		Weights: make([]int, n),
+		Profits: make([]int, n),
+	}
+	for i := 0; i < n; i++ {
+		inst.Weights[i] = 1 + rand.Intn(max)
+		inst.Profits[i] = 1 + rand.Intn(max)
+		inst.Capacity += inst.Weights[i]
+	}
+	inst.Capacity /= 2
+	return inst
+}
+
+// Len returns the number of items.
+func (inst *Instance) Len() int {
+	return len(inst.Weights)
+}
+
+// Eval returns the total profit and weight of the chosen items.
+func (inst *Instance) Eval(x []bool) (profit, weight int) {
+	for i := range x {
+		if x[i] {
+			profit += inst.Profits[i]
+			weight += inst.Weights[i]
+		}
+	}
+	return profit, weight
+}
+
+// Feasible returns true if the chosen items fit in the knapsack.
+func (inst *Instance) Feasible(x []bool) bool {
+	_, weight := inst.Eval(x)
+	return weight <= inst.Capacity
+}
+
+// Solve returns an optimal solution and its profit by dynamic programming. It
+// takes time and memory proportional to the number of items times the
+// capacity.
+func (inst *Instance) Solve() (x []bool, profit int) {
+	n, c := inst.Len(), inst.Capacity
+	best := make([][]int, n+1)
+	for i := range best {
+		best[i] = make([]int, c+1)
+	}
+	for i := 1; i <= n; i++ {
+		w, p := inst.Weights[i-1], inst.Profits[i-1]
+		for j := 0; j <= c; j++ {
+			best[i][j] = best[i-1][j]
+			if w <= j && best[i][j] < best[i-1][j-w]+p {
+				best[i][j] = best[i-1][j-w] + p
+			}
+		}
+	}
+	x = make([]bool, n)
+	for i, j := n, c; 0 < i; i-- {
+		if best[i][j] != best[i-1][j] {
+			x[i-1] = true
+			j -= inst.Weights[i-1]
+		}
+	}
+	return x, best[n][c]
+}
+
+// ratios returns the items ordered by increasing ratio of profit to weight.
+func (inst *Instance) ratios() []int {
+	idx := make([]int, inst.Len())
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(a, b int) bool {
+		i, j := idx[a], idx[b]
+		return inst.Profits[i]*inst.Weights[j] < inst.Profits[j]*inst.Weights[i]
+	})
+	return idx
+}
+
+// Repair makes a solution feasible in place. Chosen items with the worst ratio
+// of profit to weight are dropped until the solution fits, then unchosen items
+// with the best ratio are added while they fit.
+func (inst *Instance) Repair(x []bool) {
+	idx := inst.ratios()
+	_, weight := inst.Eval(x)
+	for _, i := range idx {
+		if weight <= inst.Capacity {
+			break
+		}
+		if x[i] {
+			x[i] = false
+			weight -= inst.Weights[i]
+		}
+	}
+	for k := len(idx) - 1; 0 <= k; k-- {
+		i := idx[k]
+		if !x[i] && weight+inst.Weights[i] <= inst.Capacity {
+			x[i] = true
+			weight += inst.Weights[i]
+		}
+	}
+}
+
+// A Strategy determines how a genome handles infeasible solutions.
+type Strategy int
+
+const (
+	// Penalty subtracts the excess weight, scaled by the best ratio of profit
+	// to weight, from the profit of infeasible solutions. Infeasible solutions
+	// remain in the population, but are never more fit than the feasible
+	// solutions obtained by removing their excess items.
+	Penalty Strategy = iota
+
+	// Repair makes solutions feasible with Instance.Repair before evaluation.
+	// The gene of the genome is modified.
+	Repair
+)
+
+// A Genome is a solution to a knapsack instance. The fitness is computed once.
+type Genome struct {
+	evo.Cache
+	Gene     []bool
+	Instance *Instance
+	Strategy Strategy
+}
+
+// New returns a genome for the given solution.
+func (inst *Instance) New(gene []bool, strategy Strategy) *Genome {
+	return &Genome{Gene: gene, Instance: inst, Strategy: strategy}
+}
+
+// Random returns a genome for a random solution, choosing each item with equal
+// probability.
+func (inst *Instance) Random(strategy Strategy) *Genome {
+	gene := make([]bool, inst.Len())
+	for i := range gene {
+		gene[i] = rand.Intn(2) == 0
+	}
+	return inst.New(gene, strategy)
+}
+
+// Fitness returns the profit of the solution, adjusted by the strategy for
+// infeasible solutions.
+func (g *Genome) Fitness() float64 {
+	return g.Cache.Fitness(func() float64 {
+		inst := g.Instance
+		if g.Strategy == Repair {
+			inst.Repair(g.Gene)
+		}
+		profit, weight := inst.Eval(g.Gene)
+		if weight <= inst.Capacity {
+			return float64(profit)
+		}
+		var rho float64
+		for i := range inst.Weights {
+			r := float64(inst.Profits[i]) / float64(inst.Weights[i])
+			if rho < r {
+				rho = r
+			}
+		}
+		return float64(profit) - rho*float64(weight-inst.Capacity)
+	})
+}
+
+// Alleles returns the choice of each item as 0 or 1, implementing evo.Discrete.
+func (g *Genome) Alleles() []int {
+	return binary.Alleles(g.Gene)
+}
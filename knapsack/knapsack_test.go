@@ -0,0 +1,51 @@
+package knapsack_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo/knapsack"
+)
+
+func TestInstances(t *testing.T) {
+	for _, inst := range knapsack.Instances {
+		x, profit := inst.Solve()
+		if profit != inst.Optimum {
+			t.Errorf("%s: solved %d, expected %d", inst.Name, profit, inst.Optimum)
+		}
+		if p, _ := inst.Eval(x); p != profit || !inst.Feasible(x) {
+			t.Errorf("%s: bad solution", inst.Name)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	inst, err := knapsack.Parse(strings.NewReader("3 10\n5 4\n4 6\n3 5\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if inst.Capacity != 10 || inst.Len() != 3 || inst.Profits[1] != 4 || inst.Weights[2] != 5 {
+		t.Fail()
+	}
+	if _, profit := inst.Solve(); profit != 9 {
+		t.Fail()
+	}
+	if _, err := knapsack.Parse(strings.NewReader("3 10\n5 4\n")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestStrategies(t *testing.T) {
+	inst := knapsack.P02
+	all := []bool{true, true, true, true, true}
+	profit, _ := inst.Eval(all)
+	if g := inst.New(all, knapsack.Penalty); g.Fitness() >= float64(profit) {
+		t.Fail()
+	}
+	g := inst.New(append([]bool(nil), all...), knapsack.Repair)
+	fit := g.Fitness()
+	profit, _ = inst.Eval(g.Gene)
+	if fit != float64(profit) || !inst.Feasible(g.Gene) {
+		t.Fail()
+	}
+}
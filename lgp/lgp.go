@@ -0,0 +1,136 @@
+// Package lgp provides linear genetic programming.
+//
+// In linear GP, a program is a sequence of register-machine instructions. Each
+// instruction applies an operator to two sources, which are registers or
+// constants, and writes the result to a destination register. Programs are
+// executed imperatively, which is fast and makes linear GP a lightweight
+// alternative to tree-based GP.
+//
+// Before execution, the inputs are loaded into the first registers and the
+// remaining registers are zeroed. The output of a program is the final value of
+// register 0.
+package lgp
+
+import (
+	"math"
+	"math/rand"
+)
+
+// An Op is an instruction operator.
+type Op struct {
+	Name string
+	Fn   func(a, b float64) float64
+}
+
+// Arithmetic operators. Division is protected, returning the dividend when the
+// divisor is zero.
+var (
+	Add = Op{"+", func(a, b float64) float64 { return a + b }}
+	Sub = Op{"-", func(a, b float64) float64 { return a - b }}
+	Mul = Op{"*", func(a, b float64) float64 { return a * b }}
+	Div = Op{"/", func(a, b float64) float64 {
+		if b == 0 {
+			return a
+		}
+		return a / b
+	}}
+)
+
+// An Instr is an instruction. Sources in [0,Registers) refer to registers and
+// sources in [Registers,Registers+len(Consts)) refer to constants.
+type Instr struct {
+	Op         int // index into the operator set
+	Dst        int // destination register
+	Src1, Src2 int // source registers or constants
+}
+
+// A Program is a sequence of instructions.
+type Program []Instr
+
+// Copy returns a copy of the program.
+func (p Program) Copy() Program {
+	return append(Program(nil), p...)
+}
+
+// A Machine describes the register machine on which programs are executed.
+type Machine struct {
+	Inputs    int       // number of inputs, loaded into the first registers
+	Registers int       // number of registers, at least Inputs and at least 1
+	Consts    []float64 // read-only constant registers
+	Ops       []Op      // the operator set
+	MaxLen    int       // maximum program length, 0 for unbounded
+}
+
+// A Case is a fitness case: some inputs and the desired output.
+type Case struct {
+	Inputs []float64
+	Output float64
+}
+
+// Instr returns a random instruction.
+func (m *Machine) Instr() Instr {
+	srcs := m.Registers + len(m.Consts)
+	return Instr{
+		Op:   rand.Intn(len(m.Ops)),
+		Dst:  rand.Intn(m.Registers),
+		Src1: rand.Intn(srcs),
+		Src2: rand.Intn(srcs),
+	}
+}
+
+// Random returns a random program of length n.
+func (m *Machine) Random(n int) Program {
+	p := make(Program, n)
+	for i := range p {
+		p[i] = m.Instr()
+	}
+	return p
+}
+
+// Run executes the program on the given inputs and returns the output.
+func (m *Machine) Run(p Program, inputs []float64) float64 {
+	regs := make([]float64, m.Registers+len(m.Consts))
+	copy(regs, inputs)
+	copy(regs[m.Registers:], m.Consts)
+	for _, in := range p {
+		regs[in.Dst] = m.Ops[in.Op].Fn(regs[in.Src1], regs[in.Src2])
+	}
+	return regs[0]
+}
+
+// MSE returns the mean squared error of the program over some fitness cases.
+// Programs producing NaN or infinite outputs have infinite error.
+func (m *Machine) MSE(p Program, cases []Case) float64 {
+	var sum float64
+	for _, c := range cases {
+		d := m.Run(p, c.Inputs) - c.Output
+		sum += d * d
+	}
+	mse := sum / float64(len(cases))
+	if math.IsNaN(mse) {
+		return math.Inf(+1)
+	}
+	return mse
+}
+
+// Effective returns which instructions of the program affect the output. The
+// other instructions are structural introns and can be skipped or removed.
+func (m *Machine) Effective(p Program) (eff []bool) {
+	eff = make([]bool, len(p))
+	live := make([]bool, m.Registers)
+	live[0] = true
+	for i := len(p) - 1; 0 <= i; i-- {
+		in := p[i]
+		if !live[in.Dst] {
+			continue
+		}
+		eff[i] = true
+		live[in.Dst] = false
+		for _, src := range [2]int{in.Src1, in.Src2} {
+			if src < m.Registers {
+				live[src] = true
+			}
+		}
+	}
+	return eff
+}
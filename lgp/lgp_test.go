@@ -0,0 +1,65 @@
+package lgp_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/lgp"
+)
+
+func machine() *lgp.Machine {
+	return &lgp.Machine{
+		Inputs:    2,
+		Registers: 4,
+		Consts:    []float64{1, 2},
+		Ops:       []lgp.Op{lgp.Add, lgp.Sub, lgp.Mul, lgp.Div},
+		MaxLen:    16,
+	}
+}
+
+// lgp.go
+// -------------------------
+
+func TestRun(t *testing.T) {
+	m := machine()
+	p := lgp.Program{
+		{Op: 2, Dst: 3, Src1: 5, Src2: 1}, // r3 = 2 * y
+		{Op: 3, Dst: 2, Src1: 0, Src2: 2}, // r2 = x / 0 (intron)
+		{Op: 0, Dst: 0, Src1: 0, Src2: 3}, // r0 = x + r3
+	}
+	if m.Run(p, []float64{3, 4}) != 11 {
+		t.Fail()
+	}
+	cases := []lgp.Case{{[]float64{3, 4}, 11}, {[]float64{1, 1}, 4}}
+	if m.MSE(p, cases) != 0.5 {
+		t.Fail()
+	}
+	eff := m.Effective(p)
+	if !eff[0] || eff[1] || !eff[2] {
+		t.Fail()
+	}
+}
+
+// ops.go
+// -------------------------
+
+func TestOps(t *testing.T) {
+	m := machine()
+	mom := m.Random(10)
+	dad := m.Random(12)
+	for i := 0; i < 100; i++ {
+		a, b := m.Cross(mom, dad)
+		if len(a) > 16 || len(b) > 16 {
+			t.Fail()
+		}
+		if len(a)+len(b) != len(mom)+len(dad) {
+			t.Fail()
+		}
+		a = m.Macro(a)
+		m.Micro(a)
+		if len(a) == 0 || len(a) > 16 {
+			t.Fail()
+		}
+		m.Run(a, []float64{1, 2})
+		mom, dad = a, b
+	}
+}
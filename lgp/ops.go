@@ -0,0 +1,86 @@
+package lgp
+
+import (
+	"math/rand"
+)
+
+// Cross performs two-point crossover of variable length programs. A random
+// segment of each parent is exchanged, producing two children. Segment lengths
+// are adjusted so that neither child exceeds the maximum length of the machine,
+// assuming the parents do not.
+func (m *Machine) Cross(mom, dad Program) (a, b Program) {
+	i, j := segment(mom)
+	k, l := segment(dad)
+	for m.MaxLen > 0 {
+		alen := len(mom) - (j - i) + (l - k)
+		blen := len(dad) - (l - k) + (j - i)
+		if alen <= m.MaxLen && blen <= m.MaxLen {
+			break
+		}
+		if alen > m.MaxLen {
+			l--
+		} else {
+			j--
+		}
+	}
+	a = make(Program, 0, len(mom)-(j-i)+(l-k))
+	a = append(append(append(a, mom[:i]...), dad[k:l]...), mom[j:]...)
+	b = make(Program, 0, len(dad)-(l-k)+(j-i))
+	b = append(append(append(b, dad[:k]...), mom[i:j]...), dad[l:]...)
+	return a, b
+}
+
+// segment returns the bounds of a random non-empty segment of p, or an empty
+// segment if p is empty.
+func segment(p Program) (i, j int) {
+	if len(p) == 0 {
+		return 0, 0
+	}
+	i = rand.Intn(len(p))
+	j = i + 1 + rand.Intn(len(p)-i)
+	return i, j
+}
+
+// Macro performs a macro mutation, inserting or deleting a random instruction
+// with equal probability. Insertions are skipped when the program is at the
+// maximum length and deletions are skipped when only one instruction remains.
+// The program is modified in place and the result may share its memory.
+func (m *Machine) Macro(p Program) Program {
+	insert := rand.Intn(2) == 0
+	if len(p) <= 1 {
+		insert = true
+	}
+	if m.MaxLen > 0 && len(p) >= m.MaxLen {
+		insert = false
+	}
+	if insert {
+		i := rand.Intn(len(p) + 1)
+		p = append(p, Instr{})
+		copy(p[i+1:], p[i:])
+		p[i] = m.Instr()
+	} else if len(p) > 1 {
+		i := rand.Intn(len(p))
+		p = append(p[:i], p[i+1:]...)
+	}
+	return p
+}
+
+// Micro performs a micro mutation, changing the operator, the destination, or
+// one of the sources of a random instruction.
+func (m *Machine) Micro(p Program) {
+	if len(p) == 0 {
+		return
+	}
+	i := rand.Intn(len(p))
+	srcs := m.Registers + len(m.Consts)
+	switch rand.Intn(4) {
+	case 0:
+		p[i].Op = rand.Intn(len(m.Ops))
+	case 1:
+		p[i].Dst = rand.Intn(m.Registers)
+	case 2:
+		p[i].Src1 = rand.Intn(srcs)
+	case 3:
+		p[i].Src2 = rand.Intn(srcs)
+	}
+}
@@ -0,0 +1,95 @@
+package evo
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// A Hasher is a genome which can hash its genotype. Identical genotypes must
+// have equal hashes, and distinct genotypes should rarely collide.
+type Hasher interface {
+	Genome
+	Hash() uint64
+}
+
+// A Memo is a fitness cache shared between genomes, keyed by the hash of the
+// genotype. Identical genotypes produced repeatedly, e.g. late in a run, are
+// only evaluated once. Unlike a Cache, which is embedded in a single genome, a
+// Memo may be shared across a whole run or across populations. Memos are safe
+// for concurrent use. Concurrent misses on the same hash may compute the
+// fitness more than once.
+//
+// Memos are typically consulted through the Cache of a genome:
+//
+//	var memo = evo.NewMemo(0)
+//
+//	func (g *genome) Fitness() float64 {
+//		return g.Cache.Fitness(func() float64 {
+//			return memo.Fitness(g.Hash(), g.evaluate)
+//		})
+//	}
+type Memo struct {
+	size         int
+	mu           sync.RWMutex
+	fits         map[uint64]float64
+	hits, misses atomic.Int64
+}
+
+// NewMemo returns an empty memo holding at most size fitnesses. When the memo
+// is full, arbitrary entries are evicted. A size of 0 means unbounded.
+func NewMemo(size int) *Memo {
+	return &Memo{
+		size: size,
+		fits: make(map[uint64]float64),
+	}
+}
+
+// Fitness returns the memoized fitness for the hash, computing it with fn on a
+// miss.
+func (m *Memo) Fitness(hash uint64, fn func() float64) float64 {
+	m.mu.RLock()
+	fit, ok := m.fits[hash]
+	m.mu.RUnlock()
+	if ok {
+		m.hits.Add(1)
+		return fit
+	}
+	m.misses.Add(1)
+	fit = fn()
+	m.mu.Lock()
+	if 0 < m.size && m.size <= len(m.fits) {
+		for k := range m.fits {
+			delete(m.fits, k)
+			break
+		}
+	}
+	m.fits[hash] = fit
+	m.mu.Unlock()
+	return fit
+}
+
+// Len returns the number of memoized fitnesses.
+func (m *Memo) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.fits)
+}
+
+// Hits returns the number of lookups answered from the memo.
+func (m *Memo) Hits() int {
+	return int(m.hits.Load())
+}
+
+// Misses returns the number of lookups which computed the fitness.
+func (m *Memo) Misses() int {
+	return int(m.misses.Load())
+}
+
+// HitRate returns the fraction of lookups answered from the memo.
+func (m *Memo) HitRate() float64 {
+	hits, misses := m.hits.Load(), m.misses.Load()
+	if hits+misses == 0 {
+		return 0
+	}
+	return float64(hits) / float64(hits+misses)
+}
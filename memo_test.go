@@ -0,0 +1,29 @@
+package evo_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestMemo(t *testing.T) {
+	memo := evo.NewMemo(2)
+	var evals int
+	fn := func() float64 {
+		evals++
+		return float64(evals)
+	}
+	if memo.Fitness(1, fn) != 1 || memo.Fitness(1, fn) != 1 {
+		t.Fail()
+	}
+	if memo.Fitness(2, fn) != 2 {
+		t.Fail()
+	}
+	memo.Fitness(3, fn)
+	if memo.Len() != 2 || memo.Hits() != 1 || memo.Misses() != 3 {
+		t.Fail()
+	}
+	if memo.HitRate() != 0.25 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,45 @@
+package neat
+
+import (
+	"math/rand"
+)
+
+// Cross performs crossover with historical markings. Connections are aligned
+// by innovation number. Matching connections are inherited randomly from
+// either parent, while disjoint and excess connections are inherited from the
+// more fit parent (mom, when the fitness is equal). A connection which is
+// disabled in either parent has a 75% chance to be disabled in the child.
+func Cross(mom, dad *Network, momFit, dadFit float64) (child *Network) {
+	if dadFit > momFit {
+		mom, dad = dad, mom
+	}
+	child = new(Network)
+	i, j := 0, 0
+	for i < len(mom.Conns) {
+		a := mom.Conns[i]
+		switch {
+		case j < len(dad.Conns) && dad.Conns[j].Innov < a.Innov:
+			j++
+			continue
+		case j < len(dad.Conns) && dad.Conns[j].Innov == a.Innov:
+			b := dad.Conns[j]
+			c := a
+			if rand.Intn(2) == 0 {
+				c = b
+			}
+			c.Enabled = true
+			if (!a.Enabled || !b.Enabled) && rand.Float64() < 0.75 {
+				c.Enabled = false
+			}
+			child.Conns = append(child.Conns, c)
+			j++
+		default:
+			child.Conns = append(child.Conns, a)
+		}
+		i++
+	}
+
+	// the nodes of the child are exactly those of the fitter parent
+	child.Nodes = append(child.Nodes, mom.Nodes...)
+	return child
+}
@@ -0,0 +1,28 @@
+// Package neat provides neuroevolution of augmenting topologies.
+//
+// NEAT evolves both the weights and the structure of neural networks. Networks
+// start minimal, with every input connected to every output, and grow through
+// structural mutations which add connections and split connections with new
+// nodes. Every structural innovation is given a historical marking, called an
+// innovation number, which allows networks of different topology to be aligned
+// during crossover and compared during speciation.
+//
+// A Network is not itself a Genome, since only the user knows how to evaluate
+// it. Instead, user genomes embed a network and implement Fitness by running
+// the network on their task. Those genomes can then be evolved by any of the
+// population types of Evo:
+//
+//	type genome struct {
+//		*neat.Network
+//		evo.Cache
+//	}
+//
+//	func (g *genome) Fitness() float64 {
+//		return g.Cache.Fitness(func() float64 {
+//			out := g.Activate(inputs)
+//			...
+//		})
+//	}
+//
+// All networks of a run must share a single Innovations tracker.
+package neat
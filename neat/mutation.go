@@ -0,0 +1,118 @@
+package neat
+
+import (
+	"math/rand"
+)
+
+// MutateWeights perturbs each weight with the given probability by a uniform
+// amount in [-power,power). With a 10% chance, a mutated weight is instead
+// replaced by a new uniform value in [-power,power).
+func (net *Network) MutateWeights(rate, power float64) {
+	for i := range net.Conns {
+		if rand.Float64() < rate {
+			delta := (rand.Float64()*2 - 1) * power
+			if rand.Float64() < 0.1 {
+				net.Conns[i].Weight = delta
+			} else {
+				net.Conns[i].Weight += delta
+			}
+		}
+	}
+}
+
+// AddConn attempts to connect two previously unconnected nodes with a random
+// weight. Connections are never added into input or bias nodes, and never in a
+// way that creates a cycle. AddConn returns false if no connection was added
+// after a few attempts.
+func (net *Network) AddConn(innov *Innovations) bool {
+	for tries := 0; tries < 20; tries++ {
+		a := net.Nodes[rand.Intn(len(net.Nodes))]
+		b := net.Nodes[rand.Intn(len(net.Nodes))]
+		if b.Kind == Input || b.Kind == Bias || a.Kind == Output {
+			continue
+		}
+		if a.ID == b.ID || net.connected(a.ID, b.ID) || net.reaches(b.ID, a.ID) {
+			continue
+		}
+		net.addConn(Conn{
+			In:      a.ID,
+			Out:     b.ID,
+			Weight:  rand.Float64()*2 - 1,
+			Enabled: true,
+			Innov:   innov.conn(a.ID, b.ID),
+		})
+		return true
+	}
+	return false
+}
+
+// AddNode splits a random enabled connection with a new hidden node. The old
+// connection is disabled. The connection into the new node has weight 1 and the
+// connection out of the new node inherits the old weight, so the behavior of the
+// network is nearly unchanged. AddNode returns false if there is no enabled
+// connection to split.
+func (net *Network) AddNode(innov *Innovations) bool {
+	var enabled []int
+	for i := range net.Conns {
+		if net.Conns[i].Enabled {
+			enabled = append(enabled, i)
+		}
+	}
+	if len(enabled) == 0 {
+		return false
+	}
+	old := net.Conns[enabled[rand.Intn(len(enabled))]]
+	id := innov.split(old.Innov, false)
+	if net.node(id) != -1 {
+		id = innov.split(old.Innov, true)
+	}
+	for i := range net.Conns {
+		if net.Conns[i].Innov == old.Innov {
+			net.Conns[i].Enabled = false
+		}
+	}
+	net.addNode(Node{id, Hidden})
+	net.addConn(Conn{old.In, id, 1, true, innov.conn(old.In, id)})
+	net.addConn(Conn{id, old.Out, old.Weight, true, innov.conn(id, old.Out)})
+	return true
+}
+
+// Toggle flips the enabled bit of a random connection.
+func (net *Network) Toggle() {
+	if len(net.Conns) == 0 {
+		return
+	}
+	i := rand.Intn(len(net.Conns))
+	net.Conns[i].Enabled = !net.Conns[i].Enabled
+}
+
+// connected returns true if there is a connection from a to b.
+func (net *Network) connected(a, b int) bool {
+	for _, c := range net.Conns {
+		if c.In == a && c.Out == b {
+			return true
+		}
+	}
+	return false
+}
+
+// reaches returns true if there is a path from a to b, including through
+// disabled connections which may later be re-enabled.
+func (net *Network) reaches(a, b int) bool {
+	seen := map[int]bool{a: true}
+	stack := []int{a}
+	for len(stack) > 0 {
+		x := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if x == b {
+			return true
+		}
+		for _, c := range net.Conns {
+			if c.In == x && !seen[c.Out] {
+				seen[c.Out] = true
+				stack = append(stack, c.Out)
+			}
+		}
+	}
+	return false
+}
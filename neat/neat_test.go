@@ -0,0 +1,102 @@
+package neat_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/cbarrick/evo/neat"
+)
+
+// sorted fails the test if the network is not sorted properly.
+func sorted(t *testing.T, net *neat.Network) {
+	ok := sort.SliceIsSorted(net.Nodes, func(i, j int) bool {
+		return net.Nodes[i].ID < net.Nodes[j].ID
+	})
+	ok = ok && sort.SliceIsSorted(net.Conns, func(i, j int) bool {
+		return net.Conns[i].Innov < net.Conns[j].Innov
+	})
+	if !ok {
+		t.Fail()
+	}
+}
+
+// network.go
+// -------------------------
+
+func TestNew(t *testing.T) {
+	var innov neat.Innovations
+	a := neat.New(2, 1, &innov)
+	b := neat.New(2, 1, &innov)
+	if len(a.Nodes) != 4 || len(a.Conns) != 3 {
+		t.Fail()
+	}
+	for i := range a.Conns {
+		if a.Conns[i].Innov != b.Conns[i].Innov {
+			t.Fail()
+		}
+	}
+	if out := a.Activate([]float64{1, 0}); len(out) != 1 || out[0] <= 0 || 1 <= out[0] {
+		t.Fail()
+	}
+}
+
+// mutation.go
+// -------------------------
+
+func TestMutation(t *testing.T) {
+	var innov neat.Innovations
+	net := neat.New(3, 2, &innov)
+	for i := 0; i < 50; i++ {
+		net.AddNode(&innov)
+		net.AddConn(&innov)
+		net.MutateWeights(0.8, 0.5)
+		net.Toggle()
+		sorted(t, net)
+		if out := net.Activate([]float64{1, 2, 3}); len(out) != 2 {
+			t.Fail()
+		}
+	}
+}
+
+// cross.go
+// -------------------------
+
+func TestCross(t *testing.T) {
+	var innov neat.Innovations
+	mom := neat.New(2, 1, &innov)
+	dad := mom.Copy()
+	mom.AddNode(&innov)
+	dad.AddConn(&innov)
+	dad.AddNode(&innov)
+	child := neat.Cross(mom, dad, 1, 0)
+	sorted(t, child)
+	if len(child.Conns) != len(mom.Conns) || len(child.Nodes) != len(mom.Nodes) {
+		t.Fail()
+	}
+	child.Activate([]float64{0, 1})
+}
+
+// species.go
+// -------------------------
+
+func TestSpeciate(t *testing.T) {
+	var innov neat.Innovations
+	a := neat.New(2, 1, &innov)
+	b := a.Copy()
+	c := a.Copy()
+	for i := range c.Conns {
+		c.Conns[i].Weight += 10
+	}
+	compat := neat.DefaultCompat
+	if compat.Distance(a, b) != 0 || compat.Distance(a, c) < compat.Threshold {
+		t.Fail()
+	}
+	species := compat.Speciate([]*neat.Network{a, b, c}, nil)
+	if len(species) != 2 || len(species[0].Members) != 2 {
+		t.Fail()
+	}
+	shared := neat.Share([]float64{2, 2, 2}, species)
+	if shared[0] != 1 || shared[2] != 2 {
+		t.Fail()
+	}
+}
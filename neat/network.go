@@ -0,0 +1,236 @@
+package neat
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+)
+
+// Activation is the activation function of hidden and output nodes. The
+// default is the steepened sigmoid used in the original NEAT paper.
+var Activation = func(x float64) float64 {
+	return 1 / (1 + math.Exp(-4.9*x))
+}
+
+// A NodeKind distinguishes the roles of nodes.
+type NodeKind int
+
+// The kinds of nodes.
+const (
+	Input NodeKind = iota
+	Bias
+	Hidden
+	Output
+)
+
+// A Node is a neuron.
+type Node struct {
+	ID   int
+	Kind NodeKind
+}
+
+// A Conn is a weighted connection between two nodes.
+type Conn struct {
+	In, Out int     // node IDs
+	Weight  float64 // the connection weight
+	Enabled bool    // disabled connections are ignored during activation
+	Innov   int     // the historical marking of the connection
+}
+
+// A Network is a NEAT genome. Nodes are sorted by ID and connections are sorted
+// by innovation number.
+type Network struct {
+	Nodes []Node
+	Conns []Conn
+}
+
+// Innovations tracks the historical markings of structural mutations. When the
+// same structural mutation occurs more than once, it receives the same markings.
+// The zero value is ready to use, and Innovations are safe to use concurrently.
+type Innovations struct {
+	mu     sync.Mutex
+	innov  int            // the next innovation number
+	node   int            // the next node ID
+	conns  map[[2]int]int // the innovation number of each connection
+	splits map[int]int    // the node ID created by splitting each connection
+}
+
+// conn returns the innovation number of a connection.
+func (in *Innovations) conn(a, b int) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.conns == nil {
+		in.conns = make(map[[2]int]int)
+	}
+	key := [2]int{a, b}
+	if innov, ok := in.conns[key]; ok {
+		return innov
+	}
+	in.conns[key] = in.innov
+	in.innov++
+	return in.innov - 1
+}
+
+// split returns the ID of the node created by splitting a connection. If fresh
+// is true, a new ID is allocated even if the connection has been split before.
+func (in *Innovations) split(innov int, fresh bool) int {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.splits == nil {
+		in.splits = make(map[int]int)
+	}
+	if id, ok := in.splits[innov]; ok && !fresh {
+		return id
+	}
+	in.splits[innov] = in.node
+	in.node++
+	return in.node - 1
+}
+
+// reserve ensures that node IDs below n are never allocated.
+func (in *Innovations) reserve(n int) {
+	in.mu.Lock()
+	defer in.mu.Unlock()
+	if in.node < n {
+		in.node = n
+	}
+}
+
+// New returns a minimal network with every input and a bias node connected to
+// every output. Weights are drawn uniformly from [-1,1). The input nodes have
+// IDs [0,inputs), the bias has ID inputs, and the outputs follow.
+func New(inputs, outputs int, innov *Innovations) *Network {
+	net := new(Network)
+	size := inputs + 1 + outputs
+	innov.reserve(size)
+	for i := 0; i < size; i++ {
+		kind := Input
+		if i == inputs {
+			kind = Bias
+		} else if i > inputs {
+			kind = Output
+		}
+		net.Nodes = append(net.Nodes, Node{i, kind})
+	}
+	for o := inputs + 1; o < size; o++ {
+		for i := 0; i <= inputs; i++ {
+			net.Conns = append(net.Conns, Conn{
+				In:      i,
+				Out:     o,
+				Weight:  rand.Float64()*2 - 1,
+				Enabled: true,
+				Innov:   innov.conn(i, o),
+			})
+		}
+	}
+	sort.Sort(byInnov(net.Conns))
+	return net
+}
+
+// Copy returns a deep copy of the network.
+func (net *Network) Copy() *Network {
+	return &Network{
+		Nodes: append([]Node(nil), net.Nodes...),
+		Conns: append([]Conn(nil), net.Conns...),
+	}
+}
+
+// node returns the index of the node with the given ID, or -1.
+func (net *Network) node(id int) int {
+	i := sort.Search(len(net.Nodes), func(i int) bool { return net.Nodes[i].ID >= id })
+	if i < len(net.Nodes) && net.Nodes[i].ID == id {
+		return i
+	}
+	return -1
+}
+
+// addNode inserts a node, keeping the nodes sorted.
+func (net *Network) addNode(n Node) {
+	i := sort.Search(len(net.Nodes), func(i int) bool { return net.Nodes[i].ID >= n.ID })
+	net.Nodes = append(net.Nodes, Node{})
+	copy(net.Nodes[i+1:], net.Nodes[i:])
+	net.Nodes[i] = n
+}
+
+// addConn inserts a connection, keeping the connections sorted.
+func (net *Network) addConn(c Conn) {
+	i := sort.Search(len(net.Conns), func(i int) bool { return net.Conns[i].Innov >= c.Innov })
+	net.Conns = append(net.Conns, Conn{})
+	copy(net.Conns[i+1:], net.Conns[i:])
+	net.Conns[i] = c
+}
+
+// order returns the IDs of the hidden and output nodes in topological order.
+func (net *Network) order() []int {
+	indeg := make(map[int]int, len(net.Nodes))
+	edges := make(map[int][]int, len(net.Nodes))
+	for _, c := range net.Conns {
+		if c.Enabled {
+			indeg[c.Out]++
+			edges[c.In] = append(edges[c.In], c.Out)
+		}
+	}
+	var queue, order []int
+	for _, n := range net.Nodes {
+		if indeg[n.ID] == 0 {
+			queue = append(queue, n.ID)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if k := net.Nodes[net.node(id)].Kind; k == Hidden || k == Output {
+			order = append(order, id)
+		}
+		for _, out := range edges[id] {
+			indeg[out]--
+			if indeg[out] == 0 {
+				queue = append(queue, out)
+			}
+		}
+	}
+	return order
+}
+
+// Activate feeds the inputs through the network and returns the outputs. The
+// network must have one input for each input node.
+func (net *Network) Activate(inputs []float64) (outputs []float64) {
+	values := make(map[int]float64, len(net.Nodes))
+	incoming := make(map[int][]Conn, len(net.Nodes))
+	var i int
+	for _, n := range net.Nodes {
+		switch n.Kind {
+		case Input:
+			values[n.ID] = inputs[i]
+			i++
+		case Bias:
+			values[n.ID] = 1
+		}
+	}
+	for _, c := range net.Conns {
+		if c.Enabled {
+			incoming[c.Out] = append(incoming[c.Out], c)
+		}
+	}
+	for _, id := range net.order() {
+		var sum float64
+		for _, c := range incoming[id] {
+			sum += values[c.In] * c.Weight
+		}
+		values[id] = Activation(sum)
+	}
+	for _, n := range net.Nodes {
+		if n.Kind == Output {
+			outputs = append(outputs, values[n.ID])
+		}
+	}
+	return outputs
+}
+
+// byInnov sorts connections by innovation number.
+type byInnov []Conn
+
+func (c byInnov) Len() int           { return len(c) }
+func (c byInnov) Swap(i, j int)      { c[i], c[j] = c[j], c[i] }
+func (c byInnov) Less(i, j int) bool { return c[i].Innov < c[j].Innov }
@@ -0,0 +1,113 @@
+package neat
+
+import (
+	"math"
+	"math/rand"
+)
+
+// Compat holds the coefficients of the compatibility distance.
+type Compat struct {
+	Excess    float64 // weight of excess connections
+	Disjoint  float64 // weight of disjoint connections
+	Weight    float64 // weight of the mean weight difference of matching connections
+	Threshold float64 // the maximum distance between members of a species
+}
+
+// DefaultCompat holds the coefficients used in the original NEAT paper.
+var DefaultCompat = Compat{
+	Excess:    1,
+	Disjoint:  1,
+	Weight:    0.4,
+	Threshold: 3,
+}
+
+// Distance returns the compatibility distance between two networks. Genomes
+// with fewer than 20 connections are not normalized by size.
+func (c Compat) Distance(a, b *Network) float64 {
+	var (
+		excess, disjoint, matching float64
+		wdiff                      float64
+		i, j                       int
+	)
+	for i < len(a.Conns) && j < len(b.Conns) {
+		x, y := a.Conns[i], b.Conns[j]
+		switch {
+		case x.Innov == y.Innov:
+			matching++
+			wdiff += math.Abs(x.Weight - y.Weight)
+			i++
+			j++
+		case x.Innov < y.Innov:
+			disjoint++
+			i++
+		default:
+			disjoint++
+			j++
+		}
+	}
+	excess = float64(len(a.Conns) - i + len(b.Conns) - j)
+	n := float64(len(a.Conns))
+	if len(b.Conns) > len(a.Conns) {
+		n = float64(len(b.Conns))
+	}
+	if n < 20 {
+		n = 1
+	}
+	if matching > 0 {
+		wdiff /= matching
+	}
+	return c.Excess*excess/n + c.Disjoint*disjoint/n + c.Weight*wdiff
+}
+
+// A Species is a group of similar networks.
+type Species struct {
+	Rep     *Network // the representative of the species
+	Members []int    // indices of the members in the population
+	Age     int      // the number of generations the species has existed
+}
+
+// Speciate divides a population of networks into species. Each network joins
+// the first species of the previous generation whose representative is within
+// the compatibility threshold, otherwise it founds a new species. Afterwards,
+// a random member of each species becomes its new representative, and species
+// without members go extinct.
+func (c Compat) Speciate(nets []*Network, prev []Species) (next []Species) {
+	next = make([]Species, len(prev))
+	for i := range prev {
+		next[i] = Species{Rep: prev[i].Rep, Age: prev[i].Age + 1}
+	}
+	for i, net := range nets {
+		found := false
+		for s := range next {
+			if c.Distance(net, next[s].Rep) < c.Threshold {
+				next[s].Members = append(next[s].Members, i)
+				found = true
+				break
+			}
+		}
+		if !found {
+			next = append(next, Species{Rep: net, Members: []int{i}})
+		}
+	}
+	live := next[:0]
+	for _, s := range next {
+		if len(s.Members) > 0 {
+			s.Rep = nets[s.Members[rand.Intn(len(s.Members))]]
+			live = append(live, s)
+		}
+	}
+	return live
+}
+
+// Share performs explicit fitness sharing. The fitness of each member of a
+// species is divided by the size of the species. Fitnesses are given and
+// returned in the order of the population.
+func Share(fits []float64, species []Species) (shared []float64) {
+	shared = make([]float64, len(fits))
+	for _, s := range species {
+		for _, i := range s.Members {
+			shared[i] = fits[i] / float64(len(s.Members))
+		}
+	}
+	return shared
+}
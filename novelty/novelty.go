@@ -0,0 +1,163 @@
+// Package novelty provides novelty search.
+//
+// Novelty search rewards genomes for behaving differently from those seen
+// before, rather than for making progress on the objective. This helps on
+// deceptive problems, where following the objective leads into local optima.
+// The behavior of a genome is described by a vector of numbers, and the novelty
+// of a behavior is its mean distance to the k nearest behaviors in an archive.
+//
+// User genomes provide behavior descriptors by implementing Behaver. Wrapping
+// them with an archive yields genomes whose fitness is the novelty, or a blend
+// of novelty and the original objective.
+package novelty
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Behaver is a genome with a behavior descriptor.
+type Behaver interface {
+	evo.Genome
+	Behavior() []float64
+}
+
+// An Archive records behaviors for computing novelty. Behaviors are added to
+// the archive permanently when their novelty exceeds a threshold. The archive
+// also remembers a window of the most recently considered behaviors, which
+// approximates the current population. Archives are safe for concurrent use.
+type Archive struct {
+	k         int
+	threshold float64
+
+	mu        sync.RWMutex
+	archive   [][]float64 // behaviors kept permanently
+	recent    [][]float64 // ring buffer of recent behaviors
+	recentIdx int         // next position in the ring buffer
+}
+
+// NewArchive returns an empty archive measuring novelty against the k nearest
+// neighbors. Behaviors with novelty above the threshold are archived. The most
+// recent behaviors considered, up to the given window, also count as neighbors.
+func NewArchive(k int, threshold float64, window int) *Archive {
+	return &Archive{
+		k:         k,
+		threshold: threshold,
+		recent:    make([][]float64, 0, window),
+	}
+}
+
+// Len returns the number of behaviors kept permanently in the archive.
+func (a *Archive) Len() int {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	return len(a.archive)
+}
+
+// Novelty returns the mean Euclidean distance from the behavior to its k nearest
+// neighbors among the archived and recent behaviors. If there are no neighbors,
+// the novelty is +Inf.
+func (a *Archive) Novelty(b []float64) float64 {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+	dists := make([]float64, 0, len(a.archive)+len(a.recent))
+	for _, x := range a.archive {
+		dists = append(dists, Distance(b, x))
+	}
+	for _, x := range a.recent {
+		dists = append(dists, Distance(b, x))
+	}
+	if len(dists) == 0 {
+		return math.Inf(+1)
+	}
+	sort.Float64s(dists)
+	k := a.k
+	if len(dists) < k {
+		k = len(dists)
+	}
+	var sum float64
+	for _, d := range dists[:k] {
+		sum += d
+	}
+	return sum / float64(k)
+}
+
+// Add archives a behavior permanently.
+func (a *Archive) Add(b []float64) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.archive = append(a.archive, b)
+}
+
+// Consider computes the novelty of a behavior, archives it if the novelty
+// exceeds the threshold, and remembers it as a recent behavior.
+func (a *Archive) Consider(b []float64) (novelty float64) {
+	novelty = a.Novelty(b)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if novelty > a.threshold {
+		a.archive = append(a.archive, b)
+	}
+	if cap(a.recent) > 0 {
+		if len(a.recent) < cap(a.recent) {
+			a.recent = append(a.recent, b)
+		} else {
+			a.recent[a.recentIdx] = b
+		}
+		a.recentIdx = (a.recentIdx + 1) % cap(a.recent)
+	}
+	return novelty
+}
+
+// Distance returns the Euclidean distance between two behaviors.
+func Distance(a, b []float64) float64 {
+	var sum float64
+	for i := range a {
+		d := a[i] - b[i]
+		sum += d * d
+	}
+	return math.Sqrt(sum)
+}
+
+// A Genome wraps a Behaver, substituting novelty into its fitness.
+type Genome struct {
+	Behaver
+	archive *Archive
+	weight  float64
+	fit     float64
+	once    sync.Once
+}
+
+// Wrap wraps a genome with an archive. The fitness of the wrapper is a blend of
+// novelty and the fitness of the underlying genome:
+//
+//	weight*novelty + (1-weight)*objective
+//
+// A weight of 1 gives pure novelty search. The fitness is computed once, at the
+// first call to Fitness, when the behavior is considered by the archive.
+func Wrap(g Behaver, archive *Archive, weight float64) *Genome {
+	return &Genome{Behaver: g, archive: archive, weight: weight}
+}
+
+// Fitness returns the blend of novelty and objective.
+func (g *Genome) Fitness() float64 {
+	g.once.Do(func() {
+		novelty := g.archive.Consider(g.Behavior())
+		if math.IsInf(novelty, +1) {
+			novelty = 0
+		}
+		g.fit = g.weight * novelty
+		if g.weight != 1 {
+			g.fit += (1 - g.weight) * g.Behaver.Fitness()
+		}
+	})
+	return g.fit
+}
+
+// Objective returns the fitness of the underlying genome.
+func (g *Genome) Objective() float64 {
+	return g.Behaver.Fitness()
+}
@@ -0,0 +1,50 @@
+package novelty_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cbarrick/evo/novelty"
+)
+
+type point [2]float64
+
+func (p point) Fitness() float64    { return -p[0] }
+func (p point) Behavior() []float64 { return p[:] }
+
+func TestArchive(t *testing.T) {
+	a := novelty.NewArchive(2, 1.5, 0)
+	if !math.IsInf(a.Novelty([]float64{0, 0}), +1) {
+		t.Fail()
+	}
+	a.Add([]float64{0, 0})
+	a.Add([]float64{3, 4})
+	if a.Novelty([]float64{0, 0}) != 2.5 {
+		t.Fail()
+	}
+	if a.Consider([]float64{0, 1}) <= 1.5 || a.Len() != 3 {
+		t.Fail()
+	}
+	if a.Consider([]float64{0, 1}) > 1.5 || a.Len() != 3 {
+		t.Fail()
+	}
+}
+
+func TestRecent(t *testing.T) {
+	a := novelty.NewArchive(1, math.Inf(+1), 2)
+	a.Consider([]float64{0})
+	a.Consider([]float64{10})
+	a.Consider([]float64{20})
+	if a.Len() != 0 || a.Novelty([]float64{1}) != 9 {
+		t.Fail()
+	}
+}
+
+func TestGenome(t *testing.T) {
+	a := novelty.NewArchive(1, 0, 0)
+	a.Add([]float64{0, 0})
+	g := novelty.Wrap(point{3, 4}, a, 0.5)
+	if g.Fitness() != 1 || g.Objective() != -3 {
+		t.Fail()
+	}
+}
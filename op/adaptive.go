@@ -0,0 +1,234 @@
+package op
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Bandit chooses among a fixed number of arms and learns from the rewards
+// of its choices. Adaptive operators use bandits to choose which of several
+// operators to apply. Bandits must be safe for concurrent use.
+type Bandit interface {
+	// Arms returns the number of arms.
+	Arms() int
+
+	// Choose returns the arm to pull next.
+	Choose() int
+
+	// Reward credits an arm with the reward of one of its pulls.
+	Reward(arm int, reward float64)
+}
+
+// A Credit computes the reward of an operator from the outcome of a child.
+type Credit func(Outcome) float64
+
+// Improvement credits the improvement of the child over the current genome, or
+// 0 if the child is worse.
+func Improvement(o Outcome) float64 {
+	return math.Max(0, o.Improvement())
+}
+
+// Survival credits 1 if the child survived the replacement, or 0 otherwise.
+func Survival(o Outcome) float64 {
+	if o.Survived {
+		return 1
+	}
+	return 0
+}
+
+// AdaptiveCrossover returns a crossover which chooses among several crossovers
+// with a bandit, and rewards the bandit with the credit of each child. The
+// bandit must have an arm for each crossover.
+func AdaptiveCrossover(b Bandit, credit Credit, xs ...Crossover) Crossover {
+	if b.Arms() != len(xs) {
+		panic("bandit arms do not match operators")
+	}
+	return adaptiveCross{b, credit, xs}
+}
+
+type adaptiveCross struct {
+	b      Bandit
+	credit Credit
+	xs     []Crossover
+}
+
+func (a adaptiveCross) Cross(mom, dad evo.Genome) (evo.Genome, Feedback) {
+	arm := a.b.Choose()
+	child, fb := a.xs[arm].Cross(mom, dad)
+	evo.Derive(child, nameOf(a.xs[arm]), mom, dad)
+	return child, a.feedback(arm, fb)
+}
+
+func (a adaptiveCross) feedback(arm int, fb Feedback) Feedback {
+	return func(o Outcome) {
+		a.b.Reward(arm, a.credit(o))
+		if fb != nil {
+			fb(o)
+		}
+	}
+}
+
+// AdaptiveMutation returns a mutation which chooses among several mutations
+// with a bandit, and rewards the bandit with the credit of each child. The
+// bandit must have an arm for each mutation.
+func AdaptiveMutation(b Bandit, credit Credit, ms ...Mutation) Mutation {
+	if b.Arms() != len(ms) {
+		panic("bandit arms do not match operators")
+	}
+	return adaptiveMutate{adaptiveCross{b, credit, nil}, ms}
+}
+
+type adaptiveMutate struct {
+	adaptiveCross
+	ms []Mutation
+}
+
+func (a adaptiveMutate) Mutate(child evo.Genome) Feedback {
+	arm := a.b.Choose()
+	fb := a.ms[arm].Mutate(child)
+	if name := nameOf(a.ms[arm]); name != "" {
+		evo.Derive(child, name)
+	}
+	return a.feedback(arm, fb)
+}
+
+// ProbabilityMatching is a bandit which pulls each arm with a probability
+// proportional to an estimate of its reward. The estimates are exponential
+// moving averages of the rewards.
+type ProbabilityMatching struct {
+	mu    sync.Mutex
+	q     []float64 // the reward estimate of each arm
+	pmin  float64
+	alpha float64
+}
+
+// NewProbabilityMatching returns a probability matching bandit with n arms.
+// Every arm is pulled with probability at least pmin, which must be less than
+// 1/n, so that no operator is abandoned for good. The adaptation rate alpha in
+// (0,1] is the weight of each new reward in the estimates.
+func NewProbabilityMatching(n int, pmin, alpha float64) *ProbabilityMatching {
+	if n < 1 || pmin < 0 || 1 <= pmin*float64(n) || alpha <= 0 || 1 < alpha {
+		panic("invalid probability matching parameters")
+	}
+	q := make([]float64, n)
+	for i := range q {
+		q[i] = 1
+	}
+	return &ProbabilityMatching{q: q, pmin: pmin, alpha: alpha}
+}
+
+// Arms returns the number of arms.
+func (pm *ProbabilityMatching) Arms() int {
+	return len(pm.q)
+}
+
+// Choose returns a random arm with the current probabilities.
+func (pm *ProbabilityMatching) Choose() int {
+	probs := pm.Probabilities()
+	x := rand.Float64()
+	for i, p := range probs {
+		if x < p {
+			return i
+		}
+		x -= p
+	}
+	return len(probs) - 1
+}
+
+// Reward updates the estimate of an arm.
+func (pm *ProbabilityMatching) Reward(arm int, reward float64) {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	pm.q[arm] += pm.alpha * (reward - pm.q[arm])
+}
+
+// Probabilities returns the probability of pulling each arm.
+func (pm *ProbabilityMatching) Probabilities() []float64 {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	n := float64(len(pm.q))
+	var sum float64
+	for _, q := range pm.q {
+		sum += q
+	}
+	probs := make([]float64, len(pm.q))
+	for i, q := range pm.q {
+		if sum == 0 {
+			probs[i] = 1 / n
+		} else {
+			probs[i] = pm.pmin + (1-n*pm.pmin)*q/sum
+		}
+	}
+	return probs
+}
+
+// UCB is a bandit which pulls the arm with the greatest upper confidence bound
+// on its mean reward, following the UCB1 algorithm. Arms which have not been
+// pulled are tried first. The exploration constant scales the confidence
+// bounds and should be on the order of the rewards.
+type UCB struct {
+	mu    sync.Mutex
+	c     float64   // exploration constant
+	pulls []float64 // the number of times each arm was chosen
+	n     []float64 // the number of rewards of each arm
+	mean  []float64 // the mean reward of each arm
+	total float64   // the total number of pulls
+}
+
+// NewUCB returns a UCB1 bandit with n arms and exploration constant c.
+func NewUCB(n int, c float64) *UCB {
+	if n < 1 || c < 0 {
+		panic("invalid UCB parameters")
+	}
+	return &UCB{
+		c:     c,
+		pulls: make([]float64, n),
+		n:     make([]float64, n),
+		mean:  make([]float64, n),
+	}
+}
+
+// Arms returns the number of arms.
+func (u *UCB) Arms() int {
+	return len(u.pulls)
+}
+
+// Choose returns the arm with the greatest upper confidence bound.
+func (u *UCB) Choose() (arm int) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	best := math.Inf(-1)
+	for i := range u.pulls {
+		var bound float64
+		if u.pulls[i] == 0 {
+			bound = math.Inf(1)
+		} else {
+			bound = u.mean[i] + u.c*math.Sqrt(2*math.Log(u.total)/u.pulls[i])
+		}
+		if best < bound {
+			best = bound
+			arm = i
+		}
+	}
+	u.pulls[arm]++
+	u.total++
+	return arm
+}
+
+// Reward updates the mean reward of an arm.
+func (u *UCB) Reward(arm int, reward float64) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.n[arm]++
+	u.mean[arm] += (reward - u.mean[arm]) / u.n[arm]
+}
+
+// Means returns the mean reward of each arm.
+func (u *UCB) Means() []float64 {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return append([]float64(nil), u.mean...)
+}
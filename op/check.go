@@ -0,0 +1,56 @@
+package op
+
+import (
+	"fmt"
+
+	"github.com/cbarrick/evo"
+)
+
+// An Invariant checks that a genome is valid, e.g. that its gene is a
+// permutation or within bounds, returning an error describing the violation.
+// See perm.Check and real.Vec.CheckBounds.
+type Invariant func(g evo.Genome) error
+
+// A Violation describes a child which broke an invariant. Pipelines with
+// invariants panic with a *Violation, so that a population configured with
+// OnError reports it as the value of an evo.PanicError.
+type Violation struct {
+	Operator string     // the offending operator, by name or type
+	Mom, Dad evo.Genome // the parents of the child
+	Child    evo.Genome // the invalid child
+	Err      error      // the error of the invariant
+}
+
+// Error describes the violation and the offending operator.
+func (v *Violation) Error() string {
+	return fmt.Sprintf("op: %s broke an invariant: %v", v.Operator, v.Err)
+}
+
+// Unwrap returns the error of the invariant.
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// Check adds invariants to the pipeline, which are checked after the crossover
+// and after each mutation and local search. When a child breaks an invariant,
+// the pipeline panics with a *Violation naming the operator which broke it.
+// Checking is meant for debugging custom operators, since silent corruption of
+// genomes is otherwise hard to trace; it costs a call of each invariant per
+// operator.
+func (p *Pipeline) Check(invs ...Invariant) *Pipeline {
+	p.invs = append(p.invs, invs...)
+	return p
+}
+
+// check panics if the child breaks one of the invariants after an operator.
+func check(invs []Invariant, op interface{}, mom, dad, child evo.Genome) {
+	for _, inv := range invs {
+		if err := inv(child); err != nil {
+			name := nameOf(op)
+			if name == "" {
+				name = fmt.Sprintf("%T", op)
+			}
+			panic(&Violation{Operator: name, Mom: mom, Dad: dad, Child: child, Err: err})
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package op
+
+import (
+	"math/rand"
+
+	"github.com/cbarrick/evo"
+)
+
+// A LocalSearch improves a genome. The budget bounds the effort of the search,
+// e.g. the number of steps or evaluations, as interpreted by the search. The
+// search returns the improved genome, which may be g itself modified in place
+// when used in Lamarckian mode.
+type LocalSearch interface {
+	Search(g evo.Genome, budget int) evo.Genome
+}
+
+// LocalSearchFunc adapts a function to a LocalSearch.
+type LocalSearchFunc func(g evo.Genome, budget int) evo.Genome
+
+// Search calls f(g, budget).
+func (f LocalSearchFunc) Search(g evo.Genome, budget int) evo.Genome {
+	return f(g, budget)
+}
+
+// HillClimb returns a first-improvement hill climber. Each step of the budget
+// draws a random neighbor of the current genome and moves to it if it is at
+// least as fit. The neighbor function must return a new genome.
+func HillClimb(neighbor func(g evo.Genome) evo.Genome) LocalSearch {
+	return LocalSearchFunc(func(g evo.Genome, budget int) evo.Genome {
+		for i := 0; i < budget; i++ {
+			if n := neighbor(g); g.Fitness() <= n.Fitness() {
+				g = n
+			}
+		}
+		return g
+	})
+}
+
+// A Mode determines how the result of a local search is inherited.
+type Mode int
+
+const (
+	// Lamarckian search replaces the child with the improved genome, so the
+	// improvements are inherited by its offspring.
+	Lamarckian Mode = iota
+
+	// Baldwinian search keeps the genes of the child but gives it the fitness
+	// of the improved genome, so only the capacity to improve is inherited.
+	// The search must not modify the child.
+	Baldwinian
+)
+
+// Memetic configures the application of a local search to offspring.
+type Memetic struct {
+	Search LocalSearch
+	Rate   float64 // the probability of searching from each child
+	Budget int     // the budget of each search
+	Mode   Mode
+
+	// If non-nil, Scheduled gives the probability of searching instead of
+	// Rate, e.g. to search more often as the run converges.
+	Scheduled *Rate
+}
+
+// Apply searches from the child with probability m.Rate, or m.Scheduled, and
+// returns the genome to use in its place. In Baldwinian mode, the result is a
+// *Learned genome wrapping the child.
+func (m Memetic) Apply(child evo.Genome) evo.Genome {
+	rate := m.Rate
+	if m.Scheduled != nil {
+		rate = m.Scheduled.Value()
+	}
+	if rate <= rand.Float64() {
+		return child
+	}
+	improved := m.Search.Search(child, m.Budget)
+	if m.Mode == Baldwinian {
+		return &Learned{Genome: child, Improved: improved}
+	}
+	return improved
+}
+
+// Wrap returns an EvolveFn which applies the local search to the genomes
+// returned by body. Since body may return the current genome, e.g. when a
+// child is rejected, surviving genomes may be searched again in later
+// iterations. Pipelines should use Pipeline.Improve instead, which searches
+// from each child before the replacement step.
+func (m Memetic) Wrap(body evo.EvolveFn) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		return m.Apply(body(current, suitors))
+	}
+}
+
+// A Learned genome is a genome with the fitness of a genome found by local
+// search from it, as produced by Baldwinian search.
+type Learned struct {
+	evo.Genome            // the genes
+	Improved   evo.Genome // the result of the search, which gives the fitness
+}
+
+// Fitness returns the fitness of the improved genome.
+func (l *Learned) Fitness() float64 {
+	return l.Improved.Fitness()
+}
+
+// Unwrap returns the genes of a Learned genome, or g itself for other genomes.
+// Crossovers and mutations of pipelines using Baldwinian search should unwrap
+// the parents.
+func Unwrap(g evo.Genome) evo.Genome {
+	if l, ok := g.(*Learned); ok {
+		return l.Genome
+	}
+	return g
+}
+
+// Improve adds a local search to the pipeline, which is applied to each child
+// after mutation and before the replacement step.
+func (p *Pipeline) Improve(m Memetic) *Pipeline {
+	p.improve = &m
+	return p
+}
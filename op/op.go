@@ -0,0 +1,287 @@
+// Package op assembles variation operators into evolution loops.
+//
+// Most evolutionary algorithms share the same loop body: select parents from
+// the suitors, recombine them into a child, mutate the child, and decide
+// whether the child replaces the current genome. A Pipeline builds an
+// evo.EvolveFn from one operator for each of those steps:
+//
+//	body := op.New(op.Tournament(2)).
+//		Cross(op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome {
+//			child := &tour{gene: make([]int, dim)}
+//			perm.PMX(child.gene, mom.(*tour).gene, dad.(*tour).gene)
+//			return child
+//		})).
+//		Mutate(op.MutationFunc(func(child evo.Genome) {
+//			perm.RandSwap(child.(*tour).gene)
+//		})).
+//		Replace(op.IfBetter).
+//		EvolveFn()
+//	pop.Evolve(seed, body)
+//
+// Crossover and mutation operators may return a Feedback for each child they
+// help create. The pipeline calls the feedback with the Outcome of the child
+// once the replacement step has decided its fate, allowing operators to adapt
+// to their success. AdaptiveCrossover and AdaptiveMutation use feedback to
+// choose among several operators with a multi-armed bandit, favoring those
+// whose children do well.
+//
+// Genomes which are evo.Descendants carry their provenance through the
+// pipeline: each child records its parents and the names of the operators which
+// created it, given by NamedCrossover and NamedMutation. Adaptive operators
+// record the name of the operator they chose.
+//
+// Pipeline.Check verifies invariants of the children after each operator, and
+// reports the operator which broke them along with the parents, to debug
+// custom operators.
+//
+// Memetic algorithms hybridize evolution with local search. Pipeline.Improve
+// applies a LocalSearch to children before the replacement step, in either
+// Lamarckian or Baldwinian mode; Memetic.Wrap does the same for hand-written
+// EvolveFns.
+//
+// Static operator rates are rarely best for a whole run. A Rate combines a
+// Schedule, such as Linear or Exponential decay, with a Clock counting
+// evaluations or generations, and can be queried from any EvolveFn; Maybe
+// applies a mutation with the probability of a rate.
+package op
+
+import (
+	"math/rand"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Selection chooses a parent from the suitors.
+type Selection interface {
+	Select(suitors []evo.Genome) evo.Genome
+}
+
+// A Crossover creates a new child from two parents. The feedback may be nil.
+type Crossover interface {
+	Cross(mom, dad evo.Genome) (child evo.Genome, fb Feedback)
+}
+
+// A Mutation modifies a new child in place. The feedback may be nil.
+type Mutation interface {
+	Mutate(child evo.Genome) Feedback
+}
+
+// A Replacement decides whether a child replaces the current genome.
+type Replacement interface {
+	Replace(current, child evo.Genome) bool
+}
+
+// A Feedback receives the outcome of a child.
+type Feedback func(Outcome)
+
+// An Outcome describes the fate of a child.
+type Outcome struct {
+	Current  evo.Genome // the genome the child competed to replace
+	Mom, Dad evo.Genome // the parents
+	Child    evo.Genome // the child, after mutation
+	Survived bool       // whether the child replaced the current genome
+}
+
+// Improvement returns the fitness of the child less that of the current genome.
+func (o Outcome) Improvement() float64 {
+	return o.Child.Fitness() - o.Current.Fitness()
+}
+
+// Provenance returns the provenance of the child, if it is an evo.Descendant.
+func (o Outcome) Provenance() (p evo.Provenance, ok bool) {
+	d, ok := o.Child.(evo.Descendant)
+	if !ok {
+		return p, false
+	}
+	return d.Provenance(), true
+}
+
+// SelectionFunc adapts a function to a Selection, e.g.
+// op.SelectionFunc(sel.BinaryTournament).
+type SelectionFunc func(suitors ...evo.Genome) evo.Genome
+
+// Select calls f(suitors...).
+func (f SelectionFunc) Select(suitors []evo.Genome) evo.Genome {
+	return f(suitors...)
+}
+
+// CrossoverFunc adapts a function to a Crossover without feedback.
+type CrossoverFunc func(mom, dad evo.Genome) evo.Genome
+
+// Cross calls f(mom, dad).
+func (f CrossoverFunc) Cross(mom, dad evo.Genome) (evo.Genome, Feedback) {
+	return f(mom, dad), nil
+}
+
+// MutationFunc adapts a function to a Mutation without feedback.
+type MutationFunc func(child evo.Genome)
+
+// Mutate calls f(child).
+func (f MutationFunc) Mutate(child evo.Genome) Feedback {
+	f(child)
+	return nil
+}
+
+// ReplacementFunc adapts a function to a Replacement.
+type ReplacementFunc func(current, child evo.Genome) bool
+
+// Replace calls f(current, child).
+func (f ReplacementFunc) Replace(current, child evo.Genome) bool {
+	return f(current, child)
+}
+
+// NamedCrossover gives a name to a crossover. The name is recorded in the
+// provenance of the children it creates in a pipeline.
+func NamedCrossover(name string, x Crossover) Crossover {
+	return namedCross{name, x}
+}
+
+type namedCross struct {
+	name string
+	Crossover
+}
+
+func (x namedCross) Name() string { return x.name }
+
+// NamedMutation gives a name to a mutation. The name is recorded in the
+// provenance of the children it mutates in a pipeline.
+func NamedMutation(name string, m Mutation) Mutation {
+	return namedMutate{name, m}
+}
+
+type namedMutate struct {
+	name string
+	Mutation
+}
+
+func (m namedMutate) Name() string { return m.name }
+
+// nameOf returns the name of an operator, or "" if it has none.
+func nameOf(op interface{}) string {
+	if n, ok := op.(interface{ Name() string }); ok {
+		return n.Name()
+	}
+	return ""
+}
+
+// Uniform selects a suitor uniformly at random.
+var Uniform Selection = SelectionFunc(func(suitors ...evo.Genome) evo.Genome {
+	return suitors[rand.Intn(len(suitors))]
+})
+
+// Tournament returns a selection which holds a tournament between k suitors
+// chosen at random, with replacement, and selects the most fit.
+func Tournament(k int) Selection {
+	if k < 1 {
+		panic("tournament size must be positive")
+	}
+	return SelectionFunc(func(suitors ...evo.Genome) evo.Genome {
+		best := suitors[rand.Intn(len(suitors))]
+		for i := 1; i < k; i++ {
+			if g := suitors[rand.Intn(len(suitors))]; best.Fitness() < g.Fitness() {
+				best = g
+			}
+		}
+		return best
+	})
+}
+
+// Always is a replacement where the child always replaces the current genome.
+var Always Replacement = ReplacementFunc(func(current, child evo.Genome) bool {
+	return true
+})
+
+// IfBetter is a replacement where the child replaces the current genome if it
+// is at least as fit.
+var IfBetter Replacement = ReplacementFunc(func(current, child evo.Genome) bool {
+	return current.Fitness() <= child.Fitness()
+})
+
+// A Pipeline assembles operators into an EvolveFn. Pipelines are built by
+// chaining methods on the result of New, and must not be modified once
+// EvolveFn has been called.
+type Pipeline struct {
+	sel     Selection
+	cross   Crossover
+	muts    []Mutation
+	improve *Memetic
+	repl    Replacement
+	invs    []Invariant
+}
+
+// New starts a pipeline which selects both parents with the given selection.
+func New(sel Selection) *Pipeline {
+	return &Pipeline{sel: sel, repl: Always}
+}
+
+// Cross sets the crossover of the pipeline. A crossover is required.
+func (p *Pipeline) Cross(x Crossover) *Pipeline {
+	p.cross = x
+	return p
+}
+
+// Mutate appends mutations to the pipeline, which are applied to each child in
+// order.
+func (p *Pipeline) Mutate(muts ...Mutation) *Pipeline {
+	p.muts = append(p.muts, muts...)
+	return p
+}
+
+// Replace sets the replacement of the pipeline. The default is Always.
+func (p *Pipeline) Replace(r Replacement) *Pipeline {
+	p.repl = r
+	return p
+}
+
+// EvolveFn returns the body of the evolution loop. It panics if the pipeline
+// has no crossover.
+func (p *Pipeline) EvolveFn() evo.EvolveFn {
+	if p.cross == nil {
+		panic("pipeline without crossover")
+	}
+	sel, cross, muts, improve, repl := p.sel, p.cross, p.muts, p.improve, p.repl
+	invs := p.invs
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		var fbs []Feedback
+		mom := sel.Select(suitors)
+		dad := sel.Select(suitors)
+		child, fb := cross.Cross(mom, dad)
+		evo.Derive(child, nameOf(cross), mom, dad)
+		check(invs, cross, mom, dad, child)
+		if fb != nil {
+			fbs = append(fbs, fb)
+		}
+		for _, m := range muts {
+			if fb := m.Mutate(child); fb != nil {
+				fbs = append(fbs, fb)
+			}
+			if name := nameOf(m); name != "" {
+				evo.Derive(child, name)
+			}
+			check(invs, m, mom, dad, child)
+		}
+		if improve != nil {
+			child = improve.Apply(child)
+			check(invs, improve.Search, mom, dad, child)
+		}
+
+		survived := repl.Replace(current, child)
+		if len(fbs) > 0 {
+			o := Outcome{
+				Current:  current,
+				Mom:      mom,
+				Dad:      dad,
+				Child:    child,
+				Survived: survived,
+			}
+			for _, fb := range fbs {
+				fb(o)
+			}
+		}
+
+		if survived {
+			return child
+		}
+		return current
+	}
+}
@@ -0,0 +1,325 @@
+package op_test
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/op"
+	"github.com/cbarrick/evo/perm"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/sel"
+)
+
+const dim = 12
+
+// fixed is a permutation whose fitness is its number of fixed points.
+type fixed struct {
+	evo.Cache
+	gene []int
+}
+
+func (f *fixed) Fitness() float64 {
+	return f.Cache.Fitness(func() (fit float64) {
+		for i, x := range f.gene {
+			if i == x {
+				fit++
+			}
+		}
+		return fit
+	})
+}
+
+type num float64
+
+func (n num) Fitness() float64 { return float64(n) }
+
+func TestPipeline(t *testing.T) {
+	body := op.New(op.Tournament(2)).
+		Cross(op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome {
+			child := &fixed{gene: make([]int, dim)}
+			perm.PMX(child.gene, mom.(*fixed).gene, dad.(*fixed).gene)
+			return child
+		})).
+		Mutate(op.MutationFunc(func(child evo.Genome) {
+			perm.RandSwap(child.(*fixed).gene)
+		})).
+		Replace(op.IfBetter).
+		EvolveFn()
+
+	seed := make([]evo.Genome, 32)
+	for i := range seed {
+		seed[i] = &fixed{gene: perm.New(dim)}
+	}
+	pop := new(gen.Population)
+	pop.Evolve(seed, body)
+	pop.Poll(0, func() bool { return pop.Fitness() == dim })
+	pop.Poll(5*time.Second, func() bool { return true })
+	pop.Wait()
+	if pop.Fitness() != dim {
+		t.Errorf("best fitness %v, want %v", pop.Fitness(), dim)
+	}
+}
+
+func TestFeedback(t *testing.T) {
+	var outcomes []op.Outcome
+	record := func(o op.Outcome) { outcomes = append(outcomes, o) }
+	var mutations atomic.Int64
+
+	body := op.New(op.SelectionFunc(sel.Tournament)).
+		Cross(crossover(record)).
+		Mutate(
+			op.MutationFunc(func(evo.Genome) { mutations.Add(1) }),
+			op.MutationFunc(func(evo.Genome) { mutations.Add(1) }),
+		).
+		Replace(op.IfBetter).
+		EvolveFn()
+
+	// the child is the mean of the best suitor with itself
+	if got := body(num(5), []evo.Genome{num(1), num(3)}); got != num(5) {
+		t.Errorf("worse child replaced the current genome: %v", got)
+	}
+	if got := body(num(2), []evo.Genome{num(1), num(3)}); got != num(3) {
+		t.Errorf("better child was rejected: %v", got)
+	}
+	if mutations.Load() != 4 {
+		t.Error("mutations not applied")
+	}
+	if len(outcomes) != 2 {
+		t.Fatal("feedback not called")
+	}
+	if outcomes[0].Survived || outcomes[0].Improvement() != -2 || outcomes[0].Mom != num(3) {
+		t.Errorf("bad outcome %+v", outcomes[0])
+	}
+	if !outcomes[1].Survived || outcomes[1].Improvement() != 1 {
+		t.Errorf("bad outcome %+v", outcomes[1])
+	}
+
+	// Always ignores fitness
+	body = op.New(op.Uniform).Cross(crossover(nil)).EvolveFn()
+	if got := body(num(5), []evo.Genome{num(1)}); got != num(1) {
+		t.Errorf("child was rejected: %v", got)
+	}
+}
+
+// lineal is a number with a lineage.
+type lineal struct {
+	evo.Lineage
+	num
+}
+
+func TestProvenance(t *testing.T) {
+	cross := op.NamedCrossover("mean", op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome {
+		return &lineal{num: (mom.(*lineal).num + dad.(*lineal).num) / 2}
+	}))
+	bandit := op.NewUCB(1, 1)
+	var outcome op.Outcome
+	body := op.New(op.Uniform).
+		Cross(cross).
+		Mutate(
+			op.MutationFunc(func(evo.Genome) {}),
+			op.AdaptiveMutation(bandit, func(o op.Outcome) float64 {
+				outcome = o
+				return 0
+			}, op.NamedMutation("noop", op.MutationFunc(func(evo.Genome) {}))),
+		).
+		EvolveFn()
+
+	parent := &lineal{num: 1}
+	child := body(&lineal{}, []evo.Genome{parent})
+	p, ok := outcome.Provenance()
+	if !ok || child != outcome.Child {
+		t.Fatal("no provenance")
+	}
+	id := parent.Provenance().ID
+	if p.Operator != "mean+noop" || len(p.Parents) != 2 || p.Parents[0] != id || p.Parents[1] != id {
+		t.Errorf("bad provenance %+v", p)
+	}
+}
+
+// crossover averages the parents, reporting outcomes to the feedback.
+type crossover op.Feedback
+
+func (fb crossover) Cross(mom, dad evo.Genome) (evo.Genome, op.Feedback) {
+	return (mom.(num) + dad.(num)) / 2, op.Feedback(fb)
+}
+
+// adaptive.go
+// -------------------------
+
+func TestBandits(t *testing.T) {
+	for name, b := range map[string]op.Bandit{
+		"pm":  op.NewProbabilityMatching(3, 0.05, 0.3),
+		"ucb": op.NewUCB(3, 0.1),
+	} {
+		// the arm i gives reward i/2
+		counts := make([]int, 3)
+		for i := 0; i < 2000; i++ {
+			arm := b.Choose()
+			counts[arm]++
+			b.Reward(arm, float64(arm)/2)
+		}
+		if counts[2] < counts[1] || counts[1] < counts[0] || counts[2] < 1000 {
+			t.Errorf("%s: bad pulls %v", name, counts)
+		}
+		if counts[0] == 0 {
+			t.Errorf("%s: arm never explored", name)
+		}
+	}
+
+	pm := op.NewProbabilityMatching(4, 0.1, 1)
+	pm.Reward(0, 0)
+	pm.Reward(1, 0)
+	pm.Reward(2, 0)
+	if p := pm.Probabilities(); math.Abs(p[0]-0.1) > 1e-9 || math.Abs(p[3]-0.7) > 1e-9 {
+		t.Errorf("bad probabilities %v", p)
+	}
+}
+
+// marked is a genome recording which mutation was applied.
+type marked struct {
+	num
+	by int
+}
+
+func TestAdaptive(t *testing.T) {
+	bandit := op.NewUCB(2, 0.5)
+	mark := func(by int) op.Mutation {
+		return op.MutationFunc(func(child evo.Genome) { child.(*marked).by = by })
+	}
+
+	// children of the second mutation always survive, the first never do
+	body := op.New(op.Uniform).
+		Cross(op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome { return new(marked) })).
+		Mutate(op.AdaptiveMutation(bandit, op.Survival, mark(0), mark(1))).
+		Replace(op.ReplacementFunc(func(current, child evo.Genome) bool {
+			return child.(*marked).by == 1
+		})).
+		EvolveFn()
+	var pulls [2]int
+	for i := 0; i < 200; i++ {
+		child := body(new(marked), []evo.Genome{num(0)}).(*marked)
+		pulls[child.by]++
+	}
+	if m := bandit.Means(); m[0] != 0 || m[1] != 1 || pulls[1] < 150 {
+		t.Errorf("bad adaptation: means %v, pulls %v", m, pulls)
+	}
+}
+
+// memetic.go
+// -------------------------
+
+// climb is a local search on nums which adds 1 per step of the budget.
+var climb = op.HillClimb(func(g evo.Genome) evo.Genome {
+	return g.(num) + 1
+})
+
+func TestMemetic(t *testing.T) {
+	lamarck := op.Memetic{Search: climb, Rate: 1, Budget: 3}
+	if got := lamarck.Apply(num(1)); got != num(4) {
+		t.Errorf("lamarckian search returned %v", got)
+	}
+
+	baldwin := op.Memetic{Search: climb, Rate: 1, Budget: 3, Mode: op.Baldwinian}
+	got := baldwin.Apply(num(1))
+	if got.Fitness() != 4 || op.Unwrap(got) != num(1) || op.Unwrap(num(2)) != num(2) {
+		t.Errorf("baldwinian search returned %v", got)
+	}
+
+	never := op.Memetic{Search: climb, Rate: 0, Budget: 3}
+	if got := never.Apply(num(1)); got != num(1) {
+		t.Errorf("search applied with rate 0: %v", got)
+	}
+
+	body := lamarck.Wrap(func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	if got := body(num(0), nil); got != num(3) {
+		t.Errorf("wrapped body returned %v", got)
+	}
+
+	// the child is searched before the replacement
+	body = op.New(op.Uniform).
+		Cross(op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome { return mom })).
+		Improve(lamarck).
+		Replace(op.ReplacementFunc(func(current, child evo.Genome) bool {
+			return child == num(current.(num)+3)
+		})).
+		EvolveFn()
+	if got := body(num(5), []evo.Genome{num(5)}); got != num(8) {
+		t.Errorf("pipeline returned %v", got)
+	}
+}
+
+// schedule.go
+// -------------------------
+
+func TestSchedules(t *testing.T) {
+	near := func(x, y float64) bool { return math.Abs(x-y) < 1e-9 }
+	lin := op.Linear(1, 0, 10)
+	if !near(lin(0), 1) || !near(lin(5), 0.5) || !near(lin(20), 0) {
+		t.Error("linear")
+	}
+	exp := op.Exponential(1, 0.01, 2)
+	if !near(exp(0), 1) || !near(exp(1), 0.1) || !near(exp(3), 0.01) {
+		t.Error("exponential")
+	}
+	step := op.Step(1, 0.5, 10)
+	if !near(step(9), 1) || !near(step(10), 0.5) || !near(step(25), 0.25) {
+		t.Error("step")
+	}
+}
+
+func TestRate(t *testing.T) {
+	var gen int
+	clock := op.Generations(generationFunc(func() int { return gen }))
+	rate := op.Rate{Schedule: op.Linear(1, 0, 4), Clock: clock}
+	var n int
+	mutate := op.Maybe(rate, op.MutationFunc(func(evo.Genome) { n++ }))
+	mutate.Mutate(nil)
+	gen = 4
+	mutate.Mutate(nil)
+	if n != 1 {
+		t.Fail()
+	}
+}
+
+type generationFunc func() int
+
+func (f generationFunc) Generation() int { return f() }
+
+// check.go
+// -------------------------
+
+func TestCheck(t *testing.T) {
+	valid := func(g evo.Genome) error { return perm.Check(g.(*fixed).gene) }
+	body := op.New(op.Uniform).
+		Cross(op.CrossoverFunc(func(mom, dad evo.Genome) evo.Genome {
+			child := &fixed{gene: make([]int, dim)}
+			perm.OrderX(child.gene, mom.(*fixed).gene, dad.(*fixed).gene)
+			return child
+		})).
+		Mutate(
+			op.MutationFunc(func(child evo.Genome) { perm.RandSwap(child.(*fixed).gene) }),
+			op.NamedMutation("dup", op.MutationFunc(func(child evo.Genome) {
+				gene := child.(*fixed).gene
+				gene[0] = gene[1]
+			})),
+		).
+		Check(valid).
+		EvolveFn()
+
+	parent := &fixed{gene: perm.New(dim)}
+	defer func() {
+		v, ok := recover().(*op.Violation)
+		if !ok {
+			t.Fatal("no violation")
+		}
+		if v.Operator != "dup" || v.Mom != parent || v.Dad != parent || valid(v.Child) == nil {
+			t.Errorf("bad violation %v", v)
+		}
+	}()
+	body(parent, []evo.Genome{parent})
+}
@@ -0,0 +1,103 @@
+package op
+
+import (
+	"math"
+	"math/rand"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Schedule gives the value of a parameter, such as a mutation or crossover
+// rate, as a function of the progress t of the run. Progress is measured by a
+// Clock, in evaluations or generations.
+type Schedule func(t float64) float64
+
+// Constant returns a schedule which is always v.
+func Constant(v float64) Schedule {
+	return func(float64) float64 { return v }
+}
+
+// Linear returns a schedule which moves linearly from one value to another over
+// the given span of progress, then stays at the final value.
+func Linear(from, to, span float64) Schedule {
+	return func(t float64) float64 {
+		if span <= t {
+			return to
+		}
+		return from + (to-from)*t/span
+	}
+}
+
+// Exponential returns a schedule which moves geometrically from one value to
+// another over the given span of progress, then stays at the final value. Both
+// values must be positive. Exponential decay is the usual choice for rates
+// which should shrink by orders of magnitude.
+func Exponential(from, to, span float64) Schedule {
+	return func(t float64) float64 {
+		if span <= t {
+			return to
+		}
+		return from * math.Pow(to/from, t/span)
+	}
+}
+
+// Step returns a schedule which starts at a value and is multiplied by the
+// factor after every period of progress.
+func Step(from, factor, period float64) Schedule {
+	return func(t float64) float64 {
+		return from * math.Pow(factor, math.Floor(t/period))
+	}
+}
+
+// A Clock measures the progress of a run.
+type Clock func() float64
+
+// Evaluations returns a clock counting the fitness evaluations of a population
+// since the clock was created, as by its Throughput, e.g. a gen.Population or a
+// graph.Graph.
+func Evaluations(pop interface{ Throughput() evo.Throughput }) Clock {
+	start := pop.Throughput().Evaluations
+	return func() float64 {
+		return float64(pop.Throughput().Evaluations - start)
+	}
+}
+
+// Generations returns a clock counting the generations of a population, e.g. a
+// gen.Population or a graph.Graph.
+func Generations(pop interface{ Generation() int }) Clock {
+	return func() float64 {
+		return float64(pop.Generation())
+	}
+}
+
+// A Rate is a schedule driven by a clock. Rates are safe for concurrent use
+// when the schedule and clock are, so a single rate can be queried by every
+// call of an EvolveFn.
+type Rate struct {
+	Schedule Schedule
+	Clock    Clock
+}
+
+// Value returns the current value of the rate.
+func (r Rate) Value() float64 {
+	return r.Schedule(r.Clock())
+}
+
+// Maybe returns a mutation which applies m to a child with the current
+// probability of the rate, e.g. a decaying mutation rate. The feedback of m is
+// passed on when it is applied.
+func Maybe(r Rate, m Mutation) Mutation {
+	return maybe{r, m}
+}
+
+type maybe struct {
+	rate Rate
+	m    Mutation
+}
+
+func (x maybe) Mutate(child evo.Genome) Feedback {
+	if rand.Float64() < x.rate.Value() {
+		return x.m.Mutate(child)
+	}
+	return nil
+}
@@ -0,0 +1,38 @@
+package evo
+
+import (
+	"fmt"
+	"runtime/debug"
+)
+
+// A PanicError records a panic recovered from an EvolveFn, or from the fitness
+// function of the genome it returned.
+type PanicError struct {
+	Value interface{} // the value passed to panic
+	Stack []byte      // the stack of the goroutine at the time of the panic
+}
+
+// Error returns the panic value and the stack of the panic.
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("evo: panic in EvolveFn: %v\n\n%s", e.Value, e.Stack)
+}
+
+// SafeEvolve calls the body and evaluates the fitness of the child it returns,
+// recovering any panic raised along the way. If a panic is recovered, the child
+// is nil and the error is a *PanicError. A nil child is returned unchanged, so
+// that populations treat it the same whether or not they recover panics, e.g.
+// gen.Population skips nil offspring. Populations use SafeEvolve to isolate a
+// faulty EvolveFn from the rest of the evolution.
+func SafeEvolve(body EvolveFn, current Genome, suitors []Genome) (child Genome, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			child = nil
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+	child = body(current, suitors)
+	if child != nil {
+		child.Fitness()
+	}
+	return child, nil
+}
@@ -0,0 +1,46 @@
+package evo_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+type faulty float64
+
+func (f faulty) Fitness() float64 {
+	if f < 0 {
+		panic("negative fitness")
+	}
+	return float64(f)
+}
+
+func TestSafeEvolve(t *testing.T) {
+	child, err := evo.SafeEvolve(func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current.(faulty) + 1
+	}, faulty(1), nil)
+	if err != nil || child != faulty(2) {
+		t.Error(child, err)
+	}
+
+	child, err = evo.SafeEvolve(func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		return suitors[len(suitors)]
+	}, faulty(1), nil)
+	if _, ok := err.(*evo.PanicError); !ok || child != nil {
+		t.Error(child, err)
+	}
+
+	child, err = evo.SafeEvolve(func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return faulty(-1)
+	}, faulty(1), nil)
+	if perr, ok := err.(*evo.PanicError); !ok || perr.Value != "negative fitness" || child != nil {
+		t.Error(child, err)
+	}
+
+	child, err = evo.SafeEvolve(func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return nil
+	}, faulty(1), nil)
+	if err != nil || child != nil {
+		t.Error(child, err)
+	}
+}
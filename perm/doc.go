@@ -3,4 +3,9 @@
 // The crossover operators each take 3 integer slices: the "mother" and "father"
 // slices provide the genetic material to be filled into the "child" slice.
 // This requires the child slice be allocated by the caller.
+//
+// Permutations read as tours, e.g. for the traveling salesman problem, are
+// evaluated against a TourCost. The tour helpers, the 2-opt local search and
+// the nearest neighbor initializer, work with any cost, including the TSPLIB
+// distances of the tsplib package.
 package perm
@@ -0,0 +1,89 @@
+package perm
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// A Mask marks positions of a permutation which are locked in place. Locked
+// positions must hold the same value in every genome, e.g. a depot fixed at
+// index 0 of a route. The operators of this package can be applied around a
+// mask with the Cross and Mutate methods, which only ever move the values at
+// unlocked positions.
+type Mask []bool
+
+// Lock returns a mask of length n with the given positions locked.
+func Lock(n int, locked ...int) Mask {
+	m := make(Mask, n)
+	for _, i := range locked {
+		m[i] = true
+	}
+	return m
+}
+
+// free returns the unlocked positions of the mask.
+func (m Mask) free() (idx []int) {
+	idx = make([]int, 0, len(m))
+	for i := range m {
+		if !m[i] {
+			idx = append(idx, i)
+		}
+	}
+	return idx
+}
+
+// Shuffle randomly permutes the values at the unlocked positions of the gene.
+func (m Mask) Shuffle(gene []int) {
+	free := m.free()
+	vals := make([]int, len(free))
+	for i, j := range free {
+		vals[i] = gene[j]
+	}
+	for i, k := range rand.Perm(len(free)) {
+		gene[free[i]] = vals[k]
+	}
+}
+
+// Cross applies a crossover operator of this package, e.g. PMX, around the
+// mask. The unlocked values of the parents are relabeled into a permutation of
+// [0,n) so that any operator can be used, and the child inherits the locked
+// values of the mother.
+func (m Mask) Cross(cross func(child, mom, dad []int), child, mom, dad []int) {
+	free := m.free()
+	size := len(free)
+
+	// the unlocked values, sorted, map ranks back to values
+	vals := make([]int, size)
+	for i, j := range free {
+		vals[i] = mom[j]
+	}
+	sort.Ints(vals)
+
+	subc := make([]int, size)
+	subm := make([]int, size)
+	subd := make([]int, size)
+	for i, j := range free {
+		subm[i] = sort.SearchInts(vals, mom[j])
+		subd[i] = sort.SearchInts(vals, dad[j])
+	}
+	cross(subc, subm, subd)
+
+	copy(child, mom)
+	for i, j := range free {
+		child[j] = vals[subc[i]]
+	}
+}
+
+// Mutate applies a mutation operator of this package, e.g. RandSwap, to the
+// unlocked positions of the gene.
+func (m Mask) Mutate(mutate func(gene []int), gene []int) {
+	free := m.free()
+	sub := make([]int, len(free))
+	for i, j := range free {
+		sub[i] = gene[j]
+	}
+	mutate(sub)
+	for i, j := range free {
+		gene[j] = sub[i]
+	}
+}
@@ -1,6 +1,7 @@
 package perm
 
 import (
+	"fmt"
 	"math/rand"
 )
 
@@ -64,10 +65,38 @@ func Rotate(slice []int, n int) {
 // Validate panics if the argument is not a permutation.
 // This can be useful when testing custom operators.
 func Validate(slice []int) {
-	n := len(slice)
-	for i := 0; i < n; i++ {
-		if Search(slice, i) == -1 {
-			panic("invalid permutation")
+	if Check(slice) != nil {
+		panic("invalid permutation")
+	}
+}
+
+// Check returns an error if the argument is not a permutation of [0,n), naming
+// the first value which is out of range or repeated. It can be used as an
+// invariant of a pipeline, see op.Pipeline.Check.
+func Check(slice []int) error {
+	seen := make([]bool, len(slice))
+	for i, x := range slice {
+		switch {
+		case x < 0 || len(slice) <= x:
+			return fmt.Errorf("perm: value %d at %d is out of range", x, i)
+		case seen[x]:
+			return fmt.Errorf("perm: value %d at %d is repeated", x, i)
+		}
+		seen[x] = true
+	}
+	return nil
+}
+
+// Hash returns the FNV-1a hash of a slice, e.g. for memoizing the fitness of
+// permutation genomes with evo.Memo.
+func Hash(slice []int) uint64 {
+	const offset, prime = 14695981039346656037, 1099511628211
+	h := uint64(offset)
+	for _, x := range slice {
+		for i := 0; i < 64; i += 8 {
+			h ^= uint64(x>>i) & 0xff
+			h *= prime
 		}
 	}
+	return h
 }
@@ -1,6 +1,7 @@
 package perm_test
 
 import (
+	"math"
 	"math/rand"
 	"testing"
 
@@ -59,6 +60,37 @@ func TestEdgeX(t *testing.T) {
 	validate(t, child)
 }
 
+// mask.go
+// -------------------------
+
+func TestMask(t *testing.T) {
+	mask := perm.Lock(8, 0, 5)
+	mom := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	dad := []int{0, 1, 2, 3, 4, 5, 6, 7}
+	mask.Shuffle(mom)
+	mask.Shuffle(dad)
+	ops := []func(child, mom, dad []int){
+		perm.OrderX,
+		perm.PMX,
+		perm.CycleX,
+		perm.EdgeX,
+	}
+	for _, op := range ops {
+		child := make([]int, 8)
+		mask.Cross(op, child, mom, dad)
+		validate(t, child)
+		if child[0] != 0 || child[5] != 5 {
+			t.Fail()
+		}
+	}
+	mask.Mutate(perm.RandInvert, mom)
+	mask.Mutate(perm.RandSwap, mom)
+	validate(t, mom)
+	if mom[0] != 0 || mom[5] != 5 {
+		t.Fail()
+	}
+}
+
 // mutation.go
 // -------------------------
 
@@ -118,6 +150,82 @@ func TestRandSwap(t *testing.T) {
 	}
 }
 
+// tour.go
+// -------------------------
+
+// randomCost returns random costs between n elements, symmetric if sym is true.
+func randomCost(n int, sym bool) perm.Matrix {
+	m := make(perm.Matrix, n)
+	for i := range m {
+		m[i] = make([]float64, n)
+		for j := range m[i] {
+			m[i][j] = rand.Float64()
+		}
+	}
+	if sym {
+		for i := range m {
+			for j := 0; j < i; j++ {
+				m[i][j] = m[j][i]
+			}
+		}
+	}
+	return m
+}
+
+func TestSwapDelta(t *testing.T) {
+	c := randomCost(8, false)
+	tour := rand.Perm(8)
+	for i := range tour {
+		for j := range tour {
+			before := perm.TourLength(c, tour)
+			d := perm.SwapDelta(c, tour, i, j)
+			tour[i], tour[j] = tour[j], tour[i]
+			if after := perm.TourLength(c, tour); math.Abs(before+d-after) > 1e-9 {
+				t.Errorf("swap %d, %d: delta %v, want %v", i, j, d, after-before)
+			}
+			tour[i], tour[j] = tour[j], tour[i]
+		}
+	}
+}
+
+func TestTwoOptDelta(t *testing.T) {
+	c := randomCost(8, true)
+	tour := rand.Perm(8)
+	for i := 0; i < 8; i++ {
+		for j := i + 1; j < 8; j++ {
+			before := perm.TourLength(c, tour)
+			d := perm.TwoOptDelta(c, tour, i, j)
+			perm.Reverse(tour[i+1 : j+1])
+			if after := perm.TourLength(c, tour); math.Abs(before+d-after) > 1e-9 {
+				t.Errorf("2-opt %d, %d: delta %v, want %v", i, j, d, after-before)
+			}
+			perm.Reverse(tour[i+1 : j+1])
+		}
+	}
+}
+
+func TestTwoOpt(t *testing.T) {
+	c := randomCost(20, true)
+	tour := perm.NearestNeighbor(c, 20, 3)
+	validate(t, tour)
+	if tour[0] != 3 {
+		t.Errorf("tour starts at %d, want 3", tour[0])
+	}
+	before := perm.TourLength(c, tour)
+	gain := perm.TwoOpt(c, tour)
+	validate(t, tour)
+	if after := perm.TourLength(c, tour); gain < 0 || math.Abs(before-gain-after) > 1e-9 {
+		t.Errorf("gain %v, want %v", gain, before-after)
+	}
+	for i := 0; i < 20-2; i++ {
+		for j := i + 2; j < 20; j++ {
+			if d := perm.TwoOptDelta(c, tour, i, j); d < -1e-9 && !(i == 0 && j == 19) {
+				t.Fatalf("tour is not 2-optimal at %d, %d", i, j)
+			}
+		}
+	}
+}
+
 // util.go
 // -------------------------
 
@@ -153,6 +261,14 @@ func TestReverse(t *testing.T) {
 	}
 }
 
+func TestHash(t *testing.T) {
+	a := []int{0, 1, 2, 3}
+	b := []int{0, 1, 3, 2}
+	if perm.Hash(a) != perm.Hash([]int{0, 1, 2, 3}) || perm.Hash(a) == perm.Hash(b) {
+		t.Fail()
+	}
+}
+
 func TestValidate(t *testing.T) {
 	defer func() {
 		if recover() == nil {
@@ -162,3 +278,12 @@ func TestValidate(t *testing.T) {
 	}()
 	perm.Validate([]int{0, 0, 1, 2})
 }
+
+func TestCheck(t *testing.T) {
+	if err := perm.Check([]int{2, 0, 1}); err != nil {
+		t.Error(err)
+	}
+	if perm.Check([]int{0, 3, 1}) == nil || perm.Check([]int{0, 1, 1}) == nil {
+		t.Error("invalid permutation passed the check")
+	}
+}
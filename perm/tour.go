@@ -0,0 +1,129 @@
+package perm
+
+import (
+	"math"
+	"slices"
+)
+
+// A TourCost gives the cost of travelling from one element to another when a
+// permutation is read as a closed tour, e.g. the distance between cities. The
+// tour helpers of this package depend only on the cost, so Euclidean,
+// geographic, and matrix costs can be swapped without touching the operators.
+// The tsplib package provides the costs of TSPLIB.
+type TourCost interface {
+	Cost(i, j int) float64
+}
+
+// A CostFunc adapts a function to a TourCost.
+type CostFunc func(i, j int) float64
+
+// Cost returns f(i, j).
+func (f CostFunc) Cost(i, j int) float64 {
+	return f(i, j)
+}
+
+// A Matrix is a TourCost given by a matrix, where the cost from i to j is
+// m[i][j]. The matrix need not be symmetric.
+type Matrix [][]float64
+
+// Cost returns m[i][j].
+func (m Matrix) Cost(i, j int) float64 {
+	return m[i][j]
+}
+
+// TourLength returns the total cost of a tour, including the return from the
+// last element to the first.
+func TourLength(c TourCost, tour []int) (length float64) {
+	for i := range tour {
+		length += c.Cost(tour[i], tour[(i+1)%len(tour)])
+	}
+	return length
+}
+
+// SwapDelta returns the change in the length of a tour if the elements at
+// positions i and j were swapped, in constant time. It holds for asymmetric
+// costs. The elements are swapped and restored, so the tour must not be read
+// concurrently.
+func SwapDelta(c TourCost, tour []int, i, j int) float64 {
+	n := len(tour)
+	if i == j {
+		return 0
+	}
+	if j < i {
+		i, j = j, i
+	}
+	// the positions where the edges leaving them may change
+	from := []int{(i + n - 1) % n, i, (j + n - 1) % n, j}
+	edges := func(tour []int) (sum float64) {
+		for x, k := range from {
+			if slices.Contains(from[:x], k) {
+				continue
+			}
+			sum += c.Cost(tour[k], tour[(k+1)%n])
+		}
+		return sum
+	}
+	before := edges(tour)
+	tour[i], tour[j] = tour[j], tour[i]
+	after := edges(tour)
+	tour[i], tour[j] = tour[j], tour[i]
+	return after - before
+}
+
+// TwoOptDelta returns the change in the length of a tour if the segment
+// tour[i+1:j+1] were reversed, replacing the edges leaving positions i and j,
+// in constant time. It requires i < j, and holds for symmetric costs only.
+func TwoOptDelta(c TourCost, tour []int, i, j int) float64 {
+	n := len(tour)
+	a, b := tour[i], tour[i+1]
+	y, z := tour[j], tour[(j+1)%n]
+	return c.Cost(a, y) + c.Cost(b, z) - c.Cost(a, b) - c.Cost(y, z)
+}
+
+// TwoOpt improves a tour in place by 2-opt local search: while reversing a
+// segment shortens the tour, the first such segment found is reversed. It
+// returns the decrease in the length of the tour. The costs must be symmetric.
+// Each pass is quadratic in the length of the tour.
+func TwoOpt(c TourCost, tour []int) (gain float64) {
+	const eps = 1e-9
+	n := len(tour)
+	for improved := true; improved; {
+		improved = false
+		for i := 0; i < n-2; i++ {
+			for j := i + 2; j < n; j++ {
+				if i == 0 && j == n-1 {
+					continue // the edges are adjacent
+				}
+				if d := TwoOptDelta(c, tour, i, j); d < -eps {
+					Reverse(tour[i+1 : j+1])
+					gain -= d
+					improved = true
+				}
+			}
+		}
+	}
+	return gain
+}
+
+// NearestNeighbor returns a tour of n elements built by the nearest neighbor
+// heuristic: starting from the given element, the tour repeatedly travels to
+// the cheapest element not yet visited. Nearest neighbor tours are typically
+// within 25% of optimal, which makes them good seeds for a population.
+func NearestNeighbor(c TourCost, n, start int) []int {
+	tour := make([]int, 0, n)
+	visited := make([]bool, n)
+	for cur := start; ; {
+		tour = append(tour, cur)
+		visited[cur] = true
+		if len(tour) == n {
+			return tour
+		}
+		next, min := -1, math.Inf(1)
+		for j := 0; j < n; j++ {
+			if !visited[j] && (next < 0 || c.Cost(cur, j) < min) {
+				next, min = j, c.Cost(cur, j)
+			}
+		}
+		cur = next
+	}
+}
@@ -0,0 +1,337 @@
+// Package plot records the fitness of a population over the course of a run
+// and renders convergence plots.
+//
+// A Recorder samples the best, mean, and worst fitness of a population against
+// the number of fitness evaluations performed. The samples can be rendered as
+// an SVG line chart once the run is over:
+//
+//	rec := plot.NewRecorder()
+//	rec.Watch(pop, 100*time.Millisecond)
+//	pop.Wait()
+//	plot.Save("convergence.svg", "Rastrigin", rec.Points())
+//
+// Heatmap renders a grid of values, such as the fitness map of a diffusion
+// population from graph.Graph.FitnessMap, as an SVG image.
+//
+// The SVG is written directly, so the package has no dependencies beyond the
+// standard library.
+package plot
+
+import (
+	"bufio"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Point is a sample of the fitness of a population.
+type Point struct {
+	Evaluations int     `json:"evaluations"` // evaluations performed before the sample
+	Seconds     float64 `json:"seconds"`     // wall time before the sample
+	Best        float64 `json:"best"`        // the maximum fitness
+	Mean        float64 `json:"mean"`        // the mean fitness
+	Worst       float64 `json:"worst"`       // the minimum fitness
+}
+
+// A Recorder collects a time-series of points. Recorders are safe for
+// concurrent use.
+type Recorder struct {
+	mu     sync.Mutex
+	start  time.Time
+	points []Point
+}
+
+// NewRecorder returns a recorder whose clock starts immediately.
+func NewRecorder() *Recorder {
+	return &Recorder{start: time.Now()}
+}
+
+// Record adds a point for the given number of evaluations and fitness
+// statistics.
+func (r *Recorder) Record(evals int, s evo.Stats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.points = append(r.points, Point{
+		Evaluations: evals,
+		Seconds:     time.Since(r.start).Seconds(),
+		Best:        s.Max(),
+		Mean:        s.Mean(),
+		Worst:       s.Min(),
+	})
+}
+
+// Watch records the statistics of a population at some frequency for the
+// duration of the current optimization. Evaluations are counted by the
+// Throughput of the population from the time Watch is called, or are 0 if the
+// population has no Throughput method; use Record directly to count them
+// otherwise.
+func (r *Recorder) Watch(pop evo.Population, freq time.Duration) {
+	evals := func() int {
+		if m, ok := pop.(interface{ Throughput() evo.Throughput }); ok {
+			return m.Throughput().Evaluations
+		}
+		return 0
+	}
+	base := evals()
+	pop.Poll(freq, func() bool {
+		r.Record(evals()-base, pop.Stats())
+		return false
+	})
+}
+
+// Points returns a copy of the points recorded so far.
+func (r *Recorder) Points() []Point {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Point(nil), r.points...)
+}
+
+// Save renders the points as an SVG file. See SVG.
+func Save(path, title string, points []Point) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = SVG(f, title, points)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// The geometry of the chart.
+const (
+	width  = 640
+	height = 400
+	left   = 80 // margins around the plotting area
+	right  = 20
+	top    = 40
+	bottom = 50
+)
+
+// The series drawn for each chart.
+var series = []struct {
+	name  string
+	color string
+	value func(Point) float64
+}{
+	{"best", "#1f77b4", func(p Point) float64 { return p.Best }},
+	{"mean", "#ff7f0e", func(p Point) float64 { return p.Mean }},
+	{"worst", "#2ca02c", func(p Point) float64 { return p.Worst }},
+}
+
+// SVG renders the best, mean, and worst fitness of the points against the
+// number of evaluations as an SVG line chart. Non-finite values are skipped.
+func SVG(w io.Writer, title string, points []Point) error {
+	if len(points) == 0 {
+		return errors.New("plot: no points")
+	}
+
+	// the extent of the data
+	xmax := 0.0
+	ymin, ymax := math.Inf(1), math.Inf(-1)
+	for _, p := range points {
+		xmax = math.Max(xmax, float64(p.Evaluations))
+		for _, s := range series {
+			if y := s.value(p); !math.IsInf(y, 0) && !math.IsNaN(y) {
+				ymin = math.Min(ymin, y)
+				ymax = math.Max(ymax, y)
+			}
+		}
+	}
+	if ymax < ymin {
+		return errors.New("plot: no finite values")
+	}
+	xticks, xmax := ticks(0, xmax)
+	yticks, _ := ticks(ymin, ymax)
+	ymin, ymax = math.Min(ymin, yticks[0]), math.Max(ymax, yticks[len(yticks)-1])
+	if ymin == ymax {
+		ymin, ymax = ymin-1, ymax+1
+	}
+
+	// maps data coordinates to the canvas
+	px := func(x float64) float64 {
+		return left + x/xmax*(width-left-right)
+	}
+	py := func(y float64) float64 {
+		return height - bottom - (y-ymin)/(ymax-ymin)*(height-top-bottom)
+	}
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="middle" font-size="16">%s</text>`+"\n", width/2, top/2+6, escape(title))
+
+	// grid and axes
+	for _, x := range xticks {
+		fmt.Fprintf(b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="#ddd"/>`+"\n", px(x), top, px(x), height-bottom)
+		fmt.Fprintf(b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", px(x), height-bottom+16, label(x))
+	}
+	for _, y := range yticks {
+		fmt.Fprintf(b, `<line x1="%d" y1="%.1f" x2="%d" y2="%.1f" stroke="#ddd"/>`+"\n", left, py(y), width-right, py(y))
+		fmt.Fprintf(b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", left-6, py(y)+4, label(y))
+	}
+	fmt.Fprintf(b, `<rect x="%d" y="%d" width="%d" height="%d" fill="none" stroke="black"/>`+"\n", left, top, width-left-right, height-top-bottom)
+	fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="middle">evaluations</text>`+"\n", (width+left-right)/2, height-12)
+	fmt.Fprintf(b, `<text transform="translate(16 %d) rotate(-90)" text-anchor="middle">fitness</text>`+"\n", (height+top-bottom)/2)
+
+	// the series and their legend
+	for i, s := range series {
+		var path strings.Builder
+		for _, p := range points {
+			y := s.value(p)
+			if math.IsInf(y, 0) || math.IsNaN(y) {
+				continue
+			}
+			fmt.Fprintf(&path, "%.1f,%.1f ", px(float64(p.Evaluations)), py(y))
+		}
+		fmt.Fprintf(b, `<polyline points="%s" fill="none" stroke="%s" stroke-width="1.5"/>`+"\n", strings.TrimSpace(path.String()), s.color)
+		ly := top + 16 + 16*i
+		fmt.Fprintf(b, `<line x1="%d" y1="%d" x2="%d" y2="%d" stroke="%s" stroke-width="2"/>`+"\n", width-right-70, ly, width-right-50, ly, s.color)
+		fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`+"\n", width-right-45, ly+4, s.name)
+	}
+
+	fmt.Fprintln(b, `</svg>`)
+	return b.Flush()
+}
+
+// SaveHeatmap renders the values as an SVG file. See Heatmap.
+func SaveHeatmap(path, title string, values [][]float64) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	err = Heatmap(f, title, values)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// The color stops of heatmaps, from the lowest to the highest value.
+var palette = [][3]float64{
+	{68, 1, 84},
+	{59, 82, 139},
+	{33, 145, 140},
+	{94, 201, 98},
+	{253, 231, 37},
+}
+
+// Heatmap renders a grid of values as an SVG heatmap, one cell per value, rows
+// from top to bottom. Colors range from dark purple for the lowest value to
+// yellow for the highest; non-finite values are gray. The scale is labeled with
+// the extent of the values.
+func Heatmap(w io.Writer, title string, values [][]float64) error {
+	rows, cols := len(values), 0
+	for _, row := range values {
+		cols = max(cols, len(row))
+	}
+	if rows == 0 || cols == 0 {
+		return errors.New("plot: no values")
+	}
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, row := range values {
+		for _, v := range row {
+			if !math.IsInf(v, 0) && !math.IsNaN(v) {
+				lo, hi = math.Min(lo, v), math.Max(hi, v)
+			}
+		}
+	}
+
+	// color maps a value to the palette
+	color := func(v float64) string {
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "#999"
+		}
+		x := 0.0
+		if lo < hi {
+			x = (v - lo) / (hi - lo) * float64(len(palette)-1)
+		}
+		i := min(int(x), len(palette)-2)
+		f := x - float64(i)
+		var rgb [3]int
+		for j := range rgb {
+			rgb[j] = int(math.Round(palette[i][j] + f*(palette[i+1][j]-palette[i][j])))
+		}
+		return fmt.Sprintf("#%02x%02x%02x", rgb[0], rgb[1], rgb[2])
+	}
+
+	// fit the grid into the plotting area with square cells
+	cell := math.Min(float64(width-left-right)/float64(cols), float64(height-top-bottom)/float64(rows))
+	x0 := float64(left) + (float64(width-left-right)-cell*float64(cols))/2
+
+	b := bufio.NewWriter(w)
+	fmt.Fprintf(b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d" font-family="sans-serif" font-size="12">`+"\n", width, height, width, height)
+	fmt.Fprintf(b, `<rect width="%d" height="%d" fill="white"/>`+"\n", width, height)
+	fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="middle" font-size="16">%s</text>`+"\n", width/2, top/2+6, escape(title))
+	for r, row := range values {
+		for c, v := range row {
+			fmt.Fprintf(b, `<rect x="%.2f" y="%.2f" width="%.2f" height="%.2f" fill="%s"/>`+"\n", x0+float64(c)*cell, float64(top)+float64(r)*cell, cell, cell, color(v))
+		}
+	}
+
+	// the scale
+	if lo <= hi {
+		for i := range palette {
+			x := float64(width-right-120) + float64(i)*20
+			fmt.Fprintf(b, `<rect x="%.0f" y="%d" width="20" height="10" fill="%s"/>`+"\n", x, height-bottom+20, color(lo+(hi-lo)*float64(i)/float64(len(palette)-1)))
+		}
+		fmt.Fprintf(b, `<text x="%d" y="%d" text-anchor="end">%s</text>`+"\n", width-right-124, height-bottom+30, label(lo))
+		fmt.Fprintf(b, `<text x="%d" y="%d">%s</text>`+"\n", width-right-16, height-bottom+30, label(hi))
+	}
+
+	fmt.Fprintln(b, `</svg>`)
+	return b.Flush()
+}
+
+// ticks returns evenly spaced round values covering [lo, hi], and the last of
+// them, which is at least hi.
+func ticks(lo, hi float64) ([]float64, float64) {
+	span := hi - lo
+	if span <= 0 {
+		span = math.Max(math.Abs(hi), 1)
+	}
+	step := math.Pow(10, math.Floor(math.Log10(span/5)))
+	switch r := span / 5 / step; {
+	case r > 5:
+		step *= 10
+	case r > 2:
+		step *= 5
+	case r > 1:
+		step *= 2
+	}
+	var ts []float64
+	for t := math.Floor(lo/step) * step; ; t += step {
+		ts = append(ts, t)
+		if hi <= t+step/1e6 && 1 < len(ts) {
+			break
+		}
+	}
+	return ts, ts[len(ts)-1]
+}
+
+// label formats a tick value compactly.
+func label(x float64) string {
+	if x == 0 {
+		return "0"
+	}
+	if a := math.Abs(x); a < 1e-3 || 1e6 <= a {
+		return fmt.Sprintf("%.1e", x)
+	}
+	return fmt.Sprintf("%.4g", x)
+}
+
+// escape escapes text for inclusion in XML.
+func escape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
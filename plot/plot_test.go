@@ -0,0 +1,131 @@
+package plot_test
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/plot"
+	"github.com/cbarrick/evo/pop/gen"
+)
+
+type counter struct {
+	evo.Cache
+	n float64
+}
+
+func (c *counter) Fitness() float64 {
+	return c.Cache.Fitness(func() float64 { return c.n })
+}
+
+func TestRecorder(t *testing.T) {
+	var s evo.Stats
+	s = s.Put(1).Put(2).Put(6)
+	rec := plot.NewRecorder()
+	rec.Record(10, s)
+	rec.Record(20, s.Put(9))
+	points := rec.Points()
+	if len(points) != 2 {
+		t.Fatal("wrong number of points")
+	}
+	p := points[0]
+	if p.Evaluations != 10 || p.Best != 6 || p.Mean != 3 || p.Worst != 1 {
+		t.Errorf("bad point %+v", p)
+	}
+	if points[1].Best != 9 || points[1].Seconds < p.Seconds {
+		t.Errorf("bad point %+v", points[1])
+	}
+}
+
+func TestWatch(t *testing.T) {
+	seed := make([]evo.Genome, 8)
+	for i := range seed {
+		seed[i] = &counter{n: float64(i)}
+	}
+	pop := new(gen.Population)
+	pop.Evolve(seed, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return &counter{n: current.(*counter).n + 1}
+	})
+	rec := plot.NewRecorder()
+	rec.Watch(pop, time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	pop.Stop()
+	points := rec.Points()
+	if len(points) == 0 {
+		t.Fatal("no points recorded")
+	}
+	last := points[len(points)-1]
+	if last.Evaluations <= 0 || last.Best-last.Worst != 7 {
+		t.Errorf("bad point %+v", last)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	points := []plot.Point{
+		{Evaluations: 0, Best: -10, Mean: -50, Worst: math.Inf(-1)},
+		{Evaluations: 500, Best: -2, Mean: -8, Worst: -30},
+		{Evaluations: 1000, Best: 0, Mean: -1, Worst: -5},
+	}
+	var buf bytes.Buffer
+	if err := plot.SVG(&buf, "a < b", points); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if strings.Count(out, "<polyline") != 3 || !strings.Contains(out, "a &lt; b") {
+		t.Error("missing elements")
+	}
+	if strings.Contains(out, "NaN") || strings.Contains(out, "Inf") {
+		t.Error("non-finite coordinates")
+	}
+
+	// the output must be well formed
+	dec := xml.NewDecoder(&buf)
+	for {
+		_, err := dec.Token()
+		if err != nil {
+			if err != io.EOF {
+				t.Error(err)
+			}
+			break
+		}
+	}
+
+	if err := plot.SVG(&buf, "", nil); err == nil {
+		t.Error("expected error for no points")
+	}
+}
+
+func TestHeatmap(t *testing.T) {
+	values := [][]float64{
+		{0, 1, 2},
+		{3, math.NaN(), 5},
+	}
+	var buf bytes.Buffer
+	if err := plot.Heatmap(&buf, "map", values); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+	if !strings.Contains(out, `fill="#440154"`) || !strings.Contains(out, `fill="#fde725"`) || !strings.Contains(out, `fill="#999"`) {
+		t.Error("missing colors")
+	}
+	if strings.Contains(out, "NaN") {
+		t.Error("non-finite coordinates")
+	}
+	dec := xml.NewDecoder(&buf)
+	for {
+		if _, err := dec.Token(); err != nil {
+			if err != io.EOF {
+				t.Error(err)
+			}
+			break
+		}
+	}
+	if err := plot.Heatmap(&buf, "", nil); err == nil {
+		t.Error("expected error for no values")
+	}
+}
@@ -0,0 +1,484 @@
+package gen_test
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+)
+
+// num is a genome which is its own fitness, compared by identity.
+type num struct{ f float64 }
+
+func (x *num) Fitness() float64 { return x.f }
+
+// generational.go
+// -------------------------
+
+// vec is a genome whose fitness is its first element. Slices are not
+// comparable, so vecs can only be identified by their position.
+type vec []float64
+
+func (x vec) Fitness() float64 { return x[0] }
+
+func TestElite(t *testing.T) {
+	t.Run("pointer", func(t *testing.T) {
+		testElite(t, func(f float64) evo.Genome { return &num{f} })
+	})
+	t.Run("slice", func(t *testing.T) {
+		testElite(t, func(f float64) evo.Genome { return vec{f} })
+	})
+
+	// elites which survive the replacement are left alone
+	t.Run("survivors", func(t *testing.T) {
+		members := make([]evo.Genome, 10)
+		for i := range members {
+			members[i] = vec{float64(i)}
+		}
+		var (
+			mu      sync.Mutex
+			changed bool
+		)
+		var pop gen.Population
+		pop.SetElite(3)
+		pop.SetReplacement(func(members, offspring []evo.Genome) {})
+		pop.OnBarrier(func(generation int, members []evo.Genome) {
+			mu.Lock()
+			defer mu.Unlock()
+			for i := range members {
+				changed = changed || members[i].Fitness() != float64(i)
+			}
+		})
+		pop.Evolve(members, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+			return vec{-1}
+		})
+		pop.Poll(0, func() bool { return 5 <= pop.Generation() })
+		pop.Wait()
+
+		mu.Lock()
+		defer mu.Unlock()
+		if changed {
+			t.Error("surviving elites restored over other members")
+		}
+	})
+}
+
+// testElite checks that the elites survive with genomes made by the function.
+func testElite(t *testing.T, genome func(fitness float64) evo.Genome) {
+	const k = 3
+	members := make([]evo.Genome, 10)
+	for i := range members {
+		members[i] = genome(float64(i))
+	}
+
+	// the elites of the previous generation
+	elites := func(members []evo.Genome) []evo.Genome {
+		best := append([]evo.Genome(nil), members...)
+		sort.Slice(best, func(i, j int) bool { return best[i].Fitness() > best[j].Fitness() })
+		return best[:k]
+	}
+
+	var (
+		mu         sync.Mutex
+		prev       = elites(members)
+		failed     bool
+		duplicated bool
+	)
+	var pop gen.Population
+	pop.SetGap(0.8)
+	pop.SetElite(k)
+	pop.OnBarrier(func(generation int, members []evo.Genome) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, e := range prev {
+			found := false
+			for _, m := range members {
+				found = found || reflect.DeepEqual(m, e)
+			}
+			if !found {
+				failed = true
+			}
+		}
+
+		// surviving elites are not restored a second time
+		seen := make(map[float64]bool)
+		for _, m := range members {
+			if seen[m.Fitness()] {
+				duplicated = true
+			}
+			seen[m.Fitness()] = true
+		}
+		prev = elites(members)
+	})
+
+	// every offspring is fitter than every parent
+	pop.Evolve(members, func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		var max float64
+		for _, s := range suitors {
+			if max < s.Fitness() {
+				max = s.Fitness()
+			}
+		}
+		return genome(max + 1 + rand.Float64())
+	})
+	pop.Poll(0, func() bool { return 20 <= pop.Generation() })
+	pop.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if failed {
+		t.Error("elites lost")
+	}
+	if duplicated {
+		t.Error("elites duplicated")
+	}
+}
+
+func TestOnBarrier(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		running  atomic.Int32 // the number of running EvolveFns
+		gens     []int
+		overlap  bool
+		stale    bool
+		injected bool
+	)
+	var pop gen.Population
+	pop.OnBarrier(func(generation int, members []evo.Genome) {
+		mu.Lock()
+		defer mu.Unlock()
+		gens = append(gens, generation)
+		overlap = overlap || running.Load() != 0
+		members[0] = &num{100} // inject a known solution
+	})
+	pop.OnGeneration(func(generation int, stats evo.Stats) {
+		mu.Lock()
+		defer mu.Unlock()
+		stale = stale || stats.Max() != 100
+	})
+	pop.Evolve(nums(0, 1, 2, 3), func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		running.Add(1)
+		defer running.Add(-1)
+		for _, s := range suitors {
+			if s.Fitness() == 100 {
+				mu.Lock()
+				injected = true
+				mu.Unlock()
+			}
+		}
+		return &num{current.Fitness() / 2}
+	})
+	pop.Poll(0, func() bool { return 10 <= pop.Generation() })
+	pop.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	for i := range gens {
+		if gens[i] != i+1 {
+			t.Fatalf("barriers of generations %v, want 1, 2, 3...", gens)
+		}
+	}
+	if len(gens) < 10 {
+		t.Errorf("%d barriers in %d generations", len(gens), pop.Generation())
+	}
+	if overlap {
+		t.Error("barrier while members were evolving")
+	}
+	if stale {
+		t.Error("statistics do not reflect the changes of the barrier")
+	}
+	if !injected {
+		t.Error("changes of the barrier not evolved")
+	}
+}
+
+func TestOnError(t *testing.T) {
+	members := nums(0, 1, 2, 3)
+	bad := members[1]
+	var (
+		mu     sync.Mutex
+		errs   int
+		misuse bool
+	)
+	var pop gen.Population
+	pop.OnError(func(slot int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs++
+		perr, ok := err.(*evo.PanicError)
+		misuse = misuse || slot != 1 || !ok || perr.Value != "bad genome"
+	})
+	pop.Evolve(members, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		if current == bad {
+			panic("bad genome")
+		}
+		return &num{current.Fitness() + 10}
+	})
+	pop.Poll(0, func() bool { return 5 <= pop.Generation() })
+	pop.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if errs < 5 || misuse {
+		t.Errorf("%d errors in %d generations, reported as expected: %v", errs, pop.Generation(), !misuse)
+	}
+	if pop.Get(1) != bad {
+		t.Error("member which panicked was replaced")
+	}
+	if f := pop.Get(0).Fitness(); f < 50 {
+		t.Errorf("other members stopped evolving at fitness %v", f)
+	}
+}
+
+func TestEventClock(t *testing.T) {
+	start := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	members := []evo.Genome{&num{0}, &num{1}}
+	ch := make(chan evo.Event, 1)
+	var pop gen.Population
+	pop.SetClock(evo.NewFakeClock(start))
+	pop.Subscribe(ch)
+	pop.Evolve(members, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	ev := <-ch
+	pop.Stop()
+	if !ev.Time.Equal(start) {
+		t.Errorf("%v at %v, want %v", ev.Kind, ev.Time, start)
+	}
+}
+
+func TestPollClock(t *testing.T) {
+	c := evo.NewFakeClock(time.Now())
+	var pop gen.Population
+	pop.SetClock(c)
+	pop.Evolve(nums(0, 1), func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	var polls atomic.Int32
+	pop.Poll(time.Hour, func() bool { return polls.Add(1) == 2 })
+
+	// the condition is only checked once the clock advances by the frequency
+	c.BlockUntil(1)
+	c.Advance(time.Hour - time.Second)
+	time.Sleep(10 * time.Millisecond)
+	if n := polls.Load(); n != 0 {
+		t.Errorf("%d polls before the frequency", n)
+	}
+	c.Advance(time.Second)
+	c.BlockUntil(1)
+	c.Advance(time.Hour)
+	pop.Wait()
+	if n := polls.Load(); n != 2 {
+		t.Errorf("stopped after %d polls, want 2", n)
+	}
+}
+
+// migrate.go
+// -------------------------
+
+// island returns a stopped population of genomes of the given fitnesses.
+func island(fs ...float64) *gen.Population {
+	pop := new(gen.Population)
+	pop.Evolve(nums(fs...), func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	pop.Stop()
+	return pop
+}
+
+// members returns the sorted fitnesses of the members of an island.
+func members(isl *gen.Population) []float64 {
+	fs := make([]float64, isl.Len())
+	for i := range fs {
+		fs[i] = isl.Get(i).Fitness()
+	}
+	sort.Float64s(fs)
+	return fs
+}
+
+func TestMigrateWith(t *testing.T) {
+	best := gen.Policy{N: 2, Emigrants: gen.ChooseBest, Immigrants: gen.ChooseWorst}
+	move, copied := best, best
+	copied.Copy = true
+	tests := []struct {
+		name     string
+		policy   gen.Policy
+		src, dst []float64
+	}{
+		{"move", move, []float64{0, 1, 10, 11}, []float64{2, 3, 12, 13}},
+		{"copy", copied, []float64{0, 1, 2, 3}, []float64{2, 3, 12, 13}},
+	}
+	for _, test := range tests {
+		src, dst := island(0, 1, 2, 3), island(10, 11, 12, 13)
+		gen.MigrateWith(test.policy)(src, []evo.Genome{dst})
+		if got := members(src); !reflect.DeepEqual(got, test.src) {
+			t.Errorf("%s: source %v, want %v", test.name, got, test.src)
+		}
+		if got := members(dst); !reflect.DeepEqual(got, test.dst) {
+			t.Errorf("%s: destination %v, want %v", test.name, got, test.dst)
+		}
+	}
+
+	// migrants go to the first suitor when directed, and never to the source
+	directed := gen.Policy{N: 1, Copy: true, Directed: true}
+	undirected := gen.Policy{N: 1, Copy: true}
+	for i := 0; i < 10; i++ {
+		src, first, second := island(1), island(0), island(0)
+		gen.MigrateWith(directed)(src, []evo.Genome{first, second})
+		if members(first)[0] != 1 || members(second)[0] != 0 {
+			t.Error("directed migration not to the first suitor")
+		}
+		gen.MigrateWith(undirected)(src, []evo.Genome{src, second})
+		if members(second)[0] != 1 {
+			t.Error("undirected migration to the source")
+		}
+	}
+
+	// no migrants leave before the delay
+	clock := evo.NewFakeClock(time.Unix(0, 0))
+	src, dst := island(1), island(0)
+	done := make(chan struct{})
+	go func() {
+		gen.MigrateWith(gen.Policy{N: 1, Delay: time.Hour, Clock: clock})(src, []evo.Genome{dst})
+		close(done)
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(time.Hour - time.Second)
+	select {
+	case <-done:
+		t.Error("migrated before the delay")
+	case <-time.After(10 * time.Millisecond):
+	}
+	clock.Advance(time.Second)
+	<-done
+	if members(dst)[0] != 1 {
+		t.Error("no migration after the delay")
+	}
+}
+
+func TestMigrateAdaptive(t *testing.T) {
+	boost := gen.DiversityBoost(0.1, 3)
+	p := gen.Policy{N: 2, Delay: 3 * time.Second}
+	if q := boost(evo.Stats{}.Put(5).Put(5), p); q.N != 6 || q.Delay != time.Second {
+		t.Errorf("boosted policy of %d migrants after %v, want 6 after 1s", q.N, q.Delay)
+	}
+	if q := boost(evo.Stats{}.Put(1).Put(9), p); q.N != p.N || q.Delay != p.Delay {
+		t.Errorf("diverse island boosted to %d migrants after %v", q.N, q.Delay)
+	}
+
+	// islands of low diversity send more migrants
+	tests := []struct {
+		src  []float64
+		want int // the number of migrants
+	}{
+		{[]float64{5, 5, 5, 5}, 3},
+		{[]float64{1, 5, 9, 13}, 1},
+	}
+	for _, test := range tests {
+		src, dst := island(test.src...), island(0, 0, 0, 0)
+		migrate := gen.MigrateAdaptive(gen.Policy{N: 1, Copy: true}, boost)
+		migrate(src, []evo.Genome{dst})
+		var n int
+		for _, f := range members(dst) {
+			if f != 0 {
+				n++
+			}
+		}
+		if n != test.want {
+			t.Errorf("island %v sent %d migrants, want %d", test.src, n, test.want)
+		}
+	}
+}
+
+func TestChoosers(t *testing.T) {
+	genomes := nums(3, 0, 4, 1, 2)
+	tests := []struct {
+		name   string
+		choose gen.Chooser
+		want   []float64 // the sorted fitnesses of 2 chosen genomes, nil if any
+	}{
+		{"random", gen.ChooseRandom, nil},
+		{"best", gen.ChooseBest, []float64{3, 4}},
+		{"worst", gen.ChooseWorst, []float64{0, 1}},
+		{"full tournament", gen.ChooseTournament(5), []float64{3, 4}},
+		{"tournament", gen.ChooseTournament(2), nil},
+		{"single tournament", gen.ChooseTournament(1), nil},
+	}
+	for _, test := range tests {
+		for trial := 0; trial < 10; trial++ {
+			idx := test.choose(genomes, 2)
+			if len(idx) != 2 || idx[0] == idx[1] {
+				t.Errorf("%s: chose %v, want 2 distinct indices", test.name, idx)
+				break
+			}
+			got := []float64{genomes[idx[0]].Fitness(), genomes[idx[1]].Fitness()}
+			sort.Float64s(got)
+			if test.want != nil && !reflect.DeepEqual(got, test.want) {
+				t.Errorf("%s: chose %v, want %v", test.name, got, test.want)
+				break
+			}
+		}
+	}
+}
+
+// replace.go
+// -------------------------
+
+// nums returns genomes of the given fitnesses, with nil for a NaN.
+func nums(fs ...float64) []evo.Genome {
+	genomes := make([]evo.Genome, len(fs))
+	for i, f := range fs {
+		if !math.IsNaN(f) {
+			genomes[i] = &num{f}
+		}
+	}
+	return genomes
+}
+
+// fitnesses returns the fitness of each genome.
+func fitnesses(genomes []evo.Genome) []float64 {
+	fs := make([]float64, len(genomes))
+	for i := range genomes {
+		fs[i] = genomes[i].Fitness()
+	}
+	return fs
+}
+
+func TestReplace(t *testing.T) {
+	nan := math.NaN()
+	tests := []struct {
+		name      string
+		repl      gen.Replacement
+		members   []float64
+		offspring []float64 // NaN for members which were not evolved
+		want      []float64
+	}{
+		{"random", gen.ReplaceRandom, []float64{1, 2, 3, 4}, []float64{5, 0, 7, 8}, []float64{5, 0, 7, 8}},
+		{"random gap", gen.ReplaceRandom, []float64{1, 2, 3, 4}, []float64{nan, 0, nan, 8}, []float64{1, 0, 3, 8}},
+		{"worst", gen.ReplaceWorst, []float64{4, 1, 3, 2}, []float64{nan, 9, nan, 0}, []float64{4, 9, 3, 0}},
+		{"worst all", gen.ReplaceWorst, []float64{4, 1, 3, 2}, []float64{0, 0, 0, 0}, []float64{0, 0, 0, 0}},
+		{"worst none", gen.ReplaceWorst, []float64{4, 1, 3, 2}, []float64{nan, nan, nan, nan}, []float64{4, 1, 3, 2}},
+		{"parent", gen.ReplaceParent, []float64{1, 2, 3, 4}, []float64{0, 2, 5, nan}, []float64{1, 2, 5, 4}},
+	}
+	for _, test := range tests {
+		members := nums(test.members...)
+		test.repl(members, nums(test.offspring...))
+		if got := fitnesses(members); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: members %v, want %v", test.name, got, test.want)
+		}
+	}
+
+	// offspring as fit as their parent replace it
+	members, offspring := nums(1), nums(1)
+	gen.ReplaceParent(members, offspring)
+	if members[0] != offspring[0] {
+		t.Error("parent: offspring as fit as its parent not kept")
+	}
+}
@@ -8,11 +8,15 @@
 package gen
 
 import (
+	"context"
+	"fmt"
 	"math/rand"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/sel"
 )
 
 type Population struct {
@@ -20,18 +24,180 @@ type Population struct {
 	getc    chan chan int       // used to access members while running
 	setc    chan chan int       // used to mutate members while running
 	valuec  chan evo.Genome     // sends/receives genomes for get/set
-	statsc  chan chan evo.Stats // used to get stats while running
+	viewc   chan chan evo.View  // used to get views while running
 	stopc   chan chan struct{}  // used to stop the goroutine
+	donec   chan struct{}       // closed once stopped
+	meter   *evo.Meter          // measures throughput
+	gens    *atomic.Int64       // counts generations
+	events  *evo.Events         // publishes events to subscribers, may be nil
+	best    *evo.Tracker        // tracks the best genome ever evolved
+
+	elite int         // number of elites preserved across generations
+	gap   float64     // fraction of the population replaced each generation
+	repl  Replacement // merges offspring into the population
+	k     int         // number of suitors per evolution, 0 for all
+
+	immigrants float64           // fraction of random immigrants per generation
+	newcomer   func() evo.Genome // creates random immigrants
+
+	onGen     func(generation int, stats evo.Stats)      // called after each generation
+	onBarrier func(generation int, members []evo.Genome) // called at the end of each generation
+	recyc     *evo.Recycler                              // receives discarded genomes
+	onErr     func(slot int, err error)                  // called when an EvolveFn panics
+
+	tracer   evo.Tracer      // records generations and migrations
+	traceCtx context.Context // the parent of the spans
+
+	clock evo.Clock // measures the polling frequency, nil for the system clock
+}
+
+// SetTracer configures the population to record a span for each generation,
+// annotated with the generation number, the number of members evolved, and the
+// maximum and mean fitness of the new generation. When the population is an
+// island, each migration from it is recorded as a span as well. The spans are
+// children of the given context. SetTracer must be called before Evolve.
+func (pop *Population) SetTracer(ctx context.Context, t evo.Tracer) {
+	pop.traceCtx = ctx
+	pop.tracer = t
+}
+
+// SetClock configures the population to measure the frequency of Poll, and to
+// time its events, with the given clock rather than the system clock, e.g. an
+// evo.FakeClock in tests. The delays of migrations are measured by the clock
+// of their Policy.
+// SetClock must be called before Evolve.
+func (pop *Population) SetClock(c evo.Clock) {
+	pop.clock = c
+}
+
+// OnError configures the population to recover panics raised by the EvolveFn,
+// or by the fitness function of the genome it returns. The callback receives
+// the index of the member being evolved and a *evo.PanicError, and the member
+// is kept unchanged for that generation. The callback may be called
+// concurrently. Without a callback, a panic crashes the program. OnError must
+// be called before Evolve.
+func (pop *Population) OnError(fn func(slot int, err error)) {
+	pop.onErr = fn
+}
+
+// SetRecycler configures the population to put the genomes it discards into
+// the recycler: the members replaced each generation and the offspring which
+// are not installed. Since discarded genomes are reused, genomes taken from the
+// population, e.g. through views, must not be retained across generations.
+// SetRecycler must be called before Evolve.
+func (pop *Population) SetRecycler(r *evo.Recycler) {
+	pop.recyc = r
+}
+
+// Subscribe registers a channel to receive the events of the population:
+// NewBest, GenerationDone, Stagnation, Stopped, and MigrationDone when the
+// population is an island. Events are dropped rather than delaying the
+// evolution when the channel is full. Subscribe must be called before Evolve.
+func (pop *Population) Subscribe(ch chan<- evo.Event) {
+	pop.getEvents().Subscribe(ch)
+}
+
+// SetStagnation configures the population to publish a Stagnation event once
+// the best fitness has not improved for n generations. SetStagnation must be
+// called before Evolve.
+func (pop *Population) SetStagnation(n int) {
+	pop.getEvents().SetPatience(n)
+}
+
+// getEvents returns the events of the population, creating them if needed.
+func (pop *Population) getEvents() *evo.Events {
+	if pop.events == nil {
+		pop.events = new(evo.Events)
+	}
+	return pop.events
+}
+
+// OnGeneration sets a callback which is called after each generation with the
+// number of generations evolved so far and the statistics of the new
+// generation. The callback runs between generations, before the next one
+// starts, so it can be used for logging and for adaptive parameter control
+// without polling. The callback must not call methods of the population.
+// OnGeneration must be called before Evolve.
+func (pop *Population) OnGeneration(fn func(generation int, stats evo.Stats)) {
+	pop.onGen = fn
+}
+
+// OnBarrier sets a callback which is called at the end of each generation with
+// the number of the new generation and its members, once the offspring, elites,
+// and immigrants are installed. The callback runs while no member is being
+// evolved, so it may inspect the members and replace or modify them in place,
+// e.g. to inject known solutions or to repair the population as a whole. The
+// statistics, events, and OnGeneration callback of the generation reflect the
+// changes. The callback must not retain the slice nor call methods of the
+// population. OnBarrier must be called before Evolve.
+func (pop *Population) OnBarrier(fn func(generation int, members []evo.Genome)) {
+	pop.onBarrier = fn
+}
+
+// SetSuitors configures the population to pass each call of the EvolveFn a
+// random sample of k members as suitors, rather than the entire population.
+// Sampling reduces the cost of each call in large populations and lowers the
+// selection pressure of the EvolveFn. When k is 0 or at least the size of the
+// population, every member is a suitor. SetSuitors must be called before
+// Evolve.
+func (pop *Population) SetSuitors(k int) {
+	pop.k = k
+}
+
+// SetGap sets the generation gap, the fraction of the population which is
+// evolved and replaced each generation. The default gap is 1, meaning the
+// entire population is replaced every generation. Smaller gaps give
+// steady-state behavior. At least one member is evolved each generation.
+// SetGap must be called before Evolve.
+func (pop *Population) SetGap(gap float64) {
+	pop.gap = gap
+}
+
+// SetReplacement sets the replacement strategy used to merge offspring into the
+// population. The default is ReplaceRandom. SetReplacement must be called
+// before Evolve.
+func (pop *Population) SetReplacement(repl Replacement) {
+	pop.repl = repl
+}
+
+// SetImmigrants configures the random-immigrants strategy: after each
+// generation, the given fraction of the population, the least fit members, is
+// replaced with fresh genomes created by the random function. At least one
+// member is replaced when the fraction is positive. Random immigrants keep
+// injecting diversity, which helps on dynamic and deceptive problems. Elites
+// are preserved before the immigrants arrive, so a large fraction may replace
+// them. SetImmigrants must be called before Evolve.
+func (pop *Population) SetImmigrants(fraction float64, random func() evo.Genome) {
+	pop.immigrants = fraction
+	pop.newcomer = random
+}
+
+// SetElite configures the population to preserve the k most fit genomes of each
+// generation unchanged into the next, regardless of the genomes returned by the
+// EvolveFn. The elites replace the least fit genomes of the next generation,
+// unless they are already part of it. Genomes are identified by their position
+// in the population rather than compared, so genomes of any type, e.g. slices,
+// may be elites; meanwhile, the Replacement sees the members wrapped, and must
+// not depend on their concrete type. SetElite must be called before Evolve.
+func (pop *Population) SetElite(k int) {
+	pop.elite = k
 }
 
 // Evolve initiates the optimization in a separate goroutine.
 func (pop *Population) Evolve(members []evo.Genome, body evo.EvolveFn) {
 	pop.members = members
-	pop.statsc = make(chan chan evo.Stats)
+	pop.viewc = make(chan chan evo.View)
 	pop.setc = make(chan chan int)
 	pop.getc = make(chan chan int)
 	pop.valuec = make(chan evo.Genome)
 	pop.stopc = make(chan chan struct{}, 1)
+	pop.donec = make(chan struct{})
+	pop.meter = evo.NewMeter()
+	pop.gens = new(atomic.Int64)
+	pop.best = evo.NewTracker(pop.meter)
+	if pop.events != nil {
+		pop.events.SetClock(pop.clock)
+	}
 	go run(*pop, body)
 }
 
@@ -40,10 +206,14 @@ func (pop *Population) Stop() {
 	ch := make(chan struct{})
 	pop.stopc <- ch
 	<-ch
-	close(pop.statsc)
+	if pop.events != nil {
+		pop.events.Publish(evo.Event{Kind: evo.Stopped, Generation: pop.Generation()})
+	}
+	close(pop.viewc)
 	close(pop.setc)
 	close(pop.getc)
 	close(pop.valuec)
+	close(pop.donec)
 }
 
 // Poll executes a function at some frequency for the duration of the
@@ -51,10 +221,14 @@ func (pop *Population) Stop() {
 // is halted.
 func (pop *Population) Poll(freq time.Duration, cond evo.ConditionFn) {
 	done := pop.stopc
+	clock := pop.clock
+	if clock == nil {
+		clock = evo.SystemClock
+	}
 	go func() {
 		for {
 			select {
-			case <-time.After(freq):
+			case <-clock.After(freq):
 				if cond() {
 					pop.Stop()
 					return
@@ -67,21 +241,78 @@ func (pop *Population) Poll(freq time.Duration, cond evo.ConditionFn) {
 	}()
 }
 
-// Wait blocks until the evolution terminates.
+// PollStall stops the optimization once the best-so-far fitness of the
+// population has stalled, checking at the given frequency. A stall without a
+// clock measures its duration by the clock of the population. See evo.Stall.
+func (pop *Population) PollStall(freq time.Duration, stall evo.Stall) {
+	if stall.Clock == nil {
+		stall.Clock = pop.clock
+	}
+	pop.Poll(freq, stall.Condition(pop))
+}
+
+// Wait blocks until the evolution terminates, i.e. until Stop has been called,
+// by a Poll condition or another goroutine, and has returned. The Stopped event
+// is published before Wait returns.
 func (pop *Population) Wait() {
-	pop.stopc <- <-pop.stopc
+	<-pop.donec
 }
 
-// Stats returns statistics on the fitness of genomes in the population.
+// Stats returns statistics on the fitness of genomes in the population, tagged
+// with the current generation.
 func (pop *Population) Stats() (s evo.Stats) {
-	statsc := <-pop.statsc
-	if statsc == nil {
-		for i := range pop.members {
-			s = s.Put(pop.members[i].Fitness())
-		}
-		return s
+	gen := pop.Generation()
+	v := pop.View()
+	s = v.Stats().WithGeneration(gen)
+	v.Close()
+	return s
+}
+
+// IslandStats returns the statistics of each member which is itself a
+// population, e.g. the islands of an island model, in the order of the
+// members. It returns nil if no member is a population. See evo.View.SubStats.
+func (pop *Population) IslandStats() []evo.Stats {
+	v := pop.View()
+	defer v.Close()
+	return v.SubStats()
+}
+
+// Best returns the record of the best genome observed since Evolve was called,
+// including genomes which were later replaced. The initial members and the
+// offspring of every generation are observed in parallel, like the calls to the
+// EvolveFn, each evaluated once by the tracker. It is safe to call while the
+// population is evolving and after it stops.
+func (pop *Population) Best() evo.Record {
+	if pop.best == nil {
+		return evo.Record{}
+	}
+	return pop.best.Best()
+}
+
+// Generation returns the number of generations evolved since Evolve was called.
+// It is safe to call while the population is evolving, e.g. from a ConditionFn
+// to terminate after a number of generations.
+func (pop *Population) Generation() int {
+	if pop.gens == nil {
+		return 0
+	}
+	return int(pop.gens.Load())
+}
+
+// View returns a snapshot of the members of the population.
+func (pop *Population) View() evo.View {
+	viewc := <-pop.viewc
+	if viewc == nil {
+		return evo.NewView(pop.members)
 	}
-	return <-statsc
+	return <-viewc
+}
+
+// Throughput returns the throughput of the population since Evolve was called.
+// Each call to the EvolveFn counts as an iteration, and each genome it returns
+// as an evaluation.
+func (pop *Population) Throughput() evo.Throughput {
+	return pop.meter.Throughput()
 }
 
 // Fitness returns the maximum fitness within the population.
@@ -89,8 +320,12 @@ func (pop *Population) Fitness() float64 {
 	return pop.Stats().Max()
 }
 
-// get returns the ith member of the population.
-func (pop *Population) get(i int) (val evo.Genome) {
+// Get returns the ith member of the population. While the population is
+// evolving, the member is read by the evolution loop, so that external
+// controllers and custom migration schemes can inspect specific members
+// safely. Get panics if called before Evolve or if i is out of range.
+func (pop *Population) Get(i int) (val evo.Genome) {
+	pop.check(i)
 	getter := <-pop.getc
 	if getter == nil {
 		val = pop.members[i]
@@ -101,8 +336,12 @@ func (pop *Population) get(i int) (val evo.Genome) {
 	return val
 }
 
-// set sets the ith member of the population.
-func (pop *Population) set(i int, val evo.Genome) {
+// Set replaces the ith member of the population. While the population is
+// evolving, the member is replaced by the evolution loop, in the same way as
+// migrants, so the offspring of the generation in progress may replace it in
+// turn. Set panics if called before Evolve or if i is out of range.
+func (pop *Population) Set(i int, val evo.Genome) {
+	pop.check(i)
 	setter := <-pop.setc
 	if setter == nil {
 		pop.members[i] = val
@@ -112,10 +351,27 @@ func (pop *Population) set(i int, val evo.Genome) {
 	}
 }
 
+// Len returns the number of members of the population, which is fixed by the
+// call to Evolve.
+func (pop *Population) Len() int {
+	return len(pop.members)
+}
+
+// check panics unless i indexes a member of an evolving or stopped population,
+// so that invalid indices panic in the caller rather than the evolution loop.
+func (pop *Population) check(i int) {
+	if pop.getc == nil {
+		panic("gen: population accessed before Evolve")
+	}
+	if i < 0 || len(pop.members) <= i {
+		panic(fmt.Sprintf("gen: index %d out of range for %d members", i, len(pop.members)))
+	}
+}
+
 // Migrate returns an EvolveFn for using generational populations as genomes.
-// The returned migration function exchanges n individuals between the target
-// population and one neighboring population. Migration can be slowed down by
-// a delay period that occurs before the migration is performed.
+// The returned migration function exchanges n random individuals between the
+// target population and one random neighboring population. Migration can be
+// slowed down by a delay period that occurs before the migration is performed.
 //
 // The returned migration function can be used to implement an island population
 // model where the individuals are divided between some number of generational
@@ -136,23 +392,140 @@ func (pop *Population) set(i int, val evo.Genome) {
 //     pop := graph.Ring(len(islands))
 //     pop.Evolve(islands, gen.Migrate(5, 1*time.Second))
 func Migrate(n int, delay time.Duration) evo.EvolveFn {
-	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
-		<-time.After(delay)
-		var a, b *Population
-		a = current.(*Population)
-		for b = a; b == a; {
-			b = suitors[rand.Intn(len(suitors))].(*Population)
+	return MigrateWith(Policy{N: n, Delay: delay})
+}
+
+// A boxed genome is a member of the previous generation during replacement.
+// Members are identified by their box, since genomes themselves may not be
+// comparable, e.g. slices.
+type boxed struct{ evo.Genome }
+
+// preserve replaces the members by the offspring, then restores the elites of
+// the previous generation which were replaced.
+func (pop *Population) preserve(repl Replacement, offspring []evo.Genome) {
+	for i := range pop.members {
+		pop.members[i] = &boxed{pop.members[i]}
+	}
+	elite := make(map[*boxed]bool, pop.elite)
+	for _, e := range sel.Elite(pop.elite, pop.members...) {
+		elite[e.(*boxed)] = true
+	}
+	repl(pop.members, offspring)
+
+	// unbox the survivors, noting which of them are elites
+	kept := make([]bool, len(pop.members))
+	for i, m := range pop.members {
+		if b, ok := m.(*boxed); ok {
+			kept[i] = elite[b]
+			delete(elite, b)
+			pop.members[i] = b.Genome
+		}
+	}
+
+	// the missing elites replace the least fit members which are not
+	// themselves elites, since surviving elites may be among the least fit
+	var missing []evo.Genome
+	for b := range elite {
+		missing = append(missing, b.Genome)
+	}
+	for _, i := range worst(pop.members, len(pop.members)) {
+		if len(missing) == 0 {
+			break
+		}
+		if !kept[i] {
+			pop.members[i] = missing[0]
+			missing = missing[1:]
+		}
+	}
+}
+
+// replace installs the next generation of the population.
+func (pop *Population) replace(offspring []evo.Genome) {
+	repl := pop.repl
+	if repl == nil {
+		repl = ReplaceRandom
+	}
+	if pop.elite <= 0 {
+		repl(pop.members, offspring)
+	} else {
+		pop.preserve(repl, offspring)
+	}
+	if pop.immigrants > 0 {
+		n := int(pop.immigrants*float64(len(pop.members)) + 0.5)
+		if n < 1 {
+			n = 1
+		}
+		if len(pop.members) < n {
+			n = len(pop.members)
+		}
+		for _, i := range worst(pop.members, n) {
+			pop.members[i] = pop.newcomer()
+		}
+	}
+}
+
+// measured returns true if the statistics of each generation are needed, by
+// the events, the OnGeneration callback, or the tracer.
+func (pop *Population) measured() bool {
+	return pop.events != nil || pop.onGen != nil || pop.tracer != nil
+}
+
+// measure computes the statistics of the members and observes the best of them,
+// evaluating the fitness of each member once.
+func (pop *Population) measure() (stats evo.Stats, best evo.Genome) {
+	var fit float64
+	for i, m := range pop.members {
+		f := m.Fitness()
+		stats = stats.Put(f)
+		if i == 0 || fit < f {
+			best, fit = m, f
+		}
+	}
+	if best != nil {
+		pop.best.Put(best, fit)
+	}
+	return stats, best
+}
+
+// suitors returns the suitors for a call to the EvolveFn.
+func (pop *Population) suitors() []evo.Genome {
+	size := len(pop.members)
+	if pop.k <= 0 || size <= pop.k {
+		return pop.members
+	}
+
+	// Floyd's algorithm samples k distinct indices in O(k) time
+	chosen := make(map[int]bool, pop.k)
+	suitors := make([]evo.Genome, 0, pop.k)
+	for j := size - pop.k; j < size; j++ {
+		i := rand.Intn(j + 1)
+		if chosen[i] {
+			i = j
 		}
-		for i := 0; i < n; i++ {
-			ai := rand.Intn(len(a.members))
-			bi := rand.Intn(len(b.members))
-			av := a.get(ai)
-			bv := b.get(bi)
-			a.set(ai, bv)
-			b.set(bi, av)
+		chosen[i] = true
+		suitors = append(suitors, pop.members[i])
+	}
+	rand.Shuffle(len(suitors), func(i, j int) {
+		suitors[i], suitors[j] = suitors[j], suitors[i]
+	})
+	return suitors
+}
+
+// slots returns the indices of the members to evolve in the next generation.
+func (pop *Population) slots() []int {
+	size := len(pop.members)
+	if pop.gap <= 0 || 1 <= pop.gap {
+		slots := make([]int, size)
+		for i := range slots {
+			slots[i] = i
 		}
-		return current
+		return slots
 	}
+	n := int(pop.gap*float64(size) + 0.5)
+	if n < 1 {
+		n = 1
+	}
+	return rand.Perm(size)[:n]
 }
 
 // run implements the main goroutine.
@@ -162,35 +535,101 @@ func run(pop Population, body evo.EvolveFn) {
 		loop = make(chan struct{}, 1)
 
 		// receives the results of evolutions
-		nextgen = make(chan evo.Genome, len(pop.members))
+		offspring = make([]evo.Genome, len(pop.members))
 
 		// synchronizes pending evolutions
 		pending sync.WaitGroup
 
+		// false until the first generation has been evolved
+		started bool
+
+		// the number of generations evolved
+		generation int
+
+		// the span of the current generation
+		span evo.Span
+
 		// used to access/mutate pop.members
 		getter = make(chan int)
 		setter = make(chan int)
-		statsc = make(chan evo.Stats)
+		viewc  = make(chan evo.View)
 	)
 
-	for i := range pop.members {
-		nextgen <- pop.members[i]
-	}
 	loop <- struct{}{}
 
 	for {
 		select {
 		case <-loop:
-			for i := range pop.members {
-				pop.members[i] = <-nextgen
+			if started {
+				var old []evo.Genome
+				if pop.recyc != nil {
+					old = append(append(old, pop.members...), offspring...)
+				}
+				pop.replace(offspring)
+				generation++
+				if pop.onBarrier != nil {
+					pop.onBarrier(generation, pop.members)
+				}
+				if pop.recyc != nil {
+					kept := append(pop.members[:len(pop.members):len(pop.members)], pop.best.Best().Genome)
+					pop.recyc.Recycle(old, kept)
+				}
+				pop.gens.Store(int64(generation))
+				if pop.measured() {
+					stats, best := pop.measure()
+					stats = stats.WithGeneration(generation)
+					span.SetAttributes(
+						evo.Attr{Key: "best", Value: stats.Max()},
+						evo.Attr{Key: "mean", Value: stats.Mean()},
+					)
+					if pop.events != nil {
+						pop.events.Generation(generation, stats, func() evo.Genome {
+							return best
+						})
+					}
+					if pop.onGen != nil {
+						pop.onGen(generation, stats)
+					}
+				}
+				span.End()
+			} else {
+				// the initial members are observed in parallel, like offspring
+				pending.Add(len(pop.members))
+				for _, m := range pop.members {
+					go func(m evo.Genome) {
+						pop.best.Observe(m)
+						pending.Done()
+					}(m)
+				}
 			}
-			pending.Add(len(pop.members))
-			for i := range pop.members {
+			started = true
+			slots := pop.slots()
+			_, span = evo.StartSpan(pop.traceCtx, pop.tracer, "evo.generation",
+				evo.Attr{Key: "generation", Value: float64(generation + 1)},
+				evo.Attr{Key: "evolved", Value: float64(len(slots))},
+			)
+			for i := range offspring {
+				offspring[i] = nil
+			}
+			pending.Add(len(slots))
+			for _, i := range slots {
 				val := pop.members[i]
-				go func() {
-					nextgen <- body(val, pop.members)
+				suitors := pop.suitors()
+				go func(i int) {
+					if pop.onErr == nil {
+						offspring[i] = body(val, suitors)
+					} else if child, err := evo.SafeEvolve(body, val, suitors); err != nil {
+						pop.onErr(i, err)
+					} else {
+						offspring[i] = child
+					}
+					if offspring[i] != nil {
+						pop.best.Observe(offspring[i])
+						pop.meter.Evaluate()
+					}
+					pop.meter.Iterate()
 					pending.Done()
-				}()
+				}(i)
 			}
 			go func() {
 				pending.Wait()
@@ -205,15 +644,14 @@ func run(pop Population, body evo.EvolveFn) {
 			i := <-setter
 			pop.members[i] = <-pop.valuec
 
-		case pop.statsc <- statsc:
-			var s evo.Stats
-			for i := range pop.members {
-				s = s.Put(pop.members[i].Fitness())
-			}
-			statsc <- s
+		case pop.viewc <- viewc:
+			viewc <- evo.NewView(pop.members)
 
 		case ch := <-pop.stopc:
 			pending.Wait()
+			if span != nil {
+				span.End()
+			}
 			for i := range pop.members {
 				if subpop, ok := pop.members[i].(evo.Population); ok {
 					subpop.Stop()
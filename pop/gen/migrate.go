@@ -0,0 +1,235 @@
+package gen
+
+import (
+	"math"
+	"math/rand"
+	"reflect"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Chooser chooses the indices of n distinct members of a population for
+// migration.
+type Chooser func(members []evo.Genome, n int) []int
+
+// ChooseRandom chooses members uniformly at random.
+func ChooseRandom(members []evo.Genome, n int) []int {
+	return rand.Perm(len(members))[:n]
+}
+
+// ChooseBest chooses the most fit members.
+func ChooseBest(members []evo.Genome, n int) []int {
+	idx := worst(members, len(members))
+	return idx[len(idx)-n:]
+}
+
+// ChooseWorst chooses the least fit members.
+func ChooseWorst(members []evo.Genome, n int) []int {
+	return worst(members, n)
+}
+
+// ChooseTournament returns a Chooser which chooses each member as the winner
+// of a k-way tournament between random members which have not yet been chosen.
+func ChooseTournament(k int) Chooser {
+	return func(members []evo.Genome, n int) []int {
+		left := rand.Perm(len(members))
+		chosen := make([]int, 0, n)
+		for len(chosen) < n {
+			best := 0
+			for t := 1; t < k && t < len(left); t++ {
+				if members[left[t]].Fitness() > members[left[best]].Fitness() {
+					best = t
+				}
+			}
+			chosen = append(chosen, left[best])
+			left[best] = left[len(left)-1]
+			left = left[:len(left)-1]
+			rand.Shuffle(len(left), func(i, j int) { left[i], left[j] = left[j], left[i] })
+		}
+		return chosen
+	}
+}
+
+// A Policy describes how individuals migrate between populations.
+//
+// Each migration, N emigrants are chosen from the source population and sent to
+// a destination population, where they replace N chosen members. With move
+// semantics (the default), the replaced members are sent back to take the place
+// of the emigrants, so the populations exchange individuals. With copy
+// semantics, the emigrants remain in the source as well and the replaced
+// members are discarded, so migrants only flow towards the destination.
+type Policy struct {
+	N          int           // number of migrants per migration
+	Delay      time.Duration // delay before each migration
+	Emigrants  Chooser       // chooses the emigrants, ChooseRandom if nil
+	Immigrants Chooser       // chooses the replaced members, ChooseRandom if nil
+	Copy       bool          // copy emigrants rather than moving them
+	Clock      evo.Clock     // measures the delay, evo.SystemClock if nil
+
+	// When Directed is false, the destination is a random suitor. Otherwise
+	// the destination is always the first suitor. For example, the first
+	// neighbor of each node of a graph.Ring is the next node, so directed copy
+	// migration on a ring gives a unidirectional ring.
+	Directed bool
+}
+
+// MigrateWith returns an EvolveFn for using islands as genomes which migrates
+// individuals according to a policy. The islands may be generational
+// populations or graph populations, e.g. an island model of diffusion
+// populations, where a migrant takes over the node of the member it replaces.
+// For example, a policy where the best individuals emigrate to replace the
+// worst individuals of the destination is:
+//
+//	gen.Policy{
+//		N:          5,
+//		Delay:      1 * time.Second,
+//		Emigrants:  gen.ChooseBest,
+//		Immigrants: gen.ChooseWorst,
+//		Copy:       true,
+//	}
+//
+// See Migrate for details on building island models.
+func MigrateWith(p Policy) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		<-p.clock().After(p.Delay)
+		src := current.(Island)
+		p.migrate(src, p.destination(src, suitors))
+		return current
+	}
+}
+
+// An Adaptation adjusts a migration policy given the current statistics of the
+// source population.
+type Adaptation func(stats evo.Stats, p Policy) Policy
+
+// MigrateAdaptive returns an EvolveFn like MigrateWith, except that before each
+// migration the policy is adapted to the statistics of the source population.
+// This allows the rate and size of migrations to respond to the state of each
+// island, e.g. with DiversityBoost.
+func MigrateAdaptive(p Policy, adapt Adaptation) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		src := current.(Island)
+		q := adapt(src.Stats(), p)
+		<-q.clock().After(q.Delay)
+		q.migrate(src, q.destination(src, suitors))
+		return current
+	}
+}
+
+// DiversityBoost returns an Adaptation which migrates more when an island loses
+// diversity. While the magnitude of the relative standard deviation of the
+// fitness of the island is below the threshold, the number of migrants is
+// multiplied by the factor and the delay between migrations is divided by it.
+func DiversityBoost(threshold, factor float64) Adaptation {
+	return func(stats evo.Stats, p Policy) Policy {
+		if math.Abs(stats.RSD()) < threshold {
+			p.N = int(math.Ceil(float64(p.N) * factor))
+			p.Delay = time.Duration(float64(p.Delay) / factor)
+		}
+		return p
+	}
+}
+
+// clock returns the clock of the policy.
+func (p Policy) clock() evo.Clock {
+	if p.Clock == nil {
+		return evo.SystemClock
+	}
+	return p.Clock
+}
+
+// An Island is a population whose members can be accessed by index, so that
+// individuals can migrate to and from it. Both *Population and graph.Graph are
+// islands.
+type Island interface {
+	evo.Population
+	Len() int
+	Get(i int) evo.Genome
+	Set(i int, val evo.Genome)
+}
+
+// A recorder is an island which records the migrations from it, e.g. as spans
+// and MigrationDone events.
+type recorder interface {
+	// Migration is called before n individuals migrate from the island, and
+	// the function it returns once they have migrated.
+	Migration(n int) (done func())
+}
+
+// Migration records a migration of n individuals from the population as a span
+// of its tracer and a MigrationDone event. It is called by the migration
+// functions of this package, which call the returned function once the
+// migrants have moved.
+func (pop *Population) Migration(n int) (done func()) {
+	_, span := evo.StartSpan(pop.traceCtx, pop.tracer, "evo.migration",
+		evo.Attr{Key: "migrants", Value: float64(n)},
+	)
+	return func() {
+		span.End()
+		if pop.events != nil {
+			pop.events.Publish(evo.Event{Kind: evo.MigrationDone, Generation: pop.Generation()})
+		}
+	}
+}
+
+// destination chooses the destination of a migration among the suitors.
+func (p Policy) destination(src Island, suitors []evo.Genome) (dst Island) {
+	if p.Directed {
+		return suitors[0].(Island)
+	}
+	for dst = src; same(dst, src); {
+		dst = suitors[rand.Intn(len(suitors))].(Island)
+	}
+	return dst
+}
+
+// same returns true if two islands are the same. Islands which are slices,
+// such as graph.Graph, are compared by the address of their first element,
+// since slices are not comparable.
+func same(a, b Island) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	if va.Kind() == reflect.Slice {
+		return va.Pointer() == vb.Pointer()
+	}
+	return a == b
+}
+
+// migrate performs a single migration from src to dst.
+func (p Policy) migrate(src, dst Island) {
+	n := p.N
+	if src.Len() < n {
+		n = src.Len()
+	}
+	if dst.Len() < n {
+		n = dst.Len()
+	}
+	if r, ok := src.(recorder); ok {
+		defer r.Migration(n)()
+	}
+	out := choose(p.Emigrants, src, n)
+	in := choose(p.Immigrants, dst, n)
+	for i := range out {
+		emigrant := src.Get(out[i])
+		if !p.Copy {
+			src.Set(out[i], dst.Get(in[i]))
+		}
+		dst.Set(in[i], emigrant)
+	}
+}
+
+// choose applies a chooser to an island. The members of the island are only
+// retrieved if the chooser needs them.
+func choose(c Chooser, isl Island, n int) []int {
+	if c == nil {
+		return rand.Perm(isl.Len())[:n]
+	}
+	members := make([]evo.Genome, isl.Len())
+	for i := range members {
+		members[i] = isl.Get(i)
+	}
+	return c(members, n)
+}
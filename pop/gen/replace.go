@@ -0,0 +1,61 @@
+package gen
+
+import (
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Replacement merges a generation of offspring into the population. The
+// slices are aligned: offspring[i] is the genome returned by the EvolveFn for
+// members[i]. When the generation gap is less than 1, only some members are
+// evolved, and the offspring of the others are nil.
+type Replacement func(members, offspring []evo.Genome)
+
+// ReplaceRandom replaces each parent with its offspring. Since the parents that
+// are evolved are chosen at random when the generation gap is less than 1, this
+// replaces a random subset of the population. This is the default.
+func ReplaceRandom(members, offspring []evo.Genome) {
+	for i := range offspring {
+		if offspring[i] != nil {
+			members[i] = offspring[i]
+		}
+	}
+}
+
+// ReplaceWorst replaces the least fit members of the population with the
+// offspring. Each generation, the number of members replaced is the number of
+// offspring produced.
+func ReplaceWorst(members, offspring []evo.Genome) {
+	var kids []evo.Genome
+	for i := range offspring {
+		if offspring[i] != nil {
+			kids = append(kids, offspring[i])
+		}
+	}
+	for i, j := range worst(members, len(kids)) {
+		members[j] = kids[i]
+	}
+}
+
+// ReplaceParent replaces each parent with its offspring only if the offspring is
+// at least as fit as the parent.
+func ReplaceParent(members, offspring []evo.Genome) {
+	for i := range offspring {
+		if offspring[i] != nil && offspring[i].Fitness() >= members[i].Fitness() {
+			members[i] = offspring[i]
+		}
+	}
+}
+
+// worst returns the indices of the k least fit genomes, least fit first.
+func worst(genomes []evo.Genome, k int) []int {
+	idx := make([]int, len(genomes))
+	fit := make([]float64, len(genomes))
+	for i := range idx {
+		idx[i] = i
+		fit[i] = genomes[i].Fitness()
+	}
+	sort.Slice(idx, func(i, j int) bool { return fit[idx[i]] < fit[idx[j]] })
+	return idx[:k]
+}
@@ -4,9 +4,18 @@
 // parallel, and only sees neighboring nodes as suitors. When used as a
 // meta-population, this technique is known as the island model. When used as a
 // regular population, it is known as the diffusion model.
+//
+// A Serial evolves a graph on a single goroutine instead, for environments
+// without OS threads such as js/wasm.
 package graph
 
 import (
+	"context"
+	"math"
+	"math/rand"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/cbarrick/evo"
@@ -15,26 +24,105 @@ import (
 type Graph []node
 
 type node struct {
-	val    *evo.Genome
+	val    *evo.Genome                 // the slot of the node in the members
+	cur    *atomic.Pointer[evo.Genome] // the current value, for lock-free reads
 	peers  []*node
-	getc   chan chan evo.Genome
+	hood   []*node   // the candidate suitors when not the peers
+	weight []float64 // the mating probability of each candidate, nil for all
+	walks  int       // the number of random walks, 0 to disable
+	walk   int       // the length of each random walk
+	delay  func() time.Duration
+	clock  *barrier      // non-nil for synchronous updates
+	meter  *evo.Meter    // shared by all nodes
+	hooks  *hooks        // shared by all nodes, nil without callbacks
+	idx    int           // the index of the node
+	iters  *atomic.Int64 // the number of iterations of the node
+	best   *evo.Tracker  // shared by all nodes
 	setc   chan chan evo.Genome
 	closec chan chan struct{}
-	done   chan struct{}
+	done   chan struct{} // closed once the node stops
 }
 
-// Grid creates a new graph population arranged as a 2D grid.
-func Grid(size int) Graph {
-	width := size << 1
-	layout := make([][]int, size)
-	for i := range layout {
-		layout[i] = make([]int, 4)
-		layout[i][0] = (i + 1 + size) % size
-		layout[i][1] = (i - 1 + size) % size
-		layout[i][2] = (i + width + size) % size
-		layout[i][3] = (i - width + size) % size
+// A Neighborhood describes which cells of a grid are adjacent to one another.
+type Neighborhood struct {
+	Radius int  // the maximum distance between neighbors
+	Moore  bool // measure distance by Chebyshev rather than Manhattan distance
+}
+
+// VonNeumann returns the von Neumann neighborhood of radius k: all cells within
+// a Manhattan distance of k. With k = 1, these are the four cells above, below,
+// left, and right.
+func VonNeumann(k int) Neighborhood {
+	return Neighborhood{Radius: k}
+}
+
+// Moore returns the Moore neighborhood of radius k: all cells within a Chebyshev
+// distance of k. With k = 1, these are the eight surrounding cells.
+func Moore(k int) Neighborhood {
+	return Neighborhood{Radius: k, Moore: true}
+}
+
+// contains returns true if the offset (dr,dc) is within the neighborhood.
+func (hood Neighborhood) contains(dr, dc int) bool {
+	if dr < 0 {
+		dr = -dr
 	}
-	return Custom(layout)
+	if dc < 0 {
+		dc = -dc
+	}
+	if hood.Moore {
+		return dr <= hood.Radius && dc <= hood.Radius
+	}
+	return dr+dc <= hood.Radius
+}
+
+// lattice returns the layout of a 2D grid. Cells are numbered in row-major
+// order. If wrap is true, the edges of the grid wrap around.
+func lattice(rows, cols int, hood Neighborhood, wrap bool) [][]int {
+	layout := make([][]int, rows*cols)
+	for r := 0; r < rows; r++ {
+		for c := 0; c < cols; c++ {
+			i := r*cols + c
+			seen := map[int]bool{i: true}
+			for dr := -hood.Radius; dr <= hood.Radius; dr++ {
+				for dc := -hood.Radius; dc <= hood.Radius; dc++ {
+					if !hood.contains(dr, dc) {
+						continue
+					}
+					nr, nc := r+dr, c+dc
+					if wrap {
+						nr = ((nr % rows) + rows) % rows
+						nc = ((nc % cols) + cols) % cols
+					} else if nr < 0 || rows <= nr || nc < 0 || cols <= nc {
+						continue
+					}
+					if j := nr*cols + nc; !seen[j] {
+						seen[j] = true
+						layout[i] = append(layout[i], j)
+					}
+				}
+			}
+		}
+	}
+	return layout
+}
+
+// Grid creates a new graph population arranged as a 2D grid with the given
+// number of rows and columns. Each node is connected to the cells of its
+// neighborhood, e.g. VonNeumann(1) or Moore(2). The edges of the grid do not
+// wrap around, so nodes near the border have fewer neighbors; see Torus for a
+// grid without borders.
+func Grid(rows, cols int, hood Neighborhood) Graph {
+	return Custom(lattice(rows, cols, hood, false))
+}
+
+// Torus creates a new graph population arranged as a 2D toroidal grid with the
+// given number of rows and columns. Each node is connected to its von Neumann
+// neighborhood: the nodes above, below, left, and right of it. The edges of the
+// grid wrap around, so every node has exactly four neighbors (fewer when the
+// grid is too small for them to be distinct).
+func Torus(rows, cols int) Graph {
+	return Custom(lattice(rows, cols, VonNeumann(1), true))
 }
 
 // Hypercube creates a new graph population arranged as a hypercube.
@@ -53,17 +141,160 @@ func Hypercube(size int) Graph {
 	return Custom(layout)
 }
 
-// Ring creates a new graph population arranged as a ring.
+// Ring creates a new graph population arranged as a ring. The first neighbor
+// of each node is the next node around the ring. Rings of fewer than 3 nodes
+// have no self or duplicate links, so a ring of 2 nodes is a single edge and a
+// ring of 1 node is isolated.
 func Ring(size int) Graph {
 	layout := make([][]int, size)
 	for i := 0; i < size; i++ {
-		layout[i] = make([]int, 2)
-		layout[i][0] = (i - 1 + size) % size
-		layout[i][0] = (i + 1) % size
+		next, prev := (i+1)%size, (i-1+size)%size
+		if next != i {
+			layout[i] = append(layout[i], next)
+		}
+		if prev != i && prev != next {
+			layout[i] = append(layout[i], prev)
+		}
 	}
 	return Custom(layout)
 }
 
+// Complete creates a new graph population where every node is connected to
+// every other node. A complete graph behaves like a panmictic population.
+func Complete(size int) Graph {
+	layout := make([][]int, size)
+	for i := range layout {
+		layout[i] = make([]int, 0, size-1)
+		for j := 0; j < size; j++ {
+			if i != j {
+				layout[i] = append(layout[i], j)
+			}
+		}
+	}
+	return Custom(layout)
+}
+
+// RandomRegular creates a new graph population where the edges are random but
+// every node has exactly the given degree. The product of the size and degree
+// must be even, and the degree must be less than the size.
+//
+// The graph is generated by repeatedly joining random pairs of unfilled nodes
+// that are not yet adjacent, restarting if the process gets stuck. This
+// samples approximately uniformly from the regular graphs when the degree is
+// small relative to the size.
+func RandomRegular(size, degree int) Graph {
+	if size*degree%2 != 0 || size <= degree {
+		panic("impossible regular graph")
+	}
+	for {
+		if adj, ok := tryRegular(size, degree); ok {
+			return Custom(adj.layout())
+		}
+	}
+}
+
+// tryRegular attempts to generate a random regular graph.
+func tryRegular(size, degree int) (adj adjacency, ok bool) {
+	adj = newAdjacency(size)
+
+	// stubs lists each node once for each missing edge
+	stubs := make([]int, 0, size*degree)
+	for i := 0; i < size; i++ {
+		for j := 0; j < degree; j++ {
+			stubs = append(stubs, i)
+		}
+	}
+
+	for len(stubs) > 0 {
+		// find a suitable pair of stubs, giving up after many failures
+		var x, y int
+		found := false
+		for tries := 0; tries < 100 && !found; tries++ {
+			x = rand.Intn(len(stubs))
+			y = rand.Intn(len(stubs))
+			found = stubs[x] != stubs[y] && !adj[stubs[x]][stubs[y]]
+		}
+		if !found {
+			return nil, false
+		}
+		adj.link(stubs[x], stubs[y])
+
+		// remove the two stubs
+		if x < y {
+			x, y = y, x
+		}
+		stubs[x] = stubs[len(stubs)-1]
+		stubs = stubs[:len(stubs)-1]
+		stubs[y] = stubs[len(stubs)-1]
+		stubs = stubs[:len(stubs)-1]
+	}
+	return adj, true
+}
+
+// SmallWorld creates a new graph population with a small-world topology using
+// the Watts-Strogatz model. The nodes are first arranged in a ring lattice where
+// each node is connected to its k nearest neighbors on either side. Then each
+// edge is rewired to a uniformly random node with probability beta, avoiding
+// self-loops and duplicate edges. With beta = 0, the result is a ring lattice,
+// and with beta = 1 it approaches a random graph. Small values of beta give
+// short average path lengths while preserving the local clustering of the
+// lattice.
+func SmallWorld(size, k int, beta float64) Graph {
+	adj := newAdjacency(size)
+	for i := 0; i < size; i++ {
+		for j := 1; j <= k; j++ {
+			adj.link(i, (i+j)%size)
+		}
+	}
+	for j := 1; j <= k; j++ {
+		for i := 0; i < size; i++ {
+			old := (i + j) % size
+			if rand.Float64() >= beta || !adj[i][old] || len(adj[i]) >= size-1 {
+				continue
+			}
+			next := rand.Intn(size)
+			for next == i || adj[i][next] {
+				next = rand.Intn(size)
+			}
+			adj.unlink(i, old)
+			adj.link(i, next)
+		}
+	}
+	return Custom(adj.layout())
+}
+
+// ScaleFree creates a new graph population with a scale-free topology using the
+// Barabási-Albert model of preferential attachment. The graph starts as a
+// complete graph of m+1 nodes. Each remaining node is then connected to m
+// distinct existing nodes, chosen with probability proportional to their
+// degree. The resulting degree distribution follows a power law: most nodes
+// have few neighbors while a few hubs have very many.
+func ScaleFree(size, m int) Graph {
+	adj := newAdjacency(size)
+
+	// ends lists each node once for every edge incident to it
+	// sampling uniformly from ends is sampling proportional to degree
+	var ends []int
+
+	for i := 0; i <= m && i < size; i++ {
+		for j := 0; j < i; j++ {
+			adj.link(i, j)
+			ends = append(ends, i, j)
+		}
+	}
+	for i := m + 1; i < size; i++ {
+		targets := make(map[int]bool, m)
+		for len(targets) < m {
+			targets[ends[rand.Intn(len(ends))]] = true
+		}
+		for j := range targets {
+			adj.link(i, j)
+			ends = append(ends, i, j)
+		}
+	}
+	return Custom(adj.layout())
+}
+
 // Custom creates a new graph population with a custom layout.
 // The layout is specified as an adjacency list.
 func Custom(layout [][]int) Graph {
@@ -71,21 +302,199 @@ func Custom(layout [][]int) Graph {
 	for i := range g {
 		peers := make([]*node, len(layout[i]))
 		for j := range layout[i] {
-			peers[j] = &g[j]
+			peers[j] = &g[layout[i][j]]
 		}
 		g[i].peers = peers
 	}
 	return g
 }
 
-// Stats returns statistics on the fitness of genomes in the population.
+// An adjacency is an undirected graph under construction.
+type adjacency []map[int]bool
+
+// newAdjacency returns an adjacency of n nodes without edges.
+func newAdjacency(n int) adjacency {
+	adj := make(adjacency, n)
+	for i := range adj {
+		adj[i] = make(map[int]bool)
+	}
+	return adj
+}
+
+// link adds an edge between i and j.
+func (adj adjacency) link(i, j int) {
+	if i != j {
+		adj[i][j] = true
+		adj[j][i] = true
+	}
+}
+
+// unlink removes the edge between i and j.
+func (adj adjacency) unlink(i, j int) {
+	delete(adj[i], j)
+	delete(adj[j], i)
+}
+
+// layout returns the adjacency as a sorted adjacency list.
+func (adj adjacency) layout() [][]int {
+	layout := make([][]int, len(adj))
+	for i := range adj {
+		layout[i] = make([]int, 0, len(adj[i]))
+		for j := range adj[i] {
+			layout[i] = append(layout[i], j)
+		}
+		sort.Ints(layout[i])
+	}
+	return layout
+}
+
+// Stats returns statistics on the fitness of genomes in the population, tagged
+// with the current generation. With SetStatsTTL, the statistics may be cached.
+// Before Evolve, the statistics are empty.
 func (g Graph) Stats() (s evo.Stats) {
-	for i := range g {
-		s = s.Put(g[i].get().Fitness())
+	if len(g) > 0 && g[0].hooks != nil && g[0].hooks.statsTTL > 0 {
+		return g[0].hooks.cachedStats(g)
 	}
+	return g.stats()
+}
+
+// stats computes the statistics of the population.
+func (g Graph) stats() (s evo.Stats) {
+	gen := g.Generation()
+	v := g.View()
+	s = v.Stats().WithGeneration(gen)
+	v.Close()
 	return s
 }
 
+// IslandStats returns the statistics of the value of each node which is itself
+// a population, e.g. the islands of an island model, in the order of the nodes.
+// It returns nil if no value is a population. See evo.View.SubStats.
+func (g Graph) IslandStats() []evo.Stats {
+	v := g.View()
+	defer v.Close()
+	return v.SubStats()
+}
+
+// SetStatsTTL configures the graph to cache the result of Stats, and so of
+// Fitness, for the given duration. Computing the statistics reads every node,
+// so without a cache, tight polling loops and many concurrent callers, e.g.
+// the Poll conditions of an island model, repeat the scan of the whole graph.
+// With a cache, concurrent callers share a single scan, and the statistics may
+// be stale by up to the TTL. A TTL of 0 disables the cache. SetStatsTTL must be
+// called before Evolve.
+func (g Graph) SetStatsTTL(ttl time.Duration) {
+	g.getHooks().statsTTL = ttl
+}
+
+// cachedStats returns the cached statistics, recomputing them once stale.
+func (h *hooks) cachedStats(g Graph) evo.Stats {
+	h.statsMu.Lock()
+	defer h.statsMu.Unlock()
+	if now := h.getClock().Now(); h.statsAt.IsZero() || h.statsTTL <= now.Sub(h.statsAt) {
+		h.stats = g.stats()
+		h.statsAt = now
+	}
+	return h.stats
+}
+
+// Best returns the record of the best genome observed since Evolve was called,
+// including genomes which were later replaced. The initial value of each node
+// is observed when the evolution starts, and the result of every iteration
+// after it is computed, so each genome is evaluated once by the tracker. It is
+// safe to call while the population is evolving and after it stops.
+func (g Graph) Best() evo.Record {
+	if len(g) == 0 || g[0].best == nil {
+		return evo.Record{}
+	}
+	return g[0].best.Best()
+}
+
+// Iterations returns the number of times the value of a node has been replaced
+// by its EvolveFn since Evolve was called.
+func (g Graph) Iterations(node int) int {
+	if g[node].iters == nil {
+		return 0
+	}
+	return int(g[node].iters.Load())
+}
+
+// Generation returns the number of sweeps of the graph since Evolve was called,
+// i.e. the total number of iterations of all nodes divided by the number of
+// nodes. In a synchronous graph a sweep is exactly one generation. It is safe
+// to call while the population is evolving.
+func (g Graph) Generation() int {
+	if len(g) == 0 {
+		return 0
+	}
+	var total int
+	for i := range g {
+		total += g.Iterations(i)
+	}
+	return total / len(g)
+}
+
+// View returns a snapshot of the members of the population. Each member is
+// read from its node in turn, so the snapshot is not atomic while the
+// population is evolving. Before Evolve, the view is empty.
+func (g Graph) View() evo.View {
+	if len(g) == 0 || g[0].cur == nil {
+		return evo.NewView(nil)
+	}
+	return evo.BuildView(len(g), func(i int) evo.Genome {
+		return g[i].get()
+	})
+}
+
+// Get returns the value of the ith node. Like the reads of neighbors, it does
+// not synchronize with the goroutine of the node, so it is cheap enough for
+// external controllers to call while the population is evolving. Get panics if
+// called before Evolve.
+func (g Graph) Get(i int) evo.Genome {
+	if g[i].cur == nil {
+		panic("graph: node accessed before Evolve")
+	}
+	return g[i].get()
+}
+
+// Set replaces the value of the ith node. While the population is evolving, the
+// value is replaced by the goroutine of the node in the same way as migrants,
+// so that custom migration schemes can place specific members, and an
+// iteration of the node in progress may replace it in turn. Set panics if
+// called before Evolve.
+func (g Graph) Set(i int, val evo.Genome) {
+	if g[i].setc == nil {
+		panic("graph: node accessed before Evolve")
+	}
+	g[i].set(val)
+}
+
+// Len returns the number of nodes of the graph.
+func (g Graph) Len() int {
+	return len(g)
+}
+
+// Migration records a migration of n individuals from the graph as a span of
+// its tracer and a MigrationDone event, like gen.Population.Migration, so that
+// graphs can be the islands of gen.MigrateWith.
+func (g Graph) Migration(n int) (done func()) {
+	h := g[0].hooks
+	span := h.span("evo.migration", evo.Attr{Key: "migrants", Value: float64(n)})
+	return func() {
+		span.End()
+		if h != nil && h.events != nil {
+			h.events.Publish(evo.Event{Kind: evo.MigrationDone, Generation: g.Generation()})
+		}
+	}
+}
+
+// Throughput returns the throughput of the population since Evolve was called.
+// Each call to the EvolveFn by any node counts as an iteration, and each genome
+// it returns as an evaluation.
+func (g Graph) Throughput() evo.Throughput {
+	return g[0].meter.Throughput()
+}
+
 // Fitness returns the maximum fitness within the population.
 func (g Graph) Fitness() float64 {
 	return g.Stats().Max()
@@ -93,26 +502,362 @@ func (g Graph) Fitness() float64 {
 
 // Evolve starts the optimization in a separate goroutine.
 func (g Graph) Evolve(members []evo.Genome, body evo.EvolveFn) {
+	meter := evo.NewMeter()
+	best := evo.NewTracker(meter)
+	if h := g[0].hooks; h != nil {
+		h.start(g)
+	}
 	for i := range g {
+		g[i].meter = meter
+		g[i].best = best
+		g[i].idx = i
+		g[i].iters = new(atomic.Int64)
 		g[i].val = &members[i]
-		g[i].getc = make(chan chan evo.Genome)
+		g[i].cur = new(atomic.Pointer[evo.Genome])
+		val := members[i]
+		g[i].cur.Store(&val)
 		g[i].setc = make(chan chan evo.Genome)
 		g[i].closec = make(chan chan struct{}, 1)
+		g[i].done = make(chan struct{})
 	}
 	for i := range g {
 		go g[i].run(body)
 	}
 }
 
+// SetDelay sets a fixed delay before each iteration of every node. Delays can be
+// used to rate-limit the evolution, e.g. to control the frequency of migrations
+// in an island model. SetDelay must be called before Evolve.
+func (g Graph) SetDelay(delay time.Duration) {
+	g.SetDelayFunc(func() time.Duration { return delay })
+}
+
+// SetDelayFunc sets a function which is called before each iteration of every
+// node to determine the delay before that iteration. The function may be called
+// concurrently. SetDelayFunc must be called before Evolve.
+func (g Graph) SetDelayFunc(delay func() time.Duration) {
+	for i := range g {
+		g[i].delay = delay
+	}
+}
+
+// SetWeights assigns a weight to each edge of the graph, interpreted as the
+// probability that the neighbor is a suitor in an iteration of the node. The
+// weight function receives the indices of the node and of its neighbor. Each
+// iteration, every neighbor is included independently with the probability of
+// its edge. If no neighbor is included, a single neighbor is chosen with
+// probability proportional to the weights. Neighbors of weight 0 are never
+// suitors, so a node whose weights are all 0 has none. Weights give soft
+// topologies; e.g. a large Moore neighborhood with weights from GridDecay
+// interacts mostly locally without every iteration seeing every neighbor.
+// SetWeights must be called before Evolve.
+func (g Graph) SetWeights(weight func(node, peer int) float64) {
+	index := g.index()
+	for i := range g {
+		hood := g[i].candidates()
+		g[i].weight = make([]float64, len(hood))
+		for j, peer := range hood {
+			g[i].weight[j] = weight(i, index[peer])
+		}
+	}
+}
+
+// SetRadius configures each node to draw its suitors from every node within k
+// hops of it, found by breadth-first search over the topology, rather than from
+// its direct neighbors only. Larger radii lower the selection pressure of
+// local competition. The default radius is 1. SetRadius must be called before
+// SetWeights and Evolve.
+func (g Graph) SetRadius(k int) {
+	for i := range g {
+		g[i].hood = nil
+		if k == 1 {
+			continue
+		}
+		seen := map[*node]bool{&g[i]: true}
+		frontier := []*node{&g[i]}
+		for hop := 0; hop < k && len(frontier) > 0; hop++ {
+			var next []*node
+			for _, n := range frontier {
+				for _, peer := range n.peers {
+					if !seen[peer] {
+						seen[peer] = true
+						next = append(next, peer)
+					}
+				}
+			}
+			g[i].hood = append(g[i].hood, next...)
+			frontier = next
+		}
+	}
+}
+
+// SetWalk configures each node to draw its suitors by random walks over the
+// topology rather than from a fixed neighborhood. Each iteration, n walks of
+// the given length start at the node, and the node at the end of each walk is
+// a suitor. A walk which ends at its own start is replaced by a random direct
+// neighbor. Walks override the radius and weights of the graph. SetWalk must be
+// called before Evolve.
+func (g Graph) SetWalk(n, length int) {
+	for i := range g {
+		g[i].walks = n
+		g[i].walk = length
+	}
+}
+
+// index maps the nodes of the graph to their indices.
+func (g Graph) index() map[*node]int {
+	index := make(map[*node]int, len(g))
+	for i := range g {
+		index[&g[i]] = i
+	}
+	return index
+}
+
+// GridDecay returns a weight function for a grid or torus of the given size
+// which decays exponentially with the Euclidean distance between cells. Cells
+// at distance 1 have weight 1, and the weight is divided by e for every scale
+// units of further distance. Distances are measured around the edges of the
+// grid when that is shorter, as on a torus.
+func GridDecay(rows, cols int, scale float64) func(node, peer int) float64 {
+	wrap := func(d, size int) float64 {
+		if d < 0 {
+			d = -d
+		}
+		if size-d < d {
+			d = size - d
+		}
+		return float64(d)
+	}
+	return func(node, peer int) float64 {
+		dr := wrap(node/cols-peer/cols, rows)
+		dc := wrap(node%cols-peer%cols, cols)
+		return math.Exp(-(math.Hypot(dr, dc) - 1) / scale)
+	}
+}
+
+// SetSync switches the graph between asynchronous and synchronous updates. By
+// default, each node evolves at its own pace and replaces its value as soon as
+// its EvolveFn returns. With synchronous updates, the nodes evolve in lockstep
+// generations: every node computes its replacement from the values of the
+// previous generation, and all replacements take effect at once. Synchronous
+// updates are slower, since every generation waits for the slowest node, but
+// are needed to reproduce the dynamics of synchronous cellular EAs. SetSync
+// must be called before Evolve.
+func (g Graph) SetSync(sync bool) {
+	var clock *barrier
+	if sync {
+		clock = newBarrier(len(g))
+	}
+	for i := range g {
+		g[i].clock = clock
+	}
+}
+
+// OnIteration sets a callback which is called once per sweep of the graph,
+// i.e. after every len(g) replacements, with the number of sweeps so far and
+// the statistics of the population. In a synchronous graph a sweep is exactly
+// one generation; in an asynchronous graph it is one generation on average.
+// The callback runs on its own goroutine, so it may call methods of the
+// population, but sweeps which complete while it is running are skipped rather
+// than queued. OnIteration must be called before Evolve.
+func (g Graph) OnIteration(fn func(sweep int, stats evo.Stats)) {
+	g.getHooks().onIter = fn
+}
+
+// OnReplace sets a callback which is called whenever the EvolveFn of a node
+// returns, before its result replaces the value of the node. The callback
+// receives the index of the node and the old and new genomes, which are the
+// same genome when the EvolveFn keeps the current value. It is called
+// concurrently by many nodes, so it must be safe for concurrent use and should
+// return quickly. OnReplace must be called before Evolve.
+func (g Graph) OnReplace(fn func(node int, old, new evo.Genome)) {
+	g.getHooks().onReplace = fn
+}
+
+// OnError configures the graph to recover panics raised by the EvolveFn, or by
+// the fitness function of the genome it returns. The callback receives the
+// index of the node and a *evo.PanicError, and the node keeps its current value
+// for that iteration. The callback is called concurrently by many nodes.
+// Without a callback, a panic crashes the program. OnError must be called
+// before Evolve.
+func (g Graph) OnError(fn func(node int, err error)) {
+	g.getHooks().onErr = fn
+}
+
+// Subscribe registers a channel to receive the events of the population:
+// NewBest and GenerationDone after each sweep, Stagnation, Stopped, and
+// MigrationDone when the graph is an island. Events are dropped rather than
+// delaying the evolution when the channel is full. Subscribe must be called
+// before Evolve.
+func (g Graph) Subscribe(ch chan<- evo.Event) {
+	h := g.getHooks()
+	if h.events == nil {
+		h.events = new(evo.Events)
+	}
+	h.events.Subscribe(ch)
+}
+
+// SetStagnation configures the graph to publish a Stagnation event once the
+// best fitness has not improved for n sweeps. SetStagnation must be called
+// before Evolve.
+func (g Graph) SetStagnation(n int) {
+	h := g.getHooks()
+	if h.events == nil {
+		h.events = new(evo.Events)
+	}
+	h.events.SetPatience(n)
+}
+
+// SetTracer configures the graph to record a span for each iteration of each
+// node, annotated with the index of the node. When the graph is an island, each
+// migration from it is recorded as a span as well. The spans are children of
+// the given context. SetTracer must be called before Evolve.
+func (g Graph) SetTracer(ctx context.Context, t evo.Tracer) {
+	h := g.getHooks()
+	h.traceCtx = ctx
+	h.tracer = t
+}
+
+// SetClock configures the graph to measure the delays of SetDelay, the
+// frequency of Poll, including the Poll of a Serial, and the TTL of
+// SetStatsTTL, and to time its events, with the given clock rather than the
+// system clock, e.g. an evo.FakeClock in tests. SetClock must be called before
+// Evolve.
+func (g Graph) SetClock(c evo.Clock) {
+	g.getHooks().clock = c
+}
+
+// getClock returns the clock of the graph.
+func (h *hooks) getClock() evo.Clock {
+	if h == nil || h.clock == nil {
+		return evo.SystemClock
+	}
+	return h.clock
+}
+
+// span starts a span with the tracer of the graph, if any.
+func (h *hooks) span(name string, attrs ...evo.Attr) evo.Span {
+	if h == nil {
+		_, span := evo.StartSpan(nil, nil, name)
+		return span
+	}
+	_, span := evo.StartSpan(h.traceCtx, h.tracer, name, attrs...)
+	return span
+}
+
+// getHooks returns the hooks shared by the nodes, creating them if needed.
+func (g Graph) getHooks() *hooks {
+	if len(g) == 0 {
+		return new(hooks)
+	}
+	if g[0].hooks == nil {
+		h := new(hooks)
+		for i := range g {
+			g[i].hooks = h
+		}
+	}
+	return g[0].hooks
+}
+
+// hooks holds the user callbacks of a graph.
+type hooks struct {
+	onIter    func(sweep int, stats evo.Stats)
+	onReplace func(node int, old, new evo.Genome)
+	onErr     func(node int, err error)
+
+	events   *evo.Events     // publishes events to subscribers, may be nil
+	tracer   evo.Tracer      // records iterations and migrations
+	traceCtx context.Context // the parent of the spans
+	clock    evo.Clock       // measures delays and polling, nil for the system clock
+
+	statsTTL time.Duration // how long Stats are cached, 0 for no cache
+	statsMu  sync.Mutex
+	statsAt  time.Time // when the cached stats were computed, zero if none
+	stats    evo.Stats
+
+	size    int64         // the number of nodes
+	count   atomic.Int64  // replacements so far
+	signalc chan struct{} // signals the end of a sweep to the notifier
+	quitc   chan struct{} // stops the notifier
+}
+
+// start resets the counters and the cached stats, and starts the notifier
+// goroutine.
+func (h *hooks) start(g Graph) {
+	h.size = int64(len(g))
+	h.count.Store(0)
+	h.statsMu.Lock()
+	h.statsAt = time.Time{}
+	h.statsMu.Unlock()
+	if h.events != nil {
+		h.events.SetClock(h.clock)
+	}
+	if h.onIter == nil && h.events == nil {
+		return
+	}
+	h.signalc = make(chan struct{}, 1)
+	h.quitc = make(chan struct{})
+	go func(signalc, quitc chan struct{}) {
+		for {
+			select {
+			case <-signalc:
+				sweep := int(h.count.Load() / h.size)
+				v := g.View()
+				stats := v.Stats().WithGeneration(sweep)
+				if h.events != nil {
+					h.events.Generation(sweep, stats, func() evo.Genome {
+						return v.TopK(1)[0]
+					})
+				}
+				v.Close()
+				if h.onIter != nil {
+					h.onIter(sweep, stats)
+				}
+			case <-quitc:
+				return
+			}
+		}
+	}(h.signalc, h.quitc)
+}
+
+// replaced counts a replacement and signals the notifier after each sweep.
+func (h *hooks) replaced() {
+	if h == nil || h.signalc == nil {
+		return
+	}
+	if h.count.Add(1)%h.size == 0 {
+		select {
+		case h.signalc <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// stop stops the notifier goroutine and publishes the Stopped event.
+func (h *hooks) stop(g Graph) {
+	if h == nil {
+		return
+	}
+	if h.quitc != nil {
+		close(h.quitc)
+		h.quitc = nil
+	}
+	if h.events != nil {
+		h.events.Publish(evo.Event{Kind: evo.Stopped, Generation: g.Generation()})
+	}
+}
+
 // Stop terminates the optimization.
 func (g Graph) Stop() {
 	ch := make(chan struct{})
 	for i := range g {
 		g[i].closec <- ch
 		<-ch
-		close(g[i].getc)
 		close(g[i].setc)
 	}
+	if len(g) > 0 {
+		g[0].hooks.stop(g)
+	}
 }
 
 // Poll executes a function at some frequency for the duration of the
@@ -120,10 +865,11 @@ func (g Graph) Stop() {
 // is halted.
 func (g Graph) Poll(freq time.Duration, cond evo.ConditionFn) {
 	done := g[0].closec
+	clock := g[0].hooks.getClock()
 	go func() {
 		for {
 			select {
-			case <-time.After(freq):
+			case <-clock.After(freq):
 				if cond() {
 					g.Stop()
 					return
@@ -136,6 +882,16 @@ func (g Graph) Poll(freq time.Duration, cond evo.ConditionFn) {
 	}()
 }
 
+// PollStall stops the optimization once the best-so-far fitness of the
+// population has stalled, checking at the given frequency. A stall without a
+// clock measures its duration by the clock of the graph. See evo.Stall.
+func (g Graph) PollStall(freq time.Duration, stall evo.Stall) {
+	if stall.Clock == nil && len(g) != 0 {
+		stall.Clock = g[0].hooks.getClock()
+	}
+	g.Poll(freq, stall.Condition(g))
+}
+
 // Wait blocks until the evolution terminates.
 func (g Graph) Wait() {
 	for i := range g {
@@ -147,13 +903,141 @@ func (n node) wait() {
 	n.closec <- <-n.closec
 }
 
-// get returns the genome underlying the node.
+// suitors returns the values of the neighbors which are suitors in the next
+// iteration of the node.
+func (n node) suitors() []evo.Genome {
+	if 0 < n.walks && 0 < len(n.peers) {
+		suitors := make([]evo.Genome, n.walks)
+		for i := range suitors {
+			end := n.peers[rand.Intn(len(n.peers))]
+			for step := 1; step < n.walk && 0 < len(end.peers); step++ {
+				end = end.peers[rand.Intn(len(end.peers))]
+			}
+			if end.idx == n.idx {
+				end = n.peers[rand.Intn(len(n.peers))]
+			}
+			suitors[i] = end.get()
+		}
+		return suitors
+	}
+
+	hood := n.candidates()
+	if n.weight == nil {
+		suitors := make([]evo.Genome, len(hood))
+		for i := range hood {
+			suitors[i] = hood[i].get()
+		}
+		return suitors
+	}
+
+	var suitors []evo.Genome
+	var total float64
+	for i := range hood {
+		if 0 < n.weight[i] {
+			total += n.weight[i]
+		}
+		if rand.Float64() < n.weight[i] {
+			suitors = append(suitors, hood[i].get())
+		}
+	}
+	if len(suitors) == 0 && 0 < total {
+		x := rand.Float64() * total
+		pick := -1
+		for i := range hood {
+			if 0 < n.weight[i] {
+				pick = i
+				if x < n.weight[i] {
+					break
+				}
+				x -= n.weight[i]
+			}
+		}
+		suitors = append(suitors, hood[pick].get())
+	}
+	return suitors
+}
+
+// candidates returns the nodes from which the suitors of the node are drawn.
+func (n node) candidates() []*node {
+	if n.hood != nil {
+		return n.hood
+	}
+	return n.peers
+}
+
+// get returns the genome underlying the node. Reads do not synchronize with
+// the goroutine of the node, since neighbor reads dominate the cost of each
+// iteration in large graphs.
 func (n node) get() evo.Genome {
-	getter := <-n.getc
-	if getter == nil {
-		return *n.val
+	return *n.cur.Load()
+}
+
+// store replaces the genome underlying the node. It must only be called by the
+// goroutine of the node, or once the node has stopped.
+func (n node) store(val evo.Genome) {
+	*n.val = val
+	n.cur.Store(&val)
+}
+
+// A barrier synchronizes the nodes of a synchronous graph.
+type barrier struct {
+	mu    sync.Mutex
+	size  int           // the number of nodes
+	count int           // the number of nodes which have arrived
+	done  chan struct{} // closed once every node has arrived
+}
+
+// newBarrier returns a barrier for n nodes.
+func newBarrier(n int) *barrier {
+	return &barrier{size: n, done: make(chan struct{})}
+}
+
+// arrive registers the arrival of a node at the barrier and returns a channel
+// which is closed once every node has arrived. The barrier then resets.
+func (b *barrier) arrive() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	done := b.done
+	b.count++
+	if b.count == b.size {
+		close(done)
+		b.count = 0
+		b.done = make(chan struct{})
 	}
-	return <-getter
+	return done
+}
+
+// set replaces the genome underlying the node.
+func (n node) set(val evo.Genome) {
+	setter := <-n.setc
+	if setter == nil {
+		n.store(val)
+		return
+	}
+	setter <- val
+}
+
+// evolve computes the replacement of the value of the node, calling the hooks.
+func (n node) evolve(body evo.EvolveFn, val evo.Genome) (next evo.Genome) {
+	span := n.hooks.span("evo.iteration", evo.Attr{Key: "node", Value: float64(n.idx)})
+	defer span.End()
+	suiters := n.suitors()
+	var err error
+	if n.hooks == nil || n.hooks.onErr == nil {
+		next = body(val, suiters)
+	} else if next, err = evo.SafeEvolve(body, val, suiters); err != nil {
+		n.hooks.onErr(n.idx, err)
+		next = val
+	}
+	n.meter.Iterate()
+	if err == nil {
+		n.best.Observe(next)
+		n.meter.Evaluate()
+	}
+	if n.hooks != nil && n.hooks.onReplace != nil {
+		n.hooks.onReplace(n.idx, val, next)
+	}
+	return next
 }
 
 // The main goroutine.
@@ -162,32 +1046,80 @@ func (n node) run(body evo.EvolveFn) {
 		// drives the main loop
 		loop = make(chan struct{}, 1)
 
-		// used to access/mutate the value
-		getter = make(chan evo.Genome)
+		// fires when the delay before the next iteration has passed
+		wake <-chan time.Time
+
+		// used to mutate the value
 		setter = make(chan evo.Genome)
+		putter = make(chan evo.Genome)
+
+		// used for synchronous updates
+		staged    = make(chan evo.Genome) // receives the next value
+		next      evo.Genome              // the next value
+		computed  <-chan struct{}         // closed once every node has computed
+		committed <-chan struct{}         // closed once every node has committed
 	)
 
+	n.best.Observe(n.get())
+
+	// the result of an iteration is dropped once the node stops
+	evolve := func(val evo.Genome) {
+		next := n.evolve(body, val)
+		if n.clock != nil {
+			select {
+			case staged <- next:
+			case <-n.done:
+			}
+			return
+		}
+		select {
+		case setter <- next:
+			loop <- struct{}{}
+		case <-n.done:
+		}
+	}
+
 	loop <- struct{}{}
 
 	for {
 		select {
 		case <-loop:
-			go func() {
-				suiters := make([]evo.Genome, len(n.peers))
-				for i := range n.peers {
-					suiters[i] = n.peers[i].get()
-				}
-				setter <- body(*n.val, suiters)
-				loop <- struct{}{}
-			}()
+			if n.delay != nil {
+				wake = n.hooks.getClock().After(n.delay())
+			} else {
+				go evolve(n.get())
+			}
+
+		case <-wake:
+			wake = nil
+			go evolve(n.get())
+
+		case n.setc <- putter:
+			n.store(<-putter)
+
+		case val := <-setter:
+			n.store(val)
+			n.iters.Add(1)
+			n.hooks.replaced()
+
+		case next = <-staged:
+			computed = n.clock.arrive()
 
-		case n.getc <- getter:
-			getter <- *n.val
+		case <-computed:
+			computed = nil
+			n.store(next)
+			next = nil
+			committed = n.clock.arrive()
 
-		case *n.val = <-setter:
+		case <-committed:
+			committed = nil
+			n.iters.Add(1)
+			n.hooks.replaced()
+			loop <- struct{}{}
 
 		case ch := <-n.closec:
-			if subpop, ok := (*n.val).(evo.Population); ok {
+			close(n.done)
+			if subpop, ok := n.get().(evo.Population); ok {
 				subpop.Stop()
 			}
 			ch <- struct{}{}
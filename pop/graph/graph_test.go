@@ -0,0 +1,607 @@
+package graph_test
+
+import (
+	"math"
+	"reflect"
+	"runtime"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/pop/graph"
+)
+
+// step is a genome which counts the iterations which produced it.
+type step struct{ n int }
+
+func (s *step) Fitness() float64 { return float64(s.n) }
+
+// steps returns n genomes of iteration 0.
+func steps(n int) []evo.Genome {
+	members := make([]evo.Genome, n)
+	for i := range members {
+		members[i] = &step{}
+	}
+	return members
+}
+
+// next is an EvolveFn which counts iterations.
+func next(current evo.Genome, _ []evo.Genome) evo.Genome {
+	return &step{current.(*step).n + 1}
+}
+
+// id is a genome which is the index of its node.
+type id int
+
+func (i id) Fitness() float64 { return float64(i) }
+
+func (i id) Difference(other evo.Genome) float64 {
+	return math.Abs(float64(i - other.(id)))
+}
+
+// observe evolves a graph whose members are the indices of their nodes until
+// every node has iterated n times, and returns the indices of the suitors of
+// each of the first n iterations of each node.
+func observe(g graph.Graph, n int) [][][]int {
+	var (
+		mu   sync.Mutex
+		wg   sync.WaitGroup
+		seen = make([][][]int, len(g))
+	)
+	members := make([]evo.Genome, len(g))
+	for i := range members {
+		members[i] = id(i)
+	}
+	wg.Add(len(g))
+	g.SetDelay(time.Microsecond) // yield to the other nodes
+	g.Evolve(members, func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		i := int(current.(id))
+		idx := make([]int, len(suitors))
+		for j := range suitors {
+			idx[j] = int(suitors[j].(id))
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		if len(seen[i]) < n {
+			seen[i] = append(seen[i], idx)
+			if len(seen[i]) == n {
+				wg.Done()
+			}
+		}
+		return current
+	})
+	wg.Wait()
+	g.Stop()
+	return seen
+}
+
+// neighbors returns the sorted suitors of each node of a graph, which are its
+// neighbors unless the graph samples its suitors.
+func neighbors(g graph.Graph) [][]int {
+	seen := observe(g, 1)
+	hoods := make([][]int, len(g))
+	for i := range seen {
+		hoods[i] = append([]int{}, seen[i][0]...)
+		sort.Ints(hoods[i])
+	}
+	return hoods
+}
+
+// settle waits for the goroutines started since base to exit, and returns the
+// number which are still running.
+func settle(base int) int {
+	for i := 0; i < 100 && base < runtime.NumGoroutine(); i++ {
+		time.Sleep(time.Millisecond)
+	}
+	return runtime.NumGoroutine() - base
+}
+
+// graph.go
+// -------------------------
+
+func TestSync(t *testing.T) {
+	base := runtime.NumGoroutine()
+	g := graph.Ring(8)
+	g.SetSync(true)
+
+	// in lockstep, every suitor is of the same iteration as the current node
+	var mixed atomic.Bool
+	g.Evolve(steps(8), func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		for _, s := range suitors {
+			if s.(*step).n != current.(*step).n {
+				mixed.Store(true)
+			}
+		}
+		return next(current, suitors)
+	})
+	g.Poll(0, func() bool { return 20 <= g.Generation() })
+	g.Wait()
+
+	if mixed.Load() {
+		t.Error("suitors of different iterations")
+	}
+	lo, hi := g.Iterations(0), g.Iterations(0)
+	for i := range g {
+		lo, hi = min(lo, g.Iterations(i)), max(hi, g.Iterations(i))
+	}
+	if hi-lo > 1 {
+		t.Errorf("iterations from %d to %d, want lockstep", lo, hi)
+	}
+	if n := settle(base); 0 < n {
+		t.Errorf("%d goroutines still running after Stop", n)
+	}
+}
+
+func TestUnstarted(t *testing.T) {
+	g := graph.Ring(4)
+	v := g.View()
+	defer v.Close()
+	if v.Len() != 0 {
+		t.Errorf("view of %d members before Evolve", v.Len())
+	}
+	if s := g.Stats(); s.Count() != 0 {
+		t.Errorf("stats of %d members before Evolve", s.Count())
+	}
+	g.SetStatsTTL(time.Second)
+	if s := g.Stats(); s.Count() != 0 {
+		t.Errorf("cached stats of %d members before Evolve", s.Count())
+	}
+}
+
+func TestOnError(t *testing.T) {
+	members := steps(4)
+	bad := members[1]
+	var (
+		mu     sync.Mutex
+		errs   int
+		misuse bool
+	)
+	g := graph.Ring(4)
+	g.OnError(func(node int, err error) {
+		mu.Lock()
+		defer mu.Unlock()
+		errs++
+		perr, ok := err.(*evo.PanicError)
+		misuse = misuse || node != 1 || !ok || perr.Value != "bad genome"
+	})
+	g.Evolve(members, func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		if current == bad {
+			panic("bad genome")
+		}
+		return next(current, suitors)
+	})
+	g.Poll(0, func() bool { return 5 <= g.Iterations(1) && 5 <= g.Iterations(0) })
+	g.Wait()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if errs < 5 || misuse {
+		t.Errorf("%d errors in %d iterations, reported as expected: %v", errs, g.Iterations(1), !misuse)
+	}
+	if g.Get(1) != bad {
+		t.Error("node which panicked was replaced")
+	}
+	if n := g.Get(0).(*step).n; n < 5 {
+		t.Errorf("other nodes stopped evolving after %d iterations", n)
+	}
+}
+
+func TestFakeClockMigration(t *testing.T) {
+	c := evo.NewFakeClock(time.Now())
+	islands := make([]evo.Genome, 2)
+	for i := range islands {
+		var island gen.Population
+		island.Evolve([]evo.Genome{id(i), id(i)}, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+			return current
+		})
+		islands[i] = &island
+	}
+	g := graph.Ring(len(islands))
+	g.Evolve(islands, gen.MigrateWith(gen.Policy{
+		N:     1,
+		Delay: time.Hour,
+		Clock: c,
+	}))
+
+	// neither island may migrate until the clock advances by the delay
+	c.BlockUntil(2)
+	time.Sleep(10 * time.Millisecond)
+	if n := g.Iterations(0) + g.Iterations(1); n != 0 {
+		t.Errorf("%d migrations before the delay", n)
+	}
+	c.Advance(time.Hour)
+	c.BlockUntil(2)
+	if n := g.Iterations(0) + g.Iterations(1); n != 2 {
+		t.Errorf("got %d migrations, want 2", n)
+	}
+	g.Stop()
+}
+
+func TestLattice(t *testing.T) {
+	tests := []struct {
+		name string
+		g    graph.Graph
+		node int
+		want []int
+	}{
+		{"von Neumann corner", graph.Grid(3, 3, graph.VonNeumann(1)), 0, []int{1, 3}},
+		{"von Neumann edge", graph.Grid(3, 3, graph.VonNeumann(1)), 1, []int{0, 2, 4}},
+		{"von Neumann center", graph.Grid(3, 3, graph.VonNeumann(1)), 4, []int{1, 3, 5, 7}},
+		{"Moore corner", graph.Grid(3, 3, graph.Moore(1)), 0, []int{1, 3, 4}},
+		{"Moore center", graph.Grid(3, 3, graph.Moore(1)), 4, []int{0, 1, 2, 3, 5, 6, 7, 8}},
+		{"von Neumann radius 2", graph.Grid(5, 5, graph.VonNeumann(2)), 0, []int{1, 2, 5, 6, 10}},
+		{"Moore radius 2", graph.Grid(5, 5, graph.Moore(2)), 0, []int{1, 2, 5, 6, 7, 10, 11, 12}},
+		{"torus corner", graph.Torus(3, 4), 0, []int{1, 3, 4, 8}},
+		{"torus center", graph.Torus(3, 4), 5, []int{1, 4, 6, 9}},
+		{"torus opposite corner", graph.Torus(3, 4), 11, []int{3, 7, 8, 10}},
+		{"small torus", graph.Torus(2, 2), 0, []int{1, 2}},
+	}
+	for _, test := range tests {
+		if got := neighbors(test.g)[test.node]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s: neighbors of %d are %v, want %v", test.name, test.node, got, test.want)
+		}
+	}
+
+	// the degree of a cell depends on its distance to the border
+	degrees := []struct {
+		name string
+		g    graph.Graph
+		want []int // the degree of each cell
+	}{
+		{"von Neumann", graph.Grid(3, 3, graph.VonNeumann(1)), []int{2, 3, 2, 3, 4, 3, 2, 3, 2}},
+		{"Moore", graph.Grid(3, 3, graph.Moore(1)), []int{3, 5, 3, 5, 8, 5, 3, 5, 3}},
+		{"von Neumann radius 2", graph.Grid(3, 3, graph.VonNeumann(2)), []int{5, 6, 5, 6, 8, 6, 5, 6, 5}},
+		{"torus", graph.Torus(3, 4), []int{4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4, 4}},
+	}
+	for _, test := range degrees {
+		hoods := neighbors(test.g)
+		for i := range hoods {
+			if len(hoods[i]) != test.want[i] {
+				t.Errorf("%s: cell %d has degree %d, want %d", test.name, i, len(hoods[i]), test.want[i])
+			}
+		}
+	}
+}
+
+func TestRing(t *testing.T) {
+	tests := []struct {
+		size int
+		want [][]int
+	}{
+		{1, [][]int{{}}},
+		{2, [][]int{{1}, {0}}},
+		{3, [][]int{{1, 2}, {0, 2}, {0, 1}}},
+		{5, [][]int{{1, 4}, {0, 2}, {1, 3}, {2, 4}, {0, 3}}},
+	}
+	for _, test := range tests {
+		if got := neighbors(graph.Ring(test.size)); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("ring of %d: neighbors are %v, want %v", test.size, got, test.want)
+		}
+	}
+}
+
+func TestRandomRegular(t *testing.T) {
+	for _, k := range []int{1, 2, 3, 5} {
+		hoods := neighbors(graph.RandomRegular(10, k))
+		for i := range hoods {
+			if len(hoods[i]) != k {
+				t.Errorf("degree %d: node %d has neighbors %v", k, i, hoods[i])
+			}
+			for j := range hoods[i] {
+				if hoods[i][j] == i || 0 < j && hoods[i][j] == hoods[i][j-1] {
+					t.Errorf("degree %d: node %d has self or duplicate links %v", k, i, hoods[i])
+				}
+			}
+		}
+	}
+
+	// an odd number of stubs or a degree of at least the size is impossible
+	for _, test := range [][2]int{{5, 3}, {4, 4}, {3, 5}} {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("RandomRegular(%d, %d) did not panic", test[0], test[1])
+				}
+			}()
+			graph.RandomRegular(test[0], test[1])
+		}()
+	}
+}
+
+// connected returns true if every node is reachable from the first.
+func connected(hoods [][]int) bool {
+	seen := make([]bool, len(hoods))
+	seen[0] = true
+	queue := []int{0}
+	for len(queue) != 0 {
+		i := queue[0]
+		queue = queue[1:]
+		for _, j := range hoods[i] {
+			if !seen[j] {
+				seen[j] = true
+				queue = append(queue, j)
+			}
+		}
+	}
+	for i := range seen {
+		if !seen[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSmallWorld(t *testing.T) {
+	const n, k = 32, 4
+	for _, beta := range []float64{0, 0.2, 1} {
+		hoods := neighbors(graph.SmallWorld(n, k, beta))
+		var degrees int
+		for i := range hoods {
+			degrees += len(hoods[i])
+		}
+		if degrees != 2*n*k {
+			t.Errorf("beta %v: %d edges, want %d", beta, degrees/2, n*k)
+		}
+		if !connected(hoods) {
+			t.Errorf("beta %v: not connected", beta)
+		}
+	}
+
+	// without rewiring, the graph is a ring lattice
+	hoods := neighbors(graph.SmallWorld(n, k, 0))
+	if want := []int{1, 2, 3, 4, 28, 29, 30, 31}; !reflect.DeepEqual(hoods[0], want) {
+		t.Errorf("neighbors of 0 are %v, want %v", hoods[0], want)
+	}
+}
+
+func TestScaleFree(t *testing.T) {
+	const n, m = 20, 2
+	hoods := neighbors(graph.ScaleFree(n, m))
+	var degrees int
+	for i := range hoods {
+		degrees += len(hoods[i])
+		if len(hoods[i]) < m {
+			t.Errorf("node %d has neighbors %v, want at least %d", i, hoods[i], m)
+		}
+		for j := range hoods[i] {
+			if hoods[i][j] == i || 0 < j && hoods[i][j] == hoods[i][j-1] {
+				t.Errorf("node %d has self or duplicate links %v", i, hoods[i])
+			}
+		}
+	}
+	if want := m*(m+1)/2 + (n-m-1)*m; degrees != 2*want {
+		t.Errorf("%d edges, want %d", degrees/2, want)
+	}
+	if !connected(hoods) {
+		t.Error("not connected")
+	}
+}
+
+func TestCustom(t *testing.T) {
+	layout := [][]int{{1, 2}, {2}, {0}, {}}
+	if got := neighbors(graph.Custom(layout)); !reflect.DeepEqual(got, layout) {
+		t.Errorf("neighbors are %v, want %v", got, layout)
+	}
+}
+
+func TestSetWeights(t *testing.T) {
+	// odd peers have weight 0, and even peers are rarely included
+	g := graph.Complete(6)
+	g.SetWeights(func(node, peer int) float64 { return float64(1-peer%2) / 10 })
+	for i, iters := range observe(g, 100) {
+		for _, suitors := range iters {
+			if len(suitors) == 0 {
+				t.Errorf("node %d has no suitors", i)
+			}
+			for _, j := range suitors {
+				if j%2 != 0 {
+					t.Errorf("node %d drew suitor %d of weight 0", i, j)
+				}
+			}
+		}
+	}
+
+	// nodes whose weights are all 0 have no suitors
+	g = graph.Complete(3)
+	g.SetWeights(func(node, peer int) float64 { return 0 })
+	for i, iters := range observe(g, 10) {
+		for _, suitors := range iters {
+			if len(suitors) != 0 {
+				t.Errorf("node %d drew suitors %v of weight 0", i, suitors)
+			}
+		}
+	}
+}
+
+func TestGridDecay(t *testing.T) {
+	decay := graph.GridDecay(4, 5, 2)
+	tests := []struct {
+		node, peer int
+		want       float64
+	}{
+		{0, 1, 1},
+		{0, 5, 1},
+		{0, 4, 1},  // around the columns
+		{0, 15, 1}, // around the rows
+		{0, 6, math.Exp(-(math.Sqrt2 - 1) / 2)},
+		{0, 2, math.Exp(-0.5)},
+		{7, 7, math.Exp(0.5)},
+	}
+	for _, test := range tests {
+		if got := decay(test.node, test.peer); math.Abs(got-test.want) > 1e-9 {
+			t.Errorf("weight of %d to %d is %v, want %v", test.node, test.peer, got, test.want)
+		}
+	}
+	if decay(0, 1) != decay(1, 0) || decay(3, 17) != decay(17, 3) {
+		t.Error("weights are not symmetric")
+	}
+}
+
+func TestSetRadius(t *testing.T) {
+	tests := []struct {
+		name   string
+		g      graph.Graph
+		radius int
+		node   int
+		want   []int
+	}{
+		{"ring", graph.Ring(10), 1, 0, []int{1, 9}},
+		{"ring", graph.Ring(10), 2, 0, []int{1, 2, 8, 9}},
+		{"ring", graph.Ring(10), 3, 5, []int{2, 3, 4, 6, 7, 8}},
+		{"small ring", graph.Ring(4), 5, 0, []int{1, 2, 3}},
+		{"grid", graph.Grid(3, 3, graph.VonNeumann(1)), 2, 0, []int{1, 2, 3, 4, 6}},
+		{"path", graph.Custom([][]int{{1}, {0, 2}, {1, 3}, {2}}), 2, 0, []int{1, 2}},
+	}
+	for _, test := range tests {
+		test.g.SetRadius(test.radius)
+		if got := neighbors(test.g)[test.node]; !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%s of radius %d: candidates of %d are %v, want %v",
+				test.name, test.radius, test.node, got, test.want)
+		}
+	}
+}
+
+func TestSetWalk(t *testing.T) {
+	// a path of 4 nodes and a separate pair
+	g := graph.Custom([][]int{{1}, {0, 2}, {1, 3}, {2}, {5}, {4}})
+	g.SetWalk(3, 4)
+	for i, iters := range observe(g, 50) {
+		for _, suitors := range iters {
+			if len(suitors) != 3 {
+				t.Errorf("node %d drew %d suitors, want 3", i, len(suitors))
+			}
+			for _, j := range suitors {
+				if j == i || (i < 4) != (j < 4) {
+					t.Errorf("node %d drew unreachable suitor %d", i, j)
+				}
+			}
+		}
+	}
+
+	// walks of length 1 end at direct neighbors
+	g = graph.Ring(6)
+	g.SetWalk(2, 1)
+	for i, iters := range observe(g, 50) {
+		for _, suitors := range iters {
+			for _, j := range suitors {
+				if j != (i+1)%6 && j != (i+5)%6 {
+					t.Errorf("node %d drew suitor %d beyond its neighbors", i, j)
+				}
+			}
+		}
+	}
+}
+
+func TestSetStatsTTL(t *testing.T) {
+	clock := evo.NewFakeClock(time.Unix(0, 0))
+	g := graph.Ring(4)
+	g.SetClock(clock)
+	g.SetStatsTTL(time.Minute)
+	g.Evolve(steps(4), next)
+	defer g.Stop()
+
+	// wait for the nodes to evolve past the cached stats
+	first := g.Stats()
+	for g.Throughput().Iterations < 100 {
+		time.Sleep(time.Millisecond)
+	}
+	if s := g.Stats(); s != first {
+		t.Errorf("stats recomputed within the TTL: %v, then %v", first, s)
+	}
+
+	clock.Advance(time.Minute)
+	if s := g.Stats(); s.Max() <= first.Max() || s.Generation() <= first.Generation() {
+		t.Errorf("stats not recomputed after the TTL: %v, then %v", first, s)
+	}
+}
+
+// serial.go
+// -------------------------
+
+func TestSerial(t *testing.T) {
+	g := graph.Ring(4)
+	var gens []int
+	g.OnIteration(func(sweep int, stats evo.Stats) { gens = append(gens, sweep) })
+	s := graph.NewSerial(g)
+	polled := make(chan struct{})
+	s.SetYield(func() { <-polled }) // no further sweeps until Poll
+	s.Evolve(steps(4), next)
+	s.Poll(0, func() bool { return s.Sweeps() >= 10 })
+	close(polled)
+	s.Wait()
+
+	stats := s.Stats()
+	if s.Sweeps() != 10 || stats.Generation() != 10 {
+		t.Errorf("stopped after %d sweeps, stats of generation %d, want 10", s.Sweeps(), stats.Generation())
+	}
+	if stats.Count() != 4 || stats.Min() != 10 || stats.Max() != 10 {
+		t.Errorf("wrong stats after 10 sweeps: %v", stats)
+	}
+	if len(gens) != 10 || gens[9] != 10 {
+		t.Errorf("iterations %v, want 1 through 10", gens)
+	}
+	if best := s.Best(); best.Fitness != 10 {
+		t.Errorf("wrong best: %+v", best)
+	}
+	if tp := s.Throughput(); tp.Iterations != 40 || tp.Evaluations != 40 {
+		t.Errorf("%d iterations and %d evaluations, want 40", tp.Iterations, tp.Evaluations)
+	}
+	s.Stop() // stopping a stopped population is a no-op
+
+	// without a goroutine, the caller drives the sweeps
+	s.Start(steps(4), next)
+	for i := 0; i < 3; i++ {
+		if !s.Step() {
+			t.Fatal("stopped before Stop")
+		}
+	}
+	s.Stop()
+	if s.Step() {
+		t.Error("stepped after Stop")
+	}
+	if stats := s.Stats(); stats.Generation() != 3 || stats.Max() != 3 {
+		t.Errorf("wrong stats after 3 steps: %v", stats)
+	}
+}
+
+// spatial.go
+// -------------------------
+
+func TestSpatial(t *testing.T) {
+	grid := graph.Grid(2, 3, graph.VonNeumann(1))
+	observe(grid, 1)
+	fitness := [][]float64{{0, 1, 2}, {3, 4, 5}}
+	if got := grid.FitnessMap(3); !reflect.DeepEqual(got, fitness) {
+		t.Errorf("fitness map %v, want %v", got, fitness)
+	}
+	distance := [][]float64{{5, 4, 3}, {2, 1, 0}}
+	if got := grid.DistanceMap(3); !reflect.DeepEqual(got, distance) {
+		t.Errorf("distance map %v, want %v", got, distance)
+	}
+	if got := grid.FitnessMap(2); len(got) != 3 || len(got[0]) != 2 || got[2][1] != 5 {
+		t.Errorf("fitness map of 2 columns %v", got)
+	}
+
+	// genomes which are not Differs have no distance
+	ring := graph.Ring(4)
+	ring.Evolve(steps(4), next)
+	ring.Stop()
+	for _, row := range ring.DistanceMap(2) {
+		for _, d := range row {
+			if !math.IsNaN(d) {
+				t.Errorf("distance %v between genomes which are not Differs", d)
+			}
+		}
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("map of 4 columns of 6 nodes did not panic")
+		}
+	}()
+	grid.FitnessMap(4)
+}
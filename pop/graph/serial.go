@@ -0,0 +1,287 @@
+package graph
+
+import (
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Serial evolves a graph population on a single goroutine, updating the nodes
+// in turn rather than in parallel. It suits environments without OS threads,
+// such as js/wasm, where the evolution shares its thread with an event loop:
+// Evolve runs on one goroutine which calls a yield function after every sweep,
+// and Step lets the caller drive the evolution one sweep at a time, e.g. from an
+// animation frame callback of an in-browser demo.
+//
+// The topology, radius, weights, walks and callbacks of the graph apply, except
+// that OnIteration and the events are delivered on the evolving goroutine
+// after each sweep. Delays are ignored. Each sweep evolves every node once, in
+// a random order, or in lockstep when synchronous.
+//
+// Poll conditions are checked between sweeps rather than on their own
+// goroutines. Stop must not be called from the yield function or the callbacks,
+// since it waits for the evolving goroutine; return true from a Poll condition
+// instead.
+type Serial struct {
+	g     Graph
+	sync  bool
+	yield func()
+
+	mu      sync.Mutex
+	body    evo.EvolveFn
+	sweeps  int
+	conds   []poll
+	running bool
+	stopped bool
+	done    chan struct{}
+}
+
+// poll is a condition checked between sweeps.
+type poll struct {
+	freq time.Duration
+	last time.Time
+	cond evo.ConditionFn
+}
+
+// NewSerial returns a serial population of the graph. The graph must not be
+// evolved by its own Evolve method at the same time.
+func NewSerial(g Graph) *Serial {
+	return &Serial{g: g}
+}
+
+// SetSync switches between asynchronous and synchronous updates, like
+// Graph.SetSync. SetSync must be called before Evolve or Start.
+func (s *Serial) SetSync(sync bool) {
+	s.sync = sync
+}
+
+// SetYield sets a function called on the evolving goroutine after every sweep.
+// Under js/wasm, where goroutines only yield to the event loop when they block,
+// the function should block until the page is ready for more work, e.g. by
+// waiting on a channel signaled from requestAnimationFrame. SetYield must be
+// called before Evolve.
+func (s *Serial) SetYield(yield func()) {
+	s.yield = yield
+}
+
+// Start prepares the evolution without starting a goroutine. The evolution
+// then advances by calls to Step until the population is stopped.
+func (s *Serial) Start(members []evo.Genome, body evo.EvolveFn) {
+	g := s.g
+	meter := evo.NewMeter()
+	best := evo.NewTracker(meter)
+	for i := range g {
+		g[i].meter = meter
+		g[i].best = best
+		g[i].idx = i
+		g[i].iters = new(atomic.Int64)
+		g[i].val = &members[i]
+		g[i].cur = new(atomic.Pointer[evo.Genome])
+		val := members[i]
+		g[i].cur.Store(&val)
+		best.Observe(val)
+	}
+	if h := g[0].hooks; h != nil {
+		h.size = int64(len(g))
+		h.count.Store(0)
+		if h.events != nil {
+			h.events.SetClock(h.clock)
+		}
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.body = body
+	s.sweeps = 0
+	s.conds = nil
+	s.running = false
+	s.stopped = false
+	s.done = make(chan struct{})
+}
+
+// Evolve starts the optimization in a separate goroutine, which sweeps the
+// graph and calls the yield function in turn.
+func (s *Serial) Evolve(members []evo.Genome, body evo.EvolveFn) {
+	s.Start(members, body)
+	s.mu.Lock()
+	s.running = true
+	s.mu.Unlock()
+	go func() {
+		for s.Step() {
+			if s.yield != nil {
+				s.yield()
+			}
+		}
+		s.finish()
+	}()
+}
+
+// Step evolves every node of the graph once, then delivers the callbacks and
+// checks the Poll conditions. It returns false, without evolving, once the
+// population has stopped. Step must not be called concurrently with itself or
+// while the population is evolving by Evolve.
+func (s *Serial) Step() bool {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return false
+	}
+	s.sweep()
+	s.sweeps++
+	sweep := s.sweeps
+	s.mu.Unlock()
+
+	s.notify(sweep)
+	if s.poll() {
+		s.halt()
+		return false
+	}
+	return true
+}
+
+// sweep evolves every node once.
+func (s *Serial) sweep() {
+	g := s.g
+	if s.sync {
+		next := make([]evo.Genome, len(g))
+		for i := range g {
+			next[i] = g[i].evolve(s.body, g[i].get())
+		}
+		for i := range g {
+			g[i].store(next[i])
+			g[i].iters.Add(1)
+		}
+		return
+	}
+	for _, i := range rand.Perm(len(g)) {
+		g[i].store(g[i].evolve(s.body, g[i].get()))
+		g[i].iters.Add(1)
+	}
+}
+
+// notify delivers the callbacks and events of a sweep.
+func (s *Serial) notify(sweep int) {
+	h := s.g[0].hooks
+	if h == nil || (h.onIter == nil && h.events == nil) {
+		return
+	}
+	v := s.View()
+	stats := v.Stats().WithGeneration(sweep)
+	if h.events != nil {
+		h.events.Generation(sweep, stats, func() evo.Genome {
+			return v.TopK(1)[0]
+		})
+	}
+	v.Close()
+	if h.onIter != nil {
+		h.onIter(sweep, stats)
+	}
+}
+
+// poll checks the conditions which are due, returning true if any holds.
+func (s *Serial) poll() bool {
+	s.mu.Lock()
+	conds := s.conds
+	s.mu.Unlock()
+	now := s.g[0].hooks.getClock().Now()
+	for i := range conds {
+		if now.Sub(conds[i].last) < conds[i].freq {
+			continue
+		}
+		conds[i].last = now
+		if conds[i].cond() {
+			return true
+		}
+	}
+	return false
+}
+
+// halt marks the population as stopped. Without an evolving goroutine, it
+// also finishes the evolution.
+func (s *Serial) halt() {
+	s.mu.Lock()
+	first := !s.stopped
+	s.stopped = true
+	running := s.running
+	s.mu.Unlock()
+	if first && !running {
+		s.finish()
+	}
+}
+
+// finish stops the sub-populations and publishes the Stopped event.
+func (s *Serial) finish() {
+	for i := range s.g {
+		if subpop, ok := s.g[i].get().(evo.Population); ok {
+			subpop.Stop()
+		}
+	}
+	s.g[0].hooks.stop(s.g)
+	close(s.done)
+}
+
+// Stop terminates the optimization.
+func (s *Serial) Stop() {
+	s.halt()
+	s.Wait()
+}
+
+// Poll executes a function at some frequency for the duration of the current
+// optimization. If the function returns true, the current optimization is
+// halted. The function is called between sweeps, so it is called at most once
+// per sweep, and a frequency of 0 checks it after every sweep.
+func (s *Serial) Poll(freq time.Duration, cond evo.ConditionFn) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.conds = append(s.conds, poll{freq: freq, last: s.g[0].hooks.getClock().Now(), cond: cond})
+}
+
+// Wait blocks until the evolution terminates.
+func (s *Serial) Wait() {
+	s.mu.Lock()
+	done := s.done
+	s.mu.Unlock()
+	<-done
+}
+
+// Sweeps returns the number of sweeps since Evolve or Start was called.
+func (s *Serial) Sweeps() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sweeps
+}
+
+// View returns a snapshot of the members of the population. Unlike the view of
+// a parallel graph, the snapshot is atomic: it is taken between sweeps.
+func (s *Serial) View() evo.View {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.g.View()
+}
+
+// Stats returns statistics on the fitness of genomes in the population, tagged
+// with the number of sweeps.
+func (s *Serial) Stats() evo.Stats {
+	v := s.View()
+	defer v.Close()
+	return v.Stats().WithGeneration(s.Sweeps())
+}
+
+// Fitness returns the maximum fitness within the population.
+func (s *Serial) Fitness() float64 {
+	return s.Stats().Max()
+}
+
+// Best returns the record of the best genome observed since Evolve or Start
+// was called.
+func (s *Serial) Best() evo.Record {
+	return s.g.Best()
+}
+
+// Throughput returns the throughput of the population since Evolve or Start
+// was called.
+func (s *Serial) Throughput() evo.Throughput {
+	return s.g.Throughput()
+}
@@ -0,0 +1,53 @@
+package graph
+
+import (
+	"math"
+
+	"github.com/cbarrick/evo"
+)
+
+// FitnessMap returns the fitness of each node of a grid or torus with the given
+// number of columns, as rows of cells in the row-major order of Grid and Torus.
+// Rendered as a heatmap, e.g. with plot.Heatmap, successive maps show how good
+// genes diffuse across space. It panics if the number of nodes is not a
+// multiple of cols.
+func (g Graph) FitnessMap(cols int) [][]float64 {
+	v := g.View()
+	defer v.Close()
+	members := v.Members()
+	return spatial(len(members), cols, func(i int) float64 {
+		return members[i].Fitness()
+	})
+}
+
+// DistanceMap returns the difference between the genome of each node of a grid
+// or torus and the most fit genome of the graph, laid out like FitnessMap.
+// Cells whose genomes do not implement evo.Differ are NaN.
+func (g Graph) DistanceMap(cols int) [][]float64 {
+	v := g.View()
+	defer v.Close()
+	members := v.Members()
+	best := v.TopK(1)
+	return spatial(len(members), cols, func(i int) float64 {
+		d, ok := members[i].(evo.Differ)
+		if !ok || len(best) == 0 {
+			return math.NaN()
+		}
+		return d.Difference(best[0])
+	})
+}
+
+// spatial lays out n values as rows of the given number of columns.
+func spatial(n, cols int, value func(i int) float64) [][]float64 {
+	if cols <= 0 || n%cols != 0 {
+		panic("graph: size is not a multiple of the columns")
+	}
+	rows := make([][]float64, n/cols)
+	for r := range rows {
+		rows[r] = make([]float64, cols)
+		for c := range rows[r] {
+			rows[r][c] = value(r*cols + c)
+		}
+	}
+	return rows
+}
@@ -0,0 +1,226 @@
+package evo
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// A Provenance records how a genome was created: its parents, the operators
+// which created it, and the population where it was created. Provenance
+// enables adaptive operator selection, lineage tracking, and genealogy
+// visualization.
+type Provenance struct {
+	ID       uint64   `json:"id"`                 // unique within the process
+	Parents  []uint64 `json:"parents,omitempty"`  // none for random genomes
+	Operator string   `json:"operator,omitempty"` // e.g. "pmx+swap"
+	Origin   string   `json:"origin,omitempty"`   // e.g. the name of an island
+}
+
+// A Descendant is a genome which carries its provenance. Since selectors and
+// populations pass genomes around as they are, the provenance of a Descendant
+// travels with it through selection and migration.
+type Descendant interface {
+	Genome
+	Provenance() Provenance
+	SetProvenance(Provenance)
+}
+
+// A Lineage holds the provenance of a genome. Lineages are safe for concurrent
+// use. The zero value is the lineage of a random genome, which is given an ID
+// on first use.
+//
+// Lineages are meant to be embedded in genome types, making them Descendants:
+//
+//	type genome struct {
+//		evo.Cache
+//		evo.Lineage
+//		gene []int
+//	}
+type Lineage struct {
+	mu sync.Mutex
+	p  Provenance
+}
+
+// lineageIDs counts the IDs given to lineages.
+var lineageIDs atomic.Uint64
+
+// Provenance returns the provenance of the genome.
+func (l *Lineage) Provenance() Provenance {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.p.ID == 0 {
+		l.p.ID = lineageIDs.Add(1)
+	}
+	return l.p
+}
+
+// SetProvenance replaces the provenance of the genome. The ID of the genome is
+// kept if the ID of the provenance is 0.
+func (l *Lineage) SetProvenance(p Provenance) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if p.ID == 0 {
+		p.ID = l.p.ID
+	}
+	l.p = p
+}
+
+// Derive records that the operator created the child from the parents. The
+// child inherits the origin of its first parent. If the child already has
+// parents, e.g. when a mutation follows a crossover, the operator is appended
+// to the operators of the child, separated by "+". Derive does nothing unless
+// the child is a Descendant; parents which are not Descendants are skipped.
+func Derive(child Genome, operator string, parents ...Genome) {
+	d, ok := child.(Descendant)
+	if !ok {
+		return
+	}
+	p := d.Provenance()
+	if len(p.Parents) == 0 {
+		for _, parent := range parents {
+			if pd, ok := parent.(Descendant); ok && parent != child {
+				pp := pd.Provenance()
+				if len(p.Parents) == 0 {
+					p.Origin = pp.Origin
+				}
+				p.Parents = append(p.Parents, pp.ID)
+			}
+		}
+	}
+	switch {
+	case operator == "":
+	case p.Operator == "":
+		p.Operator = operator
+	default:
+		p.Operator += "+" + operator
+	}
+	d.SetProvenance(p)
+}
+
+// WithOrigin returns an EvolveFn which evolves with body and records the
+// origin of the new genomes it returns, e.g. the name of the island evolving
+// with it. A returned genome other than the current one is taken to be new, so
+// body should not itself inject migrants, e.g. it should be wrapped by
+// remote.Node.Wrap rather than wrap it.
+func WithOrigin(origin string, body EvolveFn) EvolveFn {
+	return func(current Genome, suitors []Genome) Genome {
+		next := body(current, suitors)
+		if d, ok := next.(Descendant); ok && next != current {
+			p := d.Provenance()
+			p.Origin = origin
+			d.SetProvenance(p)
+		}
+		return next
+	}
+}
+
+// A Genealogy records the provenance of genomes for lineage tracking.
+// Genealogies are safe for concurrent use.
+type Genealogy struct {
+	mu    sync.Mutex
+	nodes map[uint64]Provenance
+}
+
+// Record records the provenance of the genome, if it is a Descendant, and
+// returns it. Recording a genome again replaces its record.
+func (g *Genealogy) Record(genome Genome) (p Provenance, ok bool) {
+	d, ok := genome.(Descendant)
+	if !ok {
+		return p, false
+	}
+	p = d.Provenance()
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.nodes == nil {
+		g.nodes = make(map[uint64]Provenance)
+	}
+	g.nodes[p.ID] = p
+	return p, true
+}
+
+// Lookup returns the recorded provenance of a genome by ID.
+func (g *Genealogy) Lookup(id uint64) (p Provenance, ok bool) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	p, ok = g.nodes[id]
+	return p, ok
+}
+
+// Ancestors returns the recorded ancestors of a genome by ID, up to the given
+// number of generations back, nearest first. Each ancestor is listed once.
+func (g *Genealogy) Ancestors(id uint64, depth int) []Provenance {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var ancestors []Provenance
+	seen := map[uint64]bool{id: true}
+	frontier := []uint64{id}
+	for gen := 0; gen < depth && len(frontier) > 0; gen++ {
+		var next []uint64
+		for _, id := range frontier {
+			for _, parent := range g.nodes[id].Parents {
+				if seen[parent] {
+					continue
+				}
+				seen[parent] = true
+				if p, ok := g.nodes[parent]; ok {
+					ancestors = append(ancestors, p)
+					next = append(next, parent)
+				}
+			}
+		}
+		frontier = next
+	}
+	return ancestors
+}
+
+// WriteDOT writes the genealogy as a Graphviz graph, with an edge from each
+// parent to each child labeled by the operator of the child, and the nodes
+// grouped by origin.
+func (g *Genealogy) WriteDOT(w io.Writer) error {
+	g.mu.Lock()
+	ids := make([]uint64, 0, len(g.nodes))
+	for id := range g.nodes {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	nodes := make([]Provenance, len(ids))
+	for i, id := range ids {
+		nodes[i] = g.nodes[id]
+	}
+	g.mu.Unlock()
+
+	origins := make(map[string][]uint64)
+	var names []string
+	for _, p := range nodes {
+		if _, ok := origins[p.Origin]; !ok {
+			names = append(names, p.Origin)
+		}
+		origins[p.Origin] = append(origins[p.Origin], p.ID)
+	}
+	sort.Strings(names)
+
+	if _, err := fmt.Fprintln(w, "digraph genealogy {"); err != nil {
+		return err
+	}
+	for i, origin := range names {
+		if origin != "" {
+			fmt.Fprintf(w, "\tsubgraph cluster_%d {\n\t\tlabel=%q;\n", i, origin)
+		}
+		for _, id := range origins[origin] {
+			fmt.Fprintf(w, "\t\t%d;\n", id)
+		}
+		if origin != "" {
+			fmt.Fprintln(w, "\t}")
+		}
+	}
+	for _, p := range nodes {
+		for _, parent := range p.Parents {
+			fmt.Fprintf(w, "\t%d -> %d [label=%q];\n", parent, p.ID, p.Operator)
+		}
+	}
+	_, err := fmt.Fprintln(w, "}")
+	return err
+}
@@ -0,0 +1,74 @@
+package evo_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+// descendant is a genome with a lineage.
+type descendant struct {
+	evo.Lineage
+	fit float64
+}
+
+func (d *descendant) Fitness() float64 { return d.fit }
+
+func TestDerive(t *testing.T) {
+	mom, dad := new(descendant), new(descendant)
+	mom.SetProvenance(evo.Provenance{Origin: "a"})
+	child := new(descendant)
+	evo.Derive(child, "pmx", mom, dad)
+	evo.Derive(child, "swap")
+
+	p := child.Provenance()
+	if p.ID == 0 || p.ID == mom.Provenance().ID || p.ID != child.Provenance().ID {
+		t.Error("bad ID", p)
+	}
+	if len(p.Parents) != 2 || p.Parents[0] != mom.Provenance().ID || p.Parents[1] != dad.Provenance().ID {
+		t.Error("bad parents", p)
+	}
+	if p.Operator != "pmx+swap" || p.Origin != "a" {
+		t.Error("bad provenance", p)
+	}
+
+	body := evo.WithOrigin("b", func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return child
+	})
+	body(mom, nil)
+	if child.Provenance().Origin != "b" {
+		t.Error("origin not recorded")
+	}
+
+	// genomes without a lineage are ignored
+	evo.Derive(point(1), "pmx", mom)
+}
+
+func TestGenealogy(t *testing.T) {
+	var g evo.Genealogy
+	root := new(descendant)
+	mid := new(descendant)
+	leaf := new(descendant)
+	evo.Derive(mid, "x", root)
+	evo.Derive(leaf, "y", mid, root)
+	for _, d := range []*descendant{root, mid, leaf} {
+		g.Record(d)
+	}
+	if _, ok := g.Record(point(1)); ok {
+		t.Error("recorded a genome without lineage")
+	}
+
+	ancestors := g.Ancestors(leaf.Provenance().ID, 5)
+	if len(ancestors) != 2 || ancestors[0].ID != mid.Provenance().ID {
+		t.Error("bad ancestors", ancestors)
+	}
+	if len(g.Ancestors(leaf.Provenance().ID, 0)) != 0 {
+		t.Error("ancestors beyond depth")
+	}
+
+	var b strings.Builder
+	if err := g.WriteDOT(&b); err != nil || strings.Count(b.String(), "->") != 3 {
+		t.Error(b.String(), err)
+	}
+}
@@ -0,0 +1,159 @@
+// Package qap provides the quadratic assignment problem as a benchmark for
+// permutation representations.
+//
+// Given n facilities and n locations, with a flow between each pair of
+// facilities and a distance between each pair of locations, the problem is to
+// assign each facility to a location so as to minimize the sum of flows times
+// distances. Solutions are permutations p, where facility i is assigned to
+// location p[i], and the cost is
+//
+//	sum over i, j of Flow[i][j] * Dist[p[i]][p[j]]
+//
+// Instances are loaded from the QAPLIB format. Swapping the locations of two
+// facilities is the most common move for this problem, and its effect on the
+// cost can be computed in linear time with SwapDelta.
+package qap
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Problem is a quadratic assignment problem.
+type Problem struct {
+	Name string
+	N    int
+	Flow [][]int // flows between facilities
+	Dist [][]int // distances between locations
+}
+
+// Load reads a problem from a file.
+func Load(path string) (*Problem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	p, err := Parse(f)
+	if err != nil {
+		return nil, err
+	}
+	p.Name = path
+	return p, nil
+}
+
+// Parse reads a problem in the QAPLIB format: the size n followed by two n×n
+// matrices. QAPLIB instances are not consistent about which matrix holds the
+// flows, but the cost is the same either way.
+func Parse(r io.Reader) (*Problem, error) {
+	var (
+		p  = new(Problem)
+		rd = bufio.NewReader(r)
+	)
+	if _, err := fmt.Fscan(rd, &p.N); err != nil {
+		return nil, fmt.Errorf("qap: bad size: %v", err)
+	}
+	read := func() ([][]int, error) {
+		m := make([][]int, p.N)
+		for i := range m {
+			m[i] = make([]int, p.N)
+			for j := range m[i] {
+				if _, err := fmt.Fscan(rd, &m[i][j]); err != nil {
+					return nil, fmt.Errorf("qap: bad matrix entry (%d,%d): %v", i+1, j+1, err)
+				}
+			}
+		}
+		return m, nil
+	}
+	var err error
+	if p.Flow, err = read(); err != nil {
+		return nil, err
+	}
+	if p.Dist, err = read(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Cost returns the cost of an assignment.
+func (p *Problem) Cost(perm []int) (cost int) {
+	for i := range perm {
+		for j := range perm {
+			cost += p.Flow[i][j] * p.Dist[perm[i]][perm[j]]
+		}
+	}
+	return cost
+}
+
+// SwapDelta returns the change in cost from swapping the locations of
+// facilities r and s, i.e. perm[r] and perm[s], in linear time. The matrices
+// need not be symmetric.
+func (p *Problem) SwapDelta(perm []int, r, s int) (delta int) {
+	a, b := p.Flow, p.Dist
+	pr, ps := perm[r], perm[s]
+	delta = (a[r][r]-a[s][s])*(b[ps][ps]-b[pr][pr]) +
+		(a[r][s]-a[s][r])*(b[ps][pr]-b[pr][ps])
+	for k := range perm {
+		if k == r || k == s {
+			continue
+		}
+		pk := perm[k]
+		delta += (a[k][r]-a[k][s])*(b[pk][ps]-b[pk][pr]) +
+			(a[r][k]-a[s][k])*(b[ps][pk]-b[pr][pk])
+	}
+	return delta
+}
+
+// An Assignment is a genome for a quadratic assignment problem. The fitness is
+// the negative cost, computed once.
+type Assignment struct {
+	evo.Cache
+	Gene    []int
+	Problem *Problem
+}
+
+// New returns a genome for the given assignment.
+func (p *Problem) New(gene []int) *Assignment {
+	return &Assignment{Gene: gene, Problem: p}
+}
+
+// Random returns a genome for a random assignment.
+func (p *Problem) Random() *Assignment {
+	return p.New(rand.Perm(p.N))
+}
+
+// Fitness returns the negative cost of the assignment.
+func (a *Assignment) Fitness() float64 {
+	return a.Cache.Fitness(func() float64 {
+		return -float64(a.Problem.Cost(a.Gene))
+	})
+}
+
+// Alleles returns the location of each facility, implementing evo.Discrete.
+func (a *Assignment) Alleles() []int {
+	return a.Gene
+}
+
+// LocalSearch performs a first-improvement local search with swap moves until
+// no swap improves the assignment. The gene is modified in place and the cache
+// is invalidated.
+func (a *Assignment) LocalSearch() {
+	p := a.Problem
+	for improved := true; improved; {
+		improved = false
+		for r := 0; r < p.N; r++ {
+			for s := r + 1; s < p.N; s++ {
+				if p.SwapDelta(a.Gene, r, s) < 0 {
+					a.Gene[r], a.Gene[s] = a.Gene[s], a.Gene[r]
+					improved = true
+				}
+			}
+		}
+	}
+	a.Invalidate()
+}
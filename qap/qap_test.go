@@ -0,0 +1,63 @@
+package qap_test
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo/qap"
+)
+
+// a small asymmetric instance
+const instance = `4
+
+0 3 0 2
+1 0 4 0
+0 2 0 5
+7 0 1 0
+
+0 22 53 53
+22 0 40 62
+53 40 0 55
+53 62 55 0
+`
+
+func TestParse(t *testing.T) {
+	p, err := qap.Parse(strings.NewReader(instance))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.N != 4 || p.Flow[3][0] != 7 || p.Dist[1][3] != 62 {
+		t.Fail()
+	}
+	if p.Cost([]int{0, 1, 2, 3}) != 3*22+2*53+22+4*40+2*40+5*55+7*53+55 {
+		t.Fail()
+	}
+	if _, err := qap.Parse(strings.NewReader("2\n1 2 3\n")); err == nil {
+		t.Fail()
+	}
+}
+
+func TestSwapDelta(t *testing.T) {
+	p, _ := qap.Parse(strings.NewReader(instance))
+	for i := 0; i < 100; i++ {
+		perm := rand.Perm(p.N)
+		r, s := rand.Intn(p.N), rand.Intn(p.N)
+		before := p.Cost(perm)
+		delta := p.SwapDelta(perm, r, s)
+		perm[r], perm[s] = perm[s], perm[r]
+		if p.Cost(perm)-before != delta {
+			t.Fatal("wrong delta")
+		}
+	}
+}
+
+func TestLocalSearch(t *testing.T) {
+	p, _ := qap.Parse(strings.NewReader(instance))
+	a := p.Random()
+	before := a.Fitness()
+	a.LocalSearch()
+	if a.Fitness() < before {
+		t.Fail()
+	}
+}
@@ -1,11 +1,14 @@
 package real
 
 import (
+	"math"
 	"math/rand"
 )
 
-// UniformX performs a uniform crossover of some parents into a child.
-func UniformX(child Vector, parents ...Vector) {
+// UniformX performs a uniform crossover of some parents into a child. Given
+// all of the suitors as parents, this is the global discrete recombination of
+// evolution strategies.
+func UniformX[T Float](child Vec[T], parents ...Vec[T]) {
 	n := len(parents)
 	for i := range child {
 		child[i] = parents[rand.Intn(n)][i]
@@ -16,7 +19,7 @@ func UniformX(child Vector, parents ...Vector) {
 // uniformly at random from the line segment between the parents. The scale
 // affects the length of the segment about the midpoint. Thus when the scale is
 // 0, the child is always the midpoint.
-func ArithX(scale float64, child, mom, dad Vector) {
+func ArithX[T Float](scale float64, child, mom, dad Vec[T]) {
 	// special case when scale == 0, we can find the midpoint in constant space
 	if scale == 0 {
 		copy(child, mom)
@@ -30,7 +33,67 @@ func ArithX(scale float64, child, mom, dad Vector) {
 	child.Subtract(dad)
 	mid := child.Copy()
 	mid.Scale(0.5)
-	child.Scale(scale*rand.Float64() - scale/2)
+	child.Scale(T(scale*rand.Float64() - scale/2))
 	child.Add(dad)
 	child.Add(mid)
 }
+
+// IntermediateX performs global intermediate recombination: the child is the
+// mean of the parents, typically all of the suitors. This is the recombination
+// of the (µ/µ_I,λ) evolution strategy. See WeightedX for a weighted mean.
+func IntermediateX[T Float](child Vec[T], parents ...Vec[T]) {
+	n := float64(len(parents))
+	for i := range child {
+		var x float64
+		for j := range parents {
+			x += float64(parents[j][i])
+		}
+		child[i] = T(x / n)
+	}
+}
+
+// PanmicticX performs panmictic intermediate recombination: each component of
+// the child is the midpoint of the components of two parents drawn at random
+// anew for that component. Like UniformX, it mixes the components of all of
+// the parents, but it averages rather than copies them, in the manner of the
+// global intermediate recombination of Schwefel.
+func PanmicticX[T Float](child Vec[T], parents ...Vec[T]) {
+	n := len(parents)
+	for i := range child {
+		a, b := parents[rand.Intn(n)][i], parents[rand.Intn(n)][i]
+		child[i] = (a + b) / 2
+	}
+}
+
+// LogRankWeights returns the recombination weights of CMA-ES for µ parents
+// ranked from most to least fit: the weight of rank i is proportional to
+// ln(µ+1/2) - ln(i), and the weights sum to 1. Better ranks get more weight,
+// but the weights decay slowly enough that every parent contributes.
+func LogRankWeights(µ int) []float64 {
+	w := make([]float64, µ)
+	var sum float64
+	for i := range w {
+		w[i] = math.Log(float64(µ)+0.5) - math.Log(float64(i+1))
+		sum += w[i]
+	}
+	for i := range w {
+		w[i] /= sum
+	}
+	return w
+}
+
+// WeightedX performs weighted multi-parent intermediate recombination: the
+// child is the weighted mean of the parents. The weights should sum to 1 and
+// are matched to the parents in order, so with LogRankWeights the parents must
+// be sorted from most to least fit, e.g. the µ best suitors as returned by
+// evo.View.TopK. This is the recombination of the (µ/µ_w,λ) evolution
+// strategy.
+func WeightedX[T Float](child Vec[T], weights []float64, parents ...Vec[T]) {
+	for i := range child {
+		var x float64
+		for j := range parents {
+			x += weights[j] * float64(parents[j][i])
+		}
+		child[i] = T(x)
+	}
+}
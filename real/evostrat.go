@@ -2,26 +2,131 @@ package real
 
 import (
 	"math"
+	"sync"
 )
 
 // Adapt performs a lognormal scaling of the vector using a global learning
 // rate of 1/sqrt(n) and a local learning rate of 1/sqrt(2*sqrt(n)). This is
 // commonly used in evolution strategies to learn the strategy parameters.
-func (v Vector) Adapt() {
+func (v Vec[T]) Adapt() {
 	n := float64(len(v))
 	globalrate := 1 / math.Sqrt(n)
 	localrate := 1 / math.Sqrt(2*math.Sqrt(n))
 	global := Lognormal(globalrate)
 	for i := range v {
-		v[i] *= Lognormal(localrate) * global
+		v[i] *= T(Lognormal(localrate) * global)
 	}
 }
 
 // Step performs a gausian purterbation of the vector using position-wise
 // step-sizes. This is commonly used in evolution strategies to mutate the
 // object parameters, using the strategy parameters as the step-sizes.
-func (v Vector) Step(steps Vector) {
+func (v Vec[T]) Step(steps Vec[T]) {
 	for i := range v {
-		v[i] += Normal(steps[i])
+		v[i] += T(Normal(float64(steps[i])))
 	}
 }
+
+// AdaptRate performs a logistic-normal scaling of a rate in (0,1) with the
+// given learning rate, commonly 1/sqrt(n) for genomes of length n. The odds of
+// the rate are scaled by a lognormal factor, so that the rate stays within
+// (0,1) and increases and decreases are equally likely. This is the
+// self-adaptation of mutation rates proposed by Bäck and Schütz for discrete
+// genomes.
+func AdaptRate(rate, learning float64) float64 {
+	return 1 / (1 + (1-rate)/rate*Lognormal(learning))
+}
+
+// A SuccessRule controls a global step size by Rechenberg's 1/5 success rule.
+// It records whether each mutation succeeded, i.e. produced a child at least as
+// fit as its parent, over a sliding window. Periodically, the step size is
+// increased if more than a fifth of the recent mutations succeeded, and
+// decreased if fewer did. SuccessRules are safe for concurrent use, so a single
+// rule may control the mutations of a whole (µ+λ) population.
+type SuccessRule struct {
+	mu        sync.Mutex
+	window    []bool // recent outcomes, as a ring buffer
+	next      int    // the next position in the window
+	full      bool   // whether the window has been filled
+	successes int    // the number of successes in the window
+	period    int    // the number of records between adaptations
+	count     int    // records since the last adaptation
+
+	// Factor is the scale applied to decrease the step size; its inverse
+	// increases the step size. The default is Schwefel's recommendation of
+	// 0.817.
+	Factor float64
+}
+
+// NewSuccessRule returns a success rule which adapts the step size after every
+// period records, based on the success ratio of the last window records. A
+// common choice for an n-dimensional problem is a period of n and a window of
+// 10n.
+func NewSuccessRule(window, period int) *SuccessRule {
+	if window < 1 || period < 1 {
+		panic("success rule window and period must be positive")
+	}
+	return &SuccessRule{
+		window: make([]bool, window),
+		period: period,
+		Factor: 0.817,
+	}
+}
+
+// Record records the outcome of a mutation and returns the factor by which the
+// step size should be scaled: 1 except at the end of each period, when it is
+// Factor or 1/Factor.
+func (r *SuccessRule) Record(success bool) (scale float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.window[r.next] && r.full {
+		r.successes--
+	}
+	if success {
+		r.successes++
+	}
+	r.window[r.next] = success
+	r.next++
+	if r.next == len(r.window) {
+		r.next = 0
+		r.full = true
+	}
+
+	r.count++
+	if r.count < r.period {
+		return 1
+	}
+	r.count = 0
+	switch ratio := r.ratio(); {
+	case ratio > 0.2:
+		return 1 / r.Factor
+	case ratio < 0.2:
+		return r.Factor
+	}
+	return 1
+}
+
+// Adapt records the outcome of a mutation and scales the steps accordingly.
+func (r *SuccessRule) Adapt(steps Vector, success bool) {
+	if scale := r.Record(success); scale != 1 {
+		steps.Scale(scale)
+	}
+}
+
+// Ratio returns the fraction of successes in the window.
+func (r *SuccessRule) Ratio() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.ratio()
+}
+
+func (r *SuccessRule) ratio() float64 {
+	n := r.next
+	if r.full {
+		n = len(r.window)
+	}
+	if n == 0 {
+		return 0
+	}
+	return float64(r.successes) / float64(n)
+}
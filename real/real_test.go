@@ -36,6 +36,55 @@ func TestArithX(t *testing.T) {
 	}
 }
 
+func TestIntermediateX(t *testing.T) {
+	child := make(real.Vector, 2)
+	real.IntermediateX(child, real.Vector{0, 1}, real.Vector{2, 3}, real.Vector{4, 8})
+	if child[0] != 2 || child[1] != 4 {
+		t.Error(child)
+	}
+}
+
+func TestPanmicticX(t *testing.T) {
+	parents := []real.Vector{{0, 0, 0}, {2, 4, 8}, {6, 12, 24}}
+	child := make(real.Vector, 3)
+	for n := 0; n < 100; n++ {
+		real.PanmicticX(child, parents...)
+		for i := range child {
+			ok := false
+			for _, a := range parents {
+				for _, b := range parents {
+					ok = ok || child[i] == (a[i]+b[i])/2
+				}
+			}
+			if !ok {
+				t.Fatalf("component %d of %v is not a midpoint", i, child)
+			}
+		}
+	}
+}
+
+func TestLogRankWeights(t *testing.T) {
+	w := real.LogRankWeights(5)
+	var sum float64
+	for i := range w {
+		sum += w[i]
+		if 0 < i && w[i-1] <= w[i] {
+			t.Error("weights not decreasing:", w)
+		}
+	}
+	if math.Abs(sum-1) > 1e-12 || w[4] <= 0 {
+		t.Error(w)
+	}
+}
+
+func TestWeightedX(t *testing.T) {
+	child := make(real.Vector, 2)
+	real.WeightedX(child, []float64{0.75, 0.25}, real.Vector{1, 2}, real.Vector{5, 6})
+	if child[0] != 2 || child[1] != 3 {
+		t.Error(child)
+	}
+}
+
 // distributions.go
 // -------------------------
 
@@ -87,6 +136,107 @@ func TestStep(t *testing.T) {
 	}
 }
 
+func TestAdaptRate(t *testing.T) {
+	var up, down int
+	for i := 0; i < 1000; i++ {
+		r := real.AdaptRate(0.1, 0.5)
+		if r <= 0 || 1 <= r {
+			t.Fatalf("rate %v out of bounds", r)
+		}
+		if r > 0.1 {
+			up++
+		} else {
+			down++
+		}
+	}
+	if up < 400 || down < 400 {
+		t.Errorf("biased adaptation: %d up, %d down", up, down)
+	}
+}
+
+func TestSuccessRule(t *testing.T) {
+	rule := real.NewSuccessRule(10, 5)
+	for i := 0; i < 4; i++ {
+		if rule.Record(true) != 1 {
+			t.Fatal("adapted before the end of the period")
+		}
+	}
+	if rule.Record(true) != 1/rule.Factor || rule.Ratio() != 1 {
+		t.Error("step size not increased")
+	}
+	for i := 0; i < 9; i++ {
+		rule.Record(false)
+	}
+	if rule.Record(false) != rule.Factor || rule.Ratio() != 0 {
+		t.Error("step size not decreased")
+	}
+
+	// a (1+1)-ES on the sphere function
+	x := real.Random(10, 5)
+	steps := real.Vector{1}
+	rule = real.NewSuccessRule(100, 10)
+	sphere := func(v real.Vector) (sum float64) {
+		for i := range v {
+			sum += v[i] * v[i]
+		}
+		return sum
+	}
+	for i := 0; i < 5000; i++ {
+		y := x.Copy()
+		for j := range y {
+			y[j] += real.Normal(steps[0])
+		}
+		success := sphere(y) <= sphere(x)
+		if success {
+			x = y
+		}
+		rule.Adapt(steps, success)
+	}
+	if 1e-6 < sphere(x) {
+		t.Errorf("did not converge: %v with step %v", sphere(x), steps[0])
+	}
+}
+
+// sample.go
+// -------------------------
+
+func TestLatinHypercube(t *testing.T) {
+	vs := real.LatinHypercube(10, 3, -5, 5)
+	for j := 0; j < 3; j++ {
+		strata := make(map[int]bool)
+		for i := range vs {
+			x := vs[i][j]
+			if x < -5 || 5 <= x {
+				t.Fatal("out of bounds:", x)
+			}
+			strata[int(x+5)] = true
+		}
+		if len(strata) != 10 {
+			t.Error("strata not covered:", strata)
+		}
+	}
+}
+
+func TestHalton(t *testing.T) {
+	vs := real.Halton(4, 2, 0, 1)
+	want := []real.Vector{{0.5, 1.0 / 3}, {0.25, 2.0 / 3}, {0.75, 1.0 / 9}, {0.125, 4.0 / 9}}
+	for i := range want {
+		for j := range want[i] {
+			if math.Abs(vs[i][j]-want[i][j]) > 1e-12 {
+				t.Fatal(vs)
+			}
+		}
+	}
+}
+
+func TestOpposition(t *testing.T) {
+	vs := []real.Vector{{1, 2}, {-3, -1}}
+	vs = real.Opposition(vs, -4, 4, func(v real.Vector) float64 { return v[0] + v[1] })
+	if vs[0][0] != 1 || vs[0][1] != 2 || vs[1][0] != 3 || vs[1][1] != 1 {
+		t.Error(vs)
+	}
+}
+
 // vector.go
 // -------------------------
 
@@ -175,3 +325,51 @@ func TestBound(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestVector32(t *testing.T) {
+	x := real.Random32(8, 1)
+	y := x.Copy().Scale(2).Subtract(x)
+	for i := range x {
+		if x[i] < 0 || 1 <= x[i] || y[i] != x[i] {
+			t.Fail()
+		}
+	}
+
+	lo, hi := make(real.Vector32, 8), make(real.Vector32, 8)
+	for i := range hi {
+		hi[i] = 0.5
+	}
+	y.Add(x).Bound(lo, hi)
+	for i := range y {
+		if y[i] < 0 || 0.5 < y[i] {
+			t.Fail()
+		}
+	}
+
+	child := make(real.Vector32, 8)
+	real.ArithX(0, child, lo, hi)
+	real.UniformX(child, child, child)
+	for i := range child {
+		if child[i] != 0.25 {
+			t.Fail()
+		}
+	}
+
+	steps := real.Vec[float32]{1, 1, 1, 1, 1, 1, 1, 1}
+	steps.Adapt()
+	child.Step(steps)
+	for i := range steps {
+		if steps[i] == 1 || child[i] == 0.25 {
+			t.Fail()
+		}
+	}
+}
+
+func TestCheckBounds(t *testing.T) {
+	if err := (real.Vector{0, 0.5, 1}).CheckBounds(0, 1); err != nil {
+		t.Error(err)
+	}
+	if (real.Vector{0, 2}).CheckBounds(0, 1) == nil || (real.Vector{math.NaN()}).CheckBounds(0, 1) == nil {
+		t.Error("invalid vector passed the check")
+	}
+}
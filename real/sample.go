@@ -0,0 +1,95 @@
+package real
+
+import (
+	"math/rand"
+)
+
+// LatinHypercube samples m vectors of dimension n between [lo,hi) by Latin
+// hypercube sampling. Each dimension is divided into m strata of equal width,
+// and each stratum is covered by exactly one vector, giving better coverage of
+// every dimension than independent uniform samples.
+func LatinHypercube(m, n int, lo, hi float64) []Vector {
+	vs := make([]Vector, m)
+	for i := range vs {
+		vs[i] = make(Vector, n)
+	}
+	width := (hi - lo) / float64(m)
+	for j := 0; j < n; j++ {
+		for i, stratum := range rand.Perm(m) {
+			vs[i][j] = lo + (float64(stratum)+rand.Float64())*width
+		}
+	}
+	return vs
+}
+
+// Halton returns the first m vectors of dimension n of the Halton sequence,
+// scaled to [lo,hi). The Halton sequence is a low-discrepancy sequence which
+// uses the radical inverse in the jth prime base for dimension j. The sequence
+// starts at index 1, skipping the origin. Coverage degrades in high dimensions
+// as the bases grow; prefer LatinHypercube there.
+func Halton(m, n int, lo, hi float64) []Vector {
+	bases := primes(n)
+	vs := make([]Vector, m)
+	for i := range vs {
+		vs[i] = make(Vector, n)
+		for j, b := range bases {
+			vs[i][j] = lo + radicalInverse(i+1, b)*(hi-lo)
+		}
+	}
+	return vs
+}
+
+// radicalInverse mirrors the digits of i in base b about the radix point.
+func radicalInverse(i, b int) (x float64) {
+	f := 1 / float64(b)
+	for scale := f; i > 0; i /= b {
+		x += float64(i%b) * scale
+		scale *= f
+	}
+	return x
+}
+
+// primes returns the first n primes.
+func primes(n int) []int {
+	ps := make([]int, 0, n)
+	for x := 2; len(ps) < n; x++ {
+		prime := true
+		for _, p := range ps {
+			if p*p > x {
+				break
+			}
+			if x%p == 0 {
+				prime = false
+				break
+			}
+		}
+		if prime {
+			ps = append(ps, x)
+		}
+	}
+	return ps
+}
+
+// Opposite returns the opposite of v within [lo,hi], lo+hi-v, as a new vector.
+func Opposite(v Vector, lo, hi float64) Vector {
+	w := make(Vector, len(v))
+	for i := range v {
+		w[i] = lo + hi - v[i]
+	}
+	return w
+}
+
+// Opposition applies opposition-based learning to initial vectors within
+// [lo,hi]: each vector is compared to its opposite and the more fit of the two
+// is kept. The fitness function is maximized. Evaluating both halves of the
+// space doubles the chance of starting near the optimum at the cost of one
+// extra evaluation per vector. The vectors are replaced in place.
+func Opposition(vs []Vector, lo, hi float64, fitness func(Vector) float64) []Vector {
+	for i := range vs {
+		w := Opposite(vs[i], lo, hi)
+		if fitness(vs[i]) < fitness(w) {
+			vs[i] = w
+		}
+	}
+	return vs
+}
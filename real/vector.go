@@ -1,49 +1,76 @@
 package real
 
 import (
+	"fmt"
 	"math/rand"
 )
 
-type Vector []float64
+// Float is the constraint satisfied by the element types of vectors.
+type Float interface {
+	~float32 | ~float64
+}
+
+// Vec is a vector of floating point numbers. The element type is generic so
+// that memory-bound problems with very long genomes can use float32, halving
+// their footprint. Most code uses the Vector instantiation.
+type Vec[T Float] []T
+
+// Vector is a vector of float64.
+type Vector = Vec[float64]
+
+// Vector32 is a vector of float32.
+type Vector32 = Vec[float32]
 
 // Random generates a random vector of length n. Values are taken uniformly
 // between [0,scale).
 func Random(n int, scale float64) (v Vector) {
-	v = make(Vector, n)
+	return RandomVec[float64](n, scale)
+}
+
+// Random32 generates a random float32 vector of length n. Values are taken
+// uniformly between [0,scale).
+func Random32(n int, scale float32) (v Vector32) {
+	return RandomVec[float32](n, float64(scale))
+}
+
+// RandomVec generates a random vector of length n with any element type.
+// Values are taken uniformly between [0,scale).
+func RandomVec[T Float](n int, scale float64) (v Vec[T]) {
+	v = make(Vec[T], n)
 	for i := range v {
-		v[i] = rand.Float64() * scale
+		v[i] = T(rand.Float64() * scale)
 	}
 	return v
 }
 
-func (v Vector) Copy() Vector {
-	w := make(Vector, len(v))
+func (v Vec[T]) Copy() Vec[T] {
+	w := make(Vec[T], len(v))
 	copy(w, v)
 	return w
 }
 
-func (v Vector) Add(w Vector) Vector {
+func (v Vec[T]) Add(w Vec[T]) Vec[T] {
 	for i := range v {
 		v[i] += w[i]
 	}
 	return v
 }
 
-func (v Vector) Subtract(w Vector) Vector {
+func (v Vec[T]) Subtract(w Vec[T]) Vec[T] {
 	for i := range v {
 		v[i] -= w[i]
 	}
 	return v
 }
 
-func (v Vector) Scale(s float64) Vector {
+func (v Vec[T]) Scale(s T) Vec[T] {
 	for i := range v {
 		v[i] *= s
 	}
 	return v
 }
 
-func (v Vector) LowBound(min float64) Vector {
+func (v Vec[T]) LowBound(min T) Vec[T] {
 	for i := range v {
 		if v[i] < min {
 			v[i] = min
@@ -52,7 +79,7 @@ func (v Vector) LowBound(min float64) Vector {
 	return v
 }
 
-func (v Vector) HighBound(max float64) Vector {
+func (v Vec[T]) HighBound(max T) Vec[T] {
 	for i := range v {
 		if v[i] > max {
 			v[i] = max
@@ -61,7 +88,7 @@ func (v Vector) HighBound(max float64) Vector {
 	return v
 }
 
-func (v Vector) Bound(lower, upper Vector) Vector {
+func (v Vec[T]) Bound(lower, upper Vec[T]) Vec[T] {
 	for i := range v {
 		if v[i] > upper[i] {
 			v[i] = upper[i]
@@ -72,3 +99,15 @@ func (v Vector) Bound(lower, upper Vector) Vector {
 	}
 	return v
 }
+
+// CheckBounds returns an error if a value of the vector is not within [min,max],
+// or is not a number. It can be used as an invariant of a pipeline, see
+// op.Pipeline.Check.
+func (v Vec[T]) CheckBounds(min, max T) error {
+	for i, x := range v {
+		if !(min <= x && x <= max) {
+			return fmt.Errorf("real: value %v at %d is out of [%v,%v]", x, i, min, max)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package evo
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// A Recycler is a free-list of discarded genomes whose memory can be reused for
+// new genomes. Populations with a recycler put the genomes they discard into
+// it, and genome factories get genomes from it instead of allocating, e.g.:
+//
+//	recycler := &evo.Recycler{New: func() evo.Genome {
+//		return &tour{gene: make([]int, dim)}
+//	}}
+//	pop := new(gen.Population)
+//	pop.SetRecycler(recycler)
+//
+//	// in the EvolveFn
+//	child := recycler.Get().(*tour)
+//	perm.PMX(child.gene, mom.gene, dad.gene)
+//
+// Only pointer genomes are recycled. A recycled genome is handed out with its
+// old contents, except that its cached fitness is invalidated if it has an
+// Invalidate method, as genomes embedding a Cache do. The factory must
+// overwrite the rest of its state. Recyclers are safe for concurrent use.
+type Recycler struct {
+	// New allocates a genome when none can be reused.
+	New func() Genome
+
+	pool   sync.Pool
+	gets   atomic.Int64
+	reused atomic.Int64
+	puts   atomic.Int64
+}
+
+// invalidator is a genome with a cached fitness.
+type invalidator interface {
+	Invalidate()
+}
+
+// Get returns a discarded genome, or a new one if none is available.
+func (r *Recycler) Get() Genome {
+	r.gets.Add(1)
+	if g, ok := r.pool.Get().(Genome); ok {
+		r.reused.Add(1)
+		if inv, ok := g.(invalidator); ok {
+			inv.Invalidate()
+		}
+		return g
+	}
+	return r.New()
+}
+
+// Put adds a discarded genome to the free-list. The caller must not use the
+// genome afterwards. Genomes which are not pointers are ignored.
+func (r *Recycler) Put(g Genome) {
+	if g == nil || reflect.ValueOf(g).Kind() != reflect.Pointer {
+		return
+	}
+	r.puts.Add(1)
+	r.pool.Put(g)
+}
+
+// Recycle puts the genomes of old which are not in kept into the recycler.
+// Genomes are compared by identity, and each discarded genome is put once, even
+// if it appears in old more than once. Populations call Recycle when replacing
+// their members.
+func (r *Recycler) Recycle(old, kept []Genome) {
+	keep := make(map[uintptr]bool, len(kept))
+	for _, g := range kept {
+		if p, ok := identity(g); ok {
+			keep[p] = true
+		}
+	}
+	for _, g := range old {
+		if p, ok := identity(g); ok && !keep[p] {
+			keep[p] = true
+			r.Put(g)
+		}
+	}
+}
+
+// identity returns the address of a pointer genome.
+func identity(g Genome) (uintptr, bool) {
+	if g == nil {
+		return 0, false
+	}
+	v := reflect.ValueOf(g)
+	if v.Kind() != reflect.Pointer || v.IsNil() {
+		return 0, false
+	}
+	return v.Pointer(), true
+}
+
+// RecycleStats counts the activity of a recycler.
+type RecycleStats struct {
+	Gets   int // calls to Get
+	Reused int // calls to Get which reused a genome
+	Puts   int // genomes put into the recycler
+}
+
+// Stats returns the activity of the recycler so far.
+func (r *Recycler) Stats() RecycleStats {
+	return RecycleStats{
+		Gets:   int(r.gets.Load()),
+		Reused: int(r.reused.Load()),
+		Puts:   int(r.puts.Load()),
+	}
+}
+
+// ReuseRate returns the fraction of Gets which reused a genome.
+func (s RecycleStats) ReuseRate() float64 {
+	if s.Gets == 0 {
+		return 0
+	}
+	return float64(s.Reused) / float64(s.Gets)
+}
@@ -0,0 +1,77 @@
+package evo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+)
+
+type cell struct {
+	evo.Cache
+	n int
+}
+
+func (c *cell) Fitness() float64 {
+	return c.Cache.Fitness(func() float64 { return float64(c.n) })
+}
+
+func TestRecycler(t *testing.T) {
+	r := &evo.Recycler{New: func() evo.Genome { return new(cell) }}
+	a, b := &cell{n: 1}, &cell{n: 2}
+	a.Fitness()
+
+	// a is discarded twice but put once, b is kept, values are ignored
+	r.Recycle([]evo.Genome{a, b, a, point(3), nil}, []evo.Genome{b})
+	if s := r.Stats(); s.Puts != 1 {
+		t.Errorf("%d puts, want 1", s.Puts)
+	}
+
+	g := r.Get().(*cell)
+	if g == a {
+		g.n = 5
+		if g.Fitness() != 5 {
+			t.Error("fitness of recycled genome not invalidated")
+		}
+	}
+	r.Get()
+	if s := r.Stats(); s.Gets != 2 || s.Reused > 1 || s.ReuseRate() > 0.5 {
+		t.Errorf("bad stats %+v", s)
+	}
+}
+
+func TestRecyclerPopulation(t *testing.T) {
+	r := &evo.Recycler{New: func() evo.Genome { return new(cell) }}
+	seed := make([]evo.Genome, 16)
+	for i := range seed {
+		seed[i] = new(cell)
+	}
+	pop := new(gen.Population)
+	pop.SetRecycler(r)
+	pop.Evolve(seed, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		child := r.Get().(*cell)
+		child.n = current.(*cell).n + 1
+		return child
+	})
+	time.Sleep(20 * time.Millisecond)
+	pop.Stop()
+
+	view := pop.View()
+	defer view.Close()
+	seen := make(map[*cell]bool)
+	stats := view.Stats()
+	for _, g := range view.Members() {
+		c := g.(*cell)
+		if seen[c] {
+			t.Fatal("genome shared by two members")
+		}
+		seen[c] = true
+	}
+	if stats.Min() != stats.Max() || stats.Max() < 2 {
+		t.Errorf("inconsistent population %v", stats)
+	}
+	if s := r.Stats(); s.Reused == 0 || s.Puts < 16 {
+		t.Errorf("genomes not recycled: %+v", s)
+	}
+}
@@ -0,0 +1,282 @@
+// Package remote links islands running in separate processes.
+//
+// A Node connects a local population to islands in other processes. Every
+// period, it chooses some members of the local population as emigrants,
+// serializes them, and sends them to its peers over a Transport. Migrants
+// received from peers wait in an inbox until the wrapped EvolveFn of the
+// local population lets them replace the result of an evolution. The node is
+// agnostic of both the population and the transport: any evo.Population works,
+// and the package provides a net/rpc transport for processes on one machine or
+// a local network; a message broker can be plugged in by implementing
+// Transport.
+//
+// Delivery is at least once. A node retries each batch until the peer
+// acknowledges it, and drops batches it has already received, so a migrant
+// is injected at most once even when an acknowledgment is lost. Batches are
+// numbered within the session of the sending node, which begins when it
+// starts, so a peer which restarts under the same name is not mistaken for a
+// duplicate.
+package remote
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+)
+
+// A Batch is a group of serialized migrants.
+type Batch struct {
+	From     string   // the name of the sending node
+	Session  int64    // the start time of the sending node, in Unix nanoseconds
+	ID       uint64   // increases with every batch sent in the session
+	Migrants [][]byte // the encoded genomes
+}
+
+// A Transport moves batches between nodes, which are identified by name.
+type Transport interface {
+	// Send delivers a batch to the named peer. It returns once the peer has
+	// acknowledged the batch, or with an error if delivery failed or the
+	// context is done before the acknowledgment.
+	Send(ctx context.Context, peer string, b Batch) error
+
+	// Receive returns the channel of batches delivered to this node.
+	Receive() <-chan Batch
+
+	// Close stops the transport.
+	Close() error
+}
+
+// A Node links a local population to remote islands.
+type Node struct {
+	Name      string        // the name of the node, as known to its peers
+	Peers     []string      // the names of the neighbors in the topology
+	Transport Transport     // moves the migrants
+	N         int           // the number of emigrants per period
+	Period    time.Duration // the time between emigrations
+	Emigrants gen.Chooser   // chooses the emigrants, gen.ChooseRandom if nil
+	Retries   int           // the number of retries of a failed send
+	Backoff   time.Duration // the delay before the first retry, doubling after
+	Timeout   time.Duration // the deadline of each send, DefaultTimeout if 0
+	Capacity  int           // the maximum of waiting migrants, DefaultCapacity if 0
+	Clock     evo.Clock     // schedules the periods and retries, evo.SystemClock if nil
+
+	// Encode and Decode serialize genomes, e.g. with encoding/json.
+	Encode func(evo.Genome) ([]byte, error)
+	Decode func([]byte) (evo.Genome, error)
+
+	mu     sync.Mutex
+	inbox  []evo.Genome
+	seen   map[string]mark // the last batch received from each peer
+	sess   int64
+	seq    uint64
+	err    error
+	sent   int
+	recvd  int
+	ctx    context.Context // done once the node stops
+	cancel context.CancelFunc
+	done   sync.WaitGroup
+}
+
+// Defaults of the fields of a Node.
+const (
+	DefaultTimeout  = 10 * time.Second // the deadline of each send
+	DefaultCapacity = 1024             // the maximum of waiting migrants
+)
+
+// A mark identifies a batch within the batches of a peer.
+type mark struct {
+	session int64
+	id      uint64
+}
+
+// Start starts emigrating members of the population and receiving migrants.
+// The population should be evolving with an EvolveFn wrapped by Wrap, or the
+// received migrants are never injected.
+func (n *Node) Start(pop evo.Population) {
+	n.mu.Lock()
+	n.seen = make(map[string]mark)
+	n.sess = n.clock().Now().UnixNano()
+	n.ctx, n.cancel = context.WithCancel(context.Background())
+	n.mu.Unlock()
+	n.done.Add(2)
+	go n.emigrate(pop)
+	go n.receive()
+}
+
+// Stop stops the node, abandoning the send in progress, if any. It does not
+// stop the population or close the transport.
+func (n *Node) Stop() {
+	n.cancel()
+	n.done.Wait()
+}
+
+// Wrap returns an EvolveFn which evolves with body, except that when migrants
+// are waiting, the next migrant replaces the result of the evolution.
+func (n *Node) Wrap(body evo.EvolveFn) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		next := body(current, suitors)
+		n.mu.Lock()
+		defer n.mu.Unlock()
+		if len(n.inbox) == 0 {
+			return next
+		}
+		immigrant := n.inbox[0]
+		n.inbox = n.inbox[1:]
+		return immigrant
+	}
+}
+
+// Stats returns the number of migrants sent and received.
+func (n *Node) Stats() (sent, received int) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.sent, n.recvd
+}
+
+// Err returns the last error of the node, if any.
+func (n *Node) Err() error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return n.err
+}
+
+// emigrate periodically sends emigrants to a random peer.
+func (n *Node) emigrate(pop evo.Population) {
+	defer n.done.Done()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case <-n.clock().After(n.Period):
+		}
+		if len(n.Peers) == 0 {
+			continue
+		}
+		b, err := n.batch(pop)
+		if err == nil {
+			err = n.send(n.Peers[rand.Intn(len(n.Peers))], b)
+		}
+		if err != nil && n.ctx.Err() != nil {
+			return // the send was abandoned by Stop
+		}
+		n.mu.Lock()
+		if err != nil {
+			n.err = err
+		} else {
+			n.sent += len(b.Migrants)
+		}
+		n.mu.Unlock()
+	}
+}
+
+// batch chooses and encodes emigrants of the population.
+func (n *Node) batch(pop evo.Population) (b Batch, err error) {
+	v := pop.View()
+	defer v.Close()
+	members := v.Members()
+	k := n.N
+	if len(members) < k {
+		k = len(members)
+	}
+	choose := n.Emigrants
+	if choose == nil {
+		choose = gen.ChooseRandom
+	}
+	for _, i := range choose(members, k) {
+		data, err := n.Encode(members[i])
+		if err != nil {
+			return b, err
+		}
+		b.Migrants = append(b.Migrants, data)
+	}
+	n.mu.Lock()
+	n.seq++
+	b.From, b.Session, b.ID = n.Name, n.sess, n.seq
+	n.mu.Unlock()
+	return b, nil
+}
+
+// send sends a batch, retrying with exponential backoff.
+func (n *Node) send(peer string, b Batch) (err error) {
+	delay := n.Backoff
+	for try := 0; ; try++ {
+		timeout := n.Timeout
+		if timeout == 0 {
+			timeout = DefaultTimeout
+		}
+		ctx, cancel := context.WithTimeout(n.ctx, timeout)
+		err = n.Transport.Send(ctx, peer, b)
+		cancel()
+		if err == nil || n.Retries <= try {
+			return err
+		}
+		select {
+		case <-n.ctx.Done():
+			return err
+		case <-n.clock().After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// receive decodes incoming batches into the inbox, dropping duplicates.
+func (n *Node) receive() {
+	defer n.done.Done()
+	in := n.Transport.Receive()
+	for {
+		select {
+		case <-n.ctx.Done():
+			return
+		case b, ok := <-in:
+			if !ok {
+				return
+			}
+			n.accept(b)
+		}
+	}
+}
+
+// accept decodes a batch into the inbox unless it was already received or
+// belongs to an earlier session of the peer. When the inbox is full, the
+// oldest waiting migrants are dropped.
+func (n *Node) accept(b Batch) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	last, ok := n.seen[b.From]
+	if ok && (b.Session < last.session || b.Session == last.session && b.ID <= last.id) {
+		return
+	}
+	n.seen[b.From] = mark{b.Session, b.ID}
+	capacity := n.Capacity
+	if capacity == 0 {
+		capacity = DefaultCapacity
+	}
+	for _, data := range b.Migrants {
+		g, err := n.Decode(data)
+		if err != nil {
+			n.err = err
+			continue
+		}
+		if len(n.inbox) == capacity {
+			n.inbox = n.inbox[1:]
+		}
+		n.inbox = append(n.inbox, g)
+		n.recvd++
+	}
+}
+
+// clock returns the clock of the node.
+func (n *Node) clock() evo.Clock {
+	if n.Clock == nil {
+		return evo.SystemClock
+	}
+	return n.Clock
+}
+
+// ErrClosed is returned when sending over a closed transport.
+var ErrClosed = errors.New("remote: transport closed")
@@ -0,0 +1,212 @@
+package remote_test
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/remote"
+)
+
+// number is a genome which is its own fitness.
+type number float64
+
+func (x number) Fitness() float64 { return float64(x) }
+
+func encode(g evo.Genome) ([]byte, error) { return json.Marshal(g) }
+
+func decode(data []byte) (evo.Genome, error) {
+	var x number
+	err := json.Unmarshal(data, &x)
+	return x, err
+}
+
+func keep(current evo.Genome, _ []evo.Genome) evo.Genome {
+	time.Sleep(time.Millisecond)
+	return current
+}
+
+// remote.go
+// -------------------------
+
+func TestNode(t *testing.T) {
+	a, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer a.Close()
+	b, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer b.Close()
+
+	// island a holds only 1s, island b only 2s
+	src := &remote.Node{Name: "a", Peers: []string{b.Addr()}, Transport: a,
+		N: 2, Period: time.Millisecond, Encode: encode, Decode: decode}
+	dst := &remote.Node{Name: "b", Transport: b, Encode: encode, Decode: decode}
+	var popA, popB gen.Population
+	popA.Evolve([]evo.Genome{number(1), number(1), number(1)}, src.Wrap(keep))
+	popB.Evolve([]evo.Genome{number(2), number(2), number(2)}, dst.Wrap(keep))
+	src.Start(&popA)
+	dst.Start(&popB)
+
+	deadline := time.Now().Add(5 * time.Second)
+	arrived := func() bool {
+		sent, _ := src.Stats()
+		return popB.Stats().Min() == 1 && sent != 0
+	}
+	for !arrived() && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	src.Stop()
+	dst.Stop()
+	popA.Stop()
+	popB.Stop()
+	if popB.Stats().Min() != 1 {
+		t.Error("no migrant arrived")
+	}
+	if sent, _ := src.Stats(); sent == 0 || src.Err() != nil {
+		t.Error(sent, src.Err())
+	}
+}
+
+func TestDuplicates(t *testing.T) {
+	tr, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer tr.Close()
+	node := &remote.Node{Name: "b", Transport: tr, Encode: encode, Decode: decode}
+	var pop gen.Population
+	pop.Evolve([]evo.Genome{number(0)}, node.Wrap(keep))
+	node.Start(&pop)
+
+	// a lost acknowledgment makes the sender deliver the batch again
+	batch := remote.Batch{From: "a", ID: 1, Migrants: [][]byte{[]byte("1")}}
+	for i := 0; i < 2; i++ {
+		if err := tr.Send(context.Background(), tr.Addr(), batch); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	node.Stop()
+	pop.Stop()
+	if _, received := node.Stats(); received != 1 {
+		t.Error("received", received)
+	}
+}
+
+func TestStoppedPeer(t *testing.T) {
+	a, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer a.Close()
+	b, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer b.Close()
+
+	// no node receives from b, so its inbox fills up
+	src := &remote.Node{Name: "a", Peers: []string{b.Addr()}, Transport: a, N: 1,
+		Period: time.Millisecond, Retries: 1000, Backoff: time.Millisecond,
+		Encode: encode, Decode: decode}
+	var pop gen.Population
+	pop.Evolve([]evo.Genome{number(1)}, src.Wrap(keep))
+	src.Start(&pop)
+	time.Sleep(100 * time.Millisecond)
+
+	stopped := make(chan struct{})
+	go func() {
+		src.Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("node did not stop")
+	}
+	pop.Stop()
+	if sent, _ := src.Stats(); sent != 16 {
+		t.Error("sent", sent)
+	}
+}
+
+func TestSessions(t *testing.T) {
+	tr, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer tr.Close()
+	node := &remote.Node{Name: "b", Transport: tr, Capacity: 2, Encode: encode, Decode: decode}
+	var pop gen.Population
+	pop.Evolve([]evo.Genome{number(0)}, keep)
+	node.Start(&pop)
+
+	// the peer restarts, numbering its batches from 1 again, and a retry of
+	// its first session arrives late
+	for _, b := range []remote.Batch{
+		{From: "a", Session: 1, ID: 5, Migrants: [][]byte{[]byte("1")}},
+		{From: "a", Session: 2, ID: 1, Migrants: [][]byte{[]byte("2"), []byte("3"), []byte("4")}},
+		{From: "a", Session: 1, ID: 6, Migrants: [][]byte{[]byte("5")}},
+	} {
+		if err := tr.Send(context.Background(), tr.Addr(), b); err != nil {
+			t.Fatal(err)
+		}
+	}
+	time.Sleep(20 * time.Millisecond)
+	node.Stop()
+	pop.Stop()
+	if _, received := node.Stats(); received != 4 {
+		t.Error("received", received)
+	}
+
+	// only the newest migrants fit in the inbox
+	wrapped := node.Wrap(keep)
+	for _, want := range []number{3, 4, 0} {
+		if got := wrapped(number(0), nil); got != want {
+			t.Errorf("got %v, want %v", got, want)
+		}
+	}
+}
+
+func TestClock(t *testing.T) {
+	a, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer a.Close()
+	b, err := remote.ListenRPC("localhost:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer b.Close()
+
+	clock := evo.NewFakeClock(time.Unix(0, 0))
+	src := &remote.Node{Name: "a", Peers: []string{b.Addr()}, Transport: a, N: 1,
+		Period: time.Hour, Clock: clock, Encode: encode, Decode: decode}
+	var pop gen.Population
+	pop.Evolve([]evo.Genome{number(1)}, keep)
+	src.Start(&pop)
+	defer pop.Stop()
+	defer src.Stop()
+
+	clock.BlockUntil(1)
+	if sent, _ := src.Stats(); sent != 0 {
+		t.Fatal("sent before the period", sent)
+	}
+	clock.Advance(time.Hour)
+	select {
+	case batch := <-b.Receive():
+		if batch.Session != 0 || batch.ID != 1 {
+			t.Error(batch)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("no batch after the period")
+	}
+}
@@ -0,0 +1,138 @@
+package remote
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+)
+
+// RPC is a Transport over net/rpc. Peers are named by their network address.
+type RPC struct {
+	ln     net.Listener
+	inbox  chan Batch
+	mu     sync.Mutex
+	conns  map[string]*rpc.Client
+	closed bool
+}
+
+// ListenRPC returns a transport listening for batches on the TCP address, e.g.
+// "localhost:7000". Use the address ":0" to choose a free port, and Addr to
+// learn it.
+func ListenRPC(addr string) (*RPC, error) {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	t := &RPC{
+		ln:    ln,
+		inbox: make(chan Batch, 16),
+		conns: make(map[string]*rpc.Client),
+	}
+	srv := rpc.NewServer()
+	if err := srv.RegisterName("Island", &receiver{t.inbox}); err != nil {
+		ln.Close()
+		return nil, err
+	}
+	go srv.Accept(ln)
+	return t, nil
+}
+
+// Addr returns the address of the transport, which is its name for peers.
+func (t *RPC) Addr() string {
+	return t.ln.Addr().String()
+}
+
+// Send delivers a batch to the peer at the given address. A connection which
+// fails, or whose peer does not acknowledge before the context is done, is
+// closed and redialed by the next send. A peer whose inbox is full rejects the
+// batch, leaving the connection open.
+func (t *RPC) Send(ctx context.Context, peer string, b Batch) error {
+	c, err := t.dial(peer)
+	if err != nil {
+		return err
+	}
+	var ack bool
+	var reply *rpc.Call
+	call := c.Go("Island.Deliver", b, &ack, nil)
+	select {
+	case reply = <-call.Done:
+	case <-ctx.Done():
+		select {
+		case reply = <-call.Done: // the reply raced the context
+		default:
+		}
+	}
+	if reply == nil {
+		err = ctx.Err()
+	} else if _, ok := reply.Error.(rpc.ServerError); ok || reply.Error == nil {
+		return reply.Error
+	} else {
+		err = reply.Error
+	}
+	t.mu.Lock()
+	if t.conns[peer] == c {
+		delete(t.conns, peer)
+	}
+	t.mu.Unlock()
+	c.Close()
+	return err
+}
+
+// dial returns a client connected to the peer.
+func (t *RPC) dial(peer string) (*rpc.Client, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, ErrClosed
+	}
+	if c, ok := t.conns[peer]; ok {
+		return c, nil
+	}
+	c, err := rpc.Dial("tcp", peer)
+	if err != nil {
+		return nil, err
+	}
+	t.conns[peer] = c
+	return c, nil
+}
+
+// Receive returns the channel of batches delivered to this transport.
+func (t *RPC) Receive() <-chan Batch {
+	return t.inbox
+}
+
+// Close stops listening and closes the connections to peers.
+func (t *RPC) Close() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+	for peer, c := range t.conns {
+		c.Close()
+		delete(t.conns, peer)
+	}
+	return t.ln.Close()
+}
+
+// receiver is the RPC service of a transport.
+type receiver struct {
+	inbox chan Batch
+}
+
+// Deliver receives a batch, acknowledging it once it is queued. A batch is
+// rejected rather than blocking the caller when the inbox is full, e.g. when
+// the node of the transport has stopped, so the sender retries it later.
+func (r *receiver) Deliver(b Batch, ack *bool) error {
+	select {
+	case r.inbox <- b:
+		*ack = true
+		return nil
+	default:
+		return ErrFull
+	}
+}
+
+// ErrFull is returned by a peer whose inbox is full. Over net/rpc, it reaches
+// the sender as an rpc.ServerError with the same message.
+var ErrFull = errors.New("remote: inbox full")
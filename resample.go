@@ -0,0 +1,76 @@
+package evo
+
+import (
+	"math"
+	"sync"
+)
+
+// A Resampled genome wraps a genome with a noisy fitness function, e.g. a
+// stochastic simulation. Its fitness is the mean of repeated evaluations of the
+// underlying genome, whose Fitness method must not itself be cached. The
+// samples are taken on the first call to Fitness, and more can be added later
+// with Sample, e.g. for genomes which survive many generations.
+type Resampled struct {
+	Genome
+	min, max int     // bounds on the number of samples taken initially
+	width    float64 // target half-width of the 95% confidence interval
+
+	mu      sync.Mutex
+	stats   Stats
+	sampled bool
+}
+
+// Resample wraps a noisy genome, taking n samples of its fitness.
+func Resample(g Genome, n int) *Resampled {
+	return &Resampled{Genome: g, min: n, max: n}
+}
+
+// ResampleUntil wraps a noisy genome, taking samples of its fitness until the
+// 95% confidence interval of the mean is no wider than ±width. At least min and
+// at most max samples are taken.
+func ResampleUntil(g Genome, min, max int, width float64) *Resampled {
+	return &Resampled{Genome: g, min: min, max: max, width: width}
+}
+
+// Fitness returns the mean of the sampled fitnesses.
+func (r *Resampled) Fitness() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !r.sampled {
+		for r.stats.Count() < r.max {
+			if r.min <= r.stats.Count() && r.halfwidth() <= r.width {
+				break
+			}
+			r.stats = r.stats.Put(r.Genome.Fitness())
+		}
+		r.sampled = true
+	}
+	return r.stats.Mean()
+}
+
+// Sample evaluates the underlying genome once more and returns the new mean.
+func (r *Resampled) Sample() float64 {
+	r.Fitness()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stats = r.stats.Put(r.Genome.Fitness())
+	return r.stats.Mean()
+}
+
+// Samples returns statistics on the sampled fitnesses.
+func (r *Resampled) Samples() Stats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.stats
+}
+
+// halfwidth returns the half-width of the 95% confidence interval of the mean,
+// or +Inf with fewer than two samples.
+func (r *Resampled) halfwidth() float64 {
+	n := float64(r.stats.Count())
+	if n < 2 {
+		return math.Inf(+1)
+	}
+	sd := math.Sqrt(r.stats.Var() * n / (n - 1))
+	return 1.96 * sd / math.Sqrt(n)
+}
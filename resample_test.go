@@ -0,0 +1,36 @@
+package evo_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+// alternating is a noisy genome whose fitness alternates between 0 and 2.
+type alternating int
+
+func (a *alternating) Fitness() float64 {
+	*a++
+	return float64(*a%2) * 2
+}
+
+func TestResample(t *testing.T) {
+	var a alternating
+	r := evo.Resample(&a, 4)
+	if r.Fitness() != 1 || r.Fitness() != 1 || r.Samples().Count() != 4 {
+		t.Fail()
+	}
+	r.Sample()
+	if r.Samples().Count() != 5 || r.Fitness() != 1.2 {
+		t.Fail()
+	}
+}
+
+func TestResampleUntil(t *testing.T) {
+	var a alternating
+	r := evo.ResampleUntil(&a, 2, 100, 0.5)
+	r.Fitness()
+	if n := r.Samples().Count(); n < 10 || 30 < n {
+		t.Fail()
+	}
+}
@@ -0,0 +1,198 @@
+// Package restart provides a restart strategy for populations which converge
+// prematurely.
+//
+// A Manager evolves a sequence of populations. Each population runs until it
+// converges, i.e. until the spread of its fitness collapses or its best fitness
+// stagnates, at which point the best genome is archived and a fresh population
+// is seeded, optionally keeping the elites of the last one. Growing the size of
+// each new population gives the IPOP strategy of Auger and Hansen, which trades
+// the speed of small populations for the robustness of large ones as restarts
+// accumulate.
+//
+//	m := &restart.Manager{
+//		New:    func() evo.Population { return new(gen.Population) },
+//		Seed:   randomGenomes,
+//		Body:   body,
+//		Size:   16,
+//		Growth: 2,
+//		RSD:    1e-3,
+//	}
+//	best := m.Run(func() bool { return len(m.Archive()) == 10 })
+package restart
+
+import (
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Manager restarts populations when they converge. The fields must be set
+// before calling Run and not modified while it runs.
+type Manager struct {
+	New  func() evo.Population    // creates an empty population
+	Seed func(n int) []evo.Genome // creates n random genomes
+	Body evo.EvolveFn             // the body of the evolution
+	Size int                      // the size of the first population
+
+	// Growth scales the size of each population over the last, e.g. 2 for
+	// IPOP. Values of at most 1 keep the size constant.
+	Growth float64
+
+	// Elites is the number of the most fit genomes of each population which
+	// seed the next, in place of random genomes.
+	Elites int
+
+	// A population has converged when the relative standard deviation of its
+	// fitness falls below RSD, or when its best fitness has not improved for
+	// the duration of Patience. Zero values disable the respective criterion.
+	RSD      float64
+	Patience time.Duration
+
+	// Freq is the frequency at which convergence is checked. The default is
+	// 10 milliseconds.
+	Freq time.Duration
+
+	mu      sync.Mutex
+	archive []Record
+	stopped bool
+	current evo.Population
+}
+
+// A Record describes one run of a population, ended by a restart or by the
+// termination of the manager.
+type Record struct {
+	Size    int           // the size of the population
+	Best    evo.Genome    // the most fit genome
+	Fitness float64       // the fitness of the most fit genome
+	Elapsed time.Duration // the duration of the run
+}
+
+// Run evolves populations until done returns true or Stop is called, and
+// returns the most fit genome found. The done condition is checked at the
+// frequency of the manager.
+func (m *Manager) Run(done evo.ConditionFn) evo.Genome {
+	freq := m.Freq
+	if freq <= 0 {
+		freq = 10 * time.Millisecond
+	}
+	m.mu.Lock()
+	m.stopped = false
+	m.mu.Unlock()
+
+	var (
+		size   = m.Size
+		elites []evo.Genome
+	)
+	for {
+		seed := make([]evo.Genome, 0, size)
+		for _, e := range elites {
+			if len(seed) < size {
+				seed = append(seed, e)
+			}
+		}
+		seed = append(seed, m.Seed(size-len(seed))...)
+
+		var (
+			pop      = m.New()
+			start    = time.Now()
+			best     = math.Inf(-1)
+			improved = start
+			finished bool
+		)
+		m.mu.Lock()
+		m.current = pop
+		if m.stopped {
+			m.mu.Unlock()
+			break
+		}
+		m.mu.Unlock()
+
+		pop.Evolve(seed, m.Body)
+		pop.Poll(freq, func() bool {
+			if done() || m.isStopped() {
+				finished = true
+				return true
+			}
+			stats := pop.Stats()
+			if best < stats.Max() {
+				best = stats.Max()
+				improved = time.Now()
+			}
+			if 0 < m.RSD && (stats.SD() == 0 || math.Abs(stats.RSD()) < m.RSD) {
+				return true
+			}
+			return 0 < m.Patience && m.Patience <= time.Since(improved)
+		})
+		pop.Wait()
+
+		view := pop.View()
+		top := view.TopK(max(m.Elites, 1))
+		view.Close()
+		m.mu.Lock()
+		m.archive = append(m.archive, Record{
+			Size:    size,
+			Best:    top[0],
+			Fitness: top[0].Fitness(),
+			Elapsed: time.Since(start),
+		})
+		m.current = nil
+		m.mu.Unlock()
+
+		if finished {
+			break
+		}
+		if 0 < m.Elites {
+			elites = top
+		}
+		if 1 < m.Growth {
+			size = int(math.Ceil(float64(size) * m.Growth))
+		}
+	}
+	return m.Best()
+}
+
+// isStopped reports whether Stop has been called.
+func (m *Manager) isStopped() bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.stopped
+}
+
+// Stop ends the current run and causes Run to return.
+func (m *Manager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.stopped = true
+}
+
+// Archive returns the records of the runs so far, in order.
+func (m *Manager) Archive() []Record {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Record(nil), m.archive...)
+}
+
+// Best returns the most fit genome in the archive, or nil if no run has ended.
+func (m *Manager) Best() evo.Genome {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	var best *Record
+	for i := range m.archive {
+		if best == nil || best.Fitness < m.archive[i].Fitness {
+			best = &m.archive[i]
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	return best.Best
+}
+
+// Current returns the population currently evolving, or nil between runs.
+func (m *Manager) Current() evo.Population {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.current
+}
@@ -0,0 +1,89 @@
+package restart_test
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/restart"
+)
+
+type num float64
+
+func (n num) Fitness() float64 { return float64(n) }
+
+func random(n int) []evo.Genome {
+	seed := make([]evo.Genome, n)
+	for i := range seed {
+		seed[i] = num(rand.Float64())
+	}
+	return seed
+}
+
+// best converges immediately to the best suitor
+func best(current evo.Genome, suitors []evo.Genome) evo.Genome {
+	b := suitors[0]
+	for _, s := range suitors {
+		if b.Fitness() < s.Fitness() {
+			b = s
+		}
+	}
+	return b
+}
+
+func TestManager(t *testing.T) {
+	m := &restart.Manager{
+		New:    func() evo.Population { return new(gen.Population) },
+		Seed:   random,
+		Body:   best,
+		Size:   4,
+		Growth: 2,
+		Elites: 1,
+		RSD:    1e-9,
+		Freq:   time.Millisecond,
+	}
+	got := m.Run(func() bool { return len(m.Archive()) >= 4 })
+
+	archive := m.Archive()
+	if len(archive) != 5 {
+		t.Fatalf("%d runs, want 5", len(archive))
+	}
+	for i, rec := range archive {
+		if rec.Size != 4<<i {
+			t.Errorf("run %d has size %d, want %d", i, rec.Size, 4<<i)
+		}
+		// the elite carries over, so the best never gets worse
+		if 0 < i && rec.Fitness < archive[i-1].Fitness {
+			t.Errorf("run %d lost the elite", i)
+		}
+	}
+	if got != archive[4].Best || got != m.Best() {
+		t.Error("wrong best genome")
+	}
+}
+
+func TestPatience(t *testing.T) {
+	m := &restart.Manager{
+		New:      func() evo.Population { return new(gen.Population) },
+		Seed:     random,
+		Body:     func(current evo.Genome, _ []evo.Genome) evo.Genome { return current },
+		Size:     4,
+		Patience: 20 * time.Millisecond,
+		Freq:     time.Millisecond,
+	}
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		m.Stop()
+	}()
+	m.Run(func() bool { return false })
+	if n := len(m.Archive()); n < 2 || 6 < n {
+		t.Errorf("%d runs, want about 4", n)
+	}
+	for _, rec := range m.Archive() {
+		if rec.Size != 4 {
+			t.Error("size changed without growth")
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package evo
+
+import (
+	"encoding/json"
+	"runtime"
+	"runtime/debug"
+	"time"
+)
+
+// A RunInfo describes the circumstances of a run, so that exported statistics
+// and checkpoints can be traced back to the code and configuration which
+// produced them.
+//
+// The seeds are the seeds of any random sources the user controls. Evo itself
+// draws from the global source of math/rand, which is seeded randomly and can
+// not be reseeded since Go 1.24, so runs using it are not reproducible from a
+// seed.
+type RunInfo struct {
+	Tag       string          `json:"tag,omitempty"`      // a user label for the run
+	Started   time.Time       `json:"started"`            // when the run was captured
+	Seeds     []int64         `json:"seeds,omitempty"`    // seeds of user random sources
+	Config    json.RawMessage `json:"config,omitempty"`   // the population and operator parameters
+	GoVersion string          `json:"go"`                 // the version of Go
+	Platform  string          `json:"platform"`           // GOOS/GOARCH
+	CPUs      int             `json:"cpus"`               // the number of logical CPUs
+	Module    string          `json:"module,omitempty"`   // the main module path and version
+	Revision  string          `json:"revision,omitempty"` // the VCS revision of the build
+	Modified  bool            `json:"modified,omitempty"` // the working tree had local changes
+}
+
+// Capture returns the RunInfo of a run starting now. The config, typically a
+// struct holding the population configuration and operator parameters, is
+// stored as JSON; if it can not be marshaled, Capture panics. The build
+// information is read from the binary when available.
+func Capture(tag string, config interface{}, seeds ...int64) RunInfo {
+	info := RunInfo{
+		Tag:       tag,
+		Started:   time.Now(),
+		Seeds:     seeds,
+		GoVersion: runtime.Version(),
+		Platform:  runtime.GOOS + "/" + runtime.GOARCH,
+		CPUs:      runtime.NumCPU(),
+	}
+	if config != nil {
+		b, err := json.Marshal(config)
+		if err != nil {
+			panic("evo: config can not be marshaled: " + err.Error())
+		}
+		info.Config = b
+	}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		info.Module = bi.Main.Path + "@" + bi.Main.Version
+		for _, s := range bi.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				info.Revision = s.Value
+			case "vcs.modified":
+				info.Modified = s.Value == "true"
+			}
+		}
+	}
+	return info
+}
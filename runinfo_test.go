@@ -0,0 +1,35 @@
+package evo_test
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestCapture(t *testing.T) {
+	config := struct {
+		Size int     `json:"size"`
+		Rate float64 `json:"rate"`
+	}{100, 0.1}
+	info := evo.Capture("baseline", config, 42)
+	if info.Tag != "baseline" || info.GoVersion != runtime.Version() || info.CPUs < 1 {
+		t.Error(info)
+	}
+	if len(info.Seeds) != 1 || info.Seeds[0] != 42 {
+		t.Error(info.Seeds)
+	}
+	if string(info.Config) != `{"size":100,"rate":0.1}` {
+		t.Error(string(info.Config))
+	}
+
+	b, err := json.Marshal(info)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back evo.RunInfo
+	if err := json.Unmarshal(b, &back); err != nil || back.Tag != info.Tag || !back.Started.Equal(info.Started) {
+		t.Error(back, err)
+	}
+}
@@ -0,0 +1,153 @@
+package sel
+
+import (
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// Aged genomes report their age for Age-Fitness Pareto Optimization. The age
+// of a genome is conventionally the number of generations since the oldest
+// ancestor of its lineage was created at random: a child is one generation
+// older than its oldest parent, and a random newcomer has age 0. Genomes which
+// are not Aged are treated as newcomers.
+type Aged interface {
+	evo.Genome
+	Age() int
+}
+
+// An afcomp competes in an age-fitness Pareto competition.
+type afcomp struct {
+	evo.Genome
+	age  int
+	fit  float64
+	rank int // the index of the Pareto front of the competitor
+}
+
+// dominates returns true if a is at least as young and as fit as b, and
+// strictly younger or fitter.
+func (a afcomp) dominates(b afcomp) bool {
+	return a.age <= b.age && a.fit >= b.fit && (a.age < b.age || a.fit > b.fit)
+}
+
+// afpo ranks the competitors by Pareto front and sorts them, best front first
+// and by fitness within each front.
+func afpo(genomes []evo.Genome) []afcomp {
+	pool := make([]afcomp, len(genomes))
+	for i, g := range genomes {
+		pool[i].Genome = g
+		if a, ok := g.(Aged); ok {
+			pool[i].age = a.Age()
+		}
+	}
+	done := make(chan struct{})
+	for i := range pool {
+		go func(i int) {
+			pool[i].fit = pool[i].Genome.Fitness()
+			done <- struct{}{}
+		}(i)
+	}
+	for range pool {
+		<-done
+	}
+
+	// peel off one front at a time
+	left := make([]int, len(pool))
+	for i := range left {
+		left[i] = i
+	}
+	for rank := 0; len(left) > 0; rank++ {
+		var next []int
+		for _, i := range left {
+			dominated := false
+			for _, j := range left {
+				if pool[j].dominates(pool[i]) {
+					dominated = true
+					break
+				}
+			}
+			if dominated {
+				next = append(next, i)
+			} else {
+				pool[i].rank = rank
+			}
+		}
+		left = next
+	}
+
+	sort.SliceStable(pool, func(i, j int) bool {
+		if pool[i].rank != pool[j].rank {
+			return pool[i].rank < pool[j].rank
+		}
+		return pool[i].fit > pool[j].fit
+	})
+	return pool
+}
+
+// AFPO implements Age-Fitness Pareto Optimization. Age and fitness are treated
+// as two objectives, preferring young and fit genomes, and the µ winners are
+// taken from the successive Pareto fronts of the genomes. The last front to be
+// taken is truncated by fitness. Then the fresh least fit winners are replaced
+// by random newcomers, so new lineages keep entering the population and old
+// ones only survive while no younger genome is as fit. See Schmidt and Lipson,
+// "Age-Fitness Pareto Optimization", 2011.
+func AFPO(µ, fresh int, random func() evo.Genome, genomes ...evo.Genome) (winners []evo.Genome) {
+	pool := afpo(genomes)
+	winners = make([]evo.Genome, µ)
+	for i := range winners {
+		if i < µ-fresh && i < len(pool) {
+			winners[i] = pool[i].Genome
+		} else {
+			winners[i] = random()
+		}
+	}
+	return winners
+}
+
+// AFPOPool creates an age-fitness Pareto pool selector. Once λ competitors have
+// been put into the pool, the µ winners are chosen as by AFPO, including fresh
+// random newcomers. The winners must then be retrieved from the pool. Once the
+// winners are retrieved, the pool starts accepting competitors for another
+// competition.
+func AFPOPool(µ, λ, fresh int, random func() evo.Genome) Pool {
+	p := newPool(µ, λ, true)
+
+	go func() {
+		// the competitors, memory shared accross iterations
+		pool := make([]evo.Genome, 0, λ)
+
+		for {
+			// wait to receive all competitors
+			for len(pool) < λ {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case val := <-p.in:
+					pool = append(pool, val)
+				}
+			}
+
+			// send out the winners
+			winners := AFPO(µ, fresh, random, pool...)
+			for i := 0; i < len(winners); {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case p.out <- winners[i]:
+					i++
+				}
+			}
+			pool = pool[:0]
+		}
+	}()
+
+	return p
+}
@@ -0,0 +1,125 @@
+package sel
+
+import (
+	"math"
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Cleared genome wraps a genome, replacing its fitness with its fitness after
+// clearing. Winners of a selection among cleared genomes are unwrapped through
+// the embedded Genome.
+type Cleared struct {
+	evo.Genome
+	fit float64
+}
+
+// Fitness returns the fitness after clearing.
+func (c *Cleared) Fitness() float64 {
+	return c.fit
+}
+
+// Clear implements the clearing procedure of Petrowski, a niching method for
+// multimodal problems. The genomes are visited from the most to the least fit.
+// Each genome which is not yet cleared becomes the dominant of a niche: within
+// the niche radius of it under the distance function, only the best κ genomes
+// keep their fitness, and the others are cleared. Cleared genomes lose every
+// competition: their fitness becomes -Inf, the equivalent of the fitness 0 of
+// the original procedure for fitness which may be negative.
+//
+// Clear is a pre-selection transform. The returned genomes wrap the genomes in
+// the same order, and may be given to any function or pool selector. See
+// ClearingPool for an elite pool with clearing. See Petrowski, "A Clearing
+// Procedure as a Niching Method for Genetic Algorithms", 1996.
+func Clear(radius float64, κ int, dist func(a, b evo.Genome) float64, genomes ...evo.Genome) []evo.Genome {
+	cleared := make([]evo.Genome, len(genomes))
+	pool := make(elcomps, len(genomes))
+	for i, g := range genomes {
+		c := &Cleared{g, g.Fitness()}
+		cleared[i] = c
+		pool[i] = elcomp{c, c.fit}
+	}
+	sort.Sort(pool)
+	pool.clear(radius, κ, func(a, b evo.Genome) float64 {
+		return dist(a.(*Cleared).Genome, b.(*Cleared).Genome)
+	})
+	for i := range pool {
+		pool[i].Genome.(*Cleared).fit = pool[i].fit
+	}
+	return cleared
+}
+
+// clear clears the pool, which must be sorted, and sorts it again by the
+// fitness after clearing.
+func (pool elcomps) clear(radius float64, κ int, dist func(a, b evo.Genome) float64) {
+	cleared := math.Inf(-1)
+	for i := range pool {
+		if pool[i].fit == cleared {
+			continue
+		}
+		winners := 1
+		for j := i + 1; j < len(pool); j++ {
+			if pool[j].fit == cleared || radius <= dist(pool[i].Genome, pool[j].Genome) {
+				continue
+			}
+			if winners < κ {
+				winners++
+			} else {
+				pool[j].fit = cleared
+			}
+		}
+	}
+	sort.Stable(pool)
+}
+
+// ClearingPool creates an elite pool selector with clearing. Once λ
+// competitors have been put into the pool, they are cleared as by Clear, and
+// the best µ competitors after clearing must then be retrieved from the pool.
+// When fewer than µ competitors survive clearing, the remaining winners are
+// cleared competitors. Winners are the original genomes, not wrapped.
+func ClearingPool(µ, λ int, radius float64, κ int, dist func(a, b evo.Genome) float64) Pool {
+	p := newPool(µ, λ, true)
+
+	go func() {
+		// the competitors, memory shared accross iterations
+		pool := make(elcomps, 0, λ)
+
+		for {
+			// wait to receive all competitors
+			for len(pool) < λ {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case val := <-p.in:
+					pool = append(pool, elcomp{val, 0})
+				}
+			}
+
+			pool.sort()
+			pool.clear(radius, κ, dist)
+
+			// send out the most fit µ genomes
+			pool = pool[:µ]
+			for i := 0; i < len(pool); {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case p.out <- pool[i].Genome:
+					i++
+				}
+			}
+			pool = pool[:0]
+		}
+	}()
+
+	return p
+}
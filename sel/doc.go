@@ -7,4 +7,7 @@
 // Pool selectors allow many goroutines to contribute competitors and for the
 // winners to be retrieved individually. Once all the winners are retrieved,
 // the pool is reset for another round of competition.
+//
+// Pre-selection transforms, like Clear, replace the fitness of the competitors
+// before they are given to a selector.
 package sel
@@ -54,10 +54,7 @@ func Elite(µ int, genomes ...evo.Genome) (winners []evo.Genome) {
 // be retrieved from the pool. Once the winners are retrieved, the pool starts
 // accepting competitors for another tournamnent.
 func ElitePool(µ, λ int) Pool {
-	var p Pool
-	p.in = make(chan evo.Genome)
-	p.out = make(chan evo.Genome, µ)
-	p.close = make(chan chan struct{})
+	p := newPool(µ, λ, true)
 
 	go func() {
 		// the competitors, memory shared accross iterations
@@ -71,6 +68,8 @@ func ElitePool(µ, λ int) Pool {
 					ch <- struct{}{}
 					return
 
+				case λ = <-p.lambda:
+
 				case val := <-p.in:
 					// we only add the competitor to the pool
 					// we do _not_ compute the fitness yet
@@ -84,13 +83,16 @@ func ElitePool(µ, λ int) Pool {
 
 			// send out the most fit µ genomes
 			pool = pool[:µ]
-			for i := range pool {
+			for i := 0; i < len(pool); {
 				select {
 				case ch := <-p.close:
 					ch <- struct{}{}
 					return
 
+				case λ = <-p.lambda:
+
 				case p.out <- pool[i].Genome:
+					i++
 				}
 			}
 			pool = pool[:0]
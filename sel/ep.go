@@ -0,0 +1,102 @@
+package sel
+
+import (
+	"math/rand"
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// qtourney scores each competitor against q random opponents, as in
+// evolutionary programming. A competitor wins when it is at least as fit as
+// its opponent. The pool becomes sorted by score.
+func (pool rrcomps) qtourney(q int) {
+	fits := make([]float64, len(pool))
+	done := make(chan struct{})
+	for i := range pool {
+		go func(i int) {
+			fits[i] = pool[i].Fitness()
+			done <- struct{}{}
+		}(i)
+	}
+	for range pool {
+		<-done
+	}
+	for i := range pool {
+		pool[i].wins = 0
+		for j := 0; j < q; j++ {
+			if fits[i] >= fits[rand.Intn(len(pool))] {
+				pool[i].wins++
+			}
+		}
+	}
+	sort.Sort(pool)
+}
+
+// EP returns the µ genomes with the most wins after each competes against q
+// random opponents. This is the stochastic selection of evolutionary
+// programming. Unlike RoundRobin, opponents are chosen independently for each
+// competitor, and larger q gives stronger selection pressure.
+func EP(µ, q int, genomes ...evo.Genome) (winners []evo.Genome) {
+	pool := make(rrcomps, len(genomes))
+	for i := range genomes {
+		pool[i] = rrcomp{genomes[i], 0}
+	}
+	pool.qtourney(q)
+	winners = make([]evo.Genome, µ)
+	for i := range winners {
+		winners[i] = pool[i].Genome
+	}
+	return winners
+}
+
+// EPPool creates an evolutionary programming pool selector. Once λ competitors
+// have been put into the pool, each competes against q random opponents. The µ
+// competitors with the most wins must then be retrieved from the pool. Once
+// the winners are retrieved, the pool starts accepting competitors for another
+// tournament.
+func EPPool(µ, λ, q int) Pool {
+	p := newPool(µ, λ, true)
+
+	go func() {
+		// the competitors, memory shared accross iterations
+		pool := make(rrcomps, 0, λ)
+
+		for {
+			// wait to receive all competitors
+			for len(pool) < λ {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case val := <-p.in:
+					pool = append(pool, rrcomp{val, 0})
+				}
+			}
+
+			// do the tournament
+			pool.qtourney(q)
+
+			// send out the µ genomes that won the most
+			pool = pool[:µ]
+			for i := 0; i < len(pool); {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case p.out <- pool[i].Genome:
+					i++
+				}
+			}
+			pool = pool[:0]
+		}
+	}()
+
+	return p
+}
@@ -1,6 +1,13 @@
 package sel
 
 import (
+	"context"
+	"fmt"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
 	"github.com/cbarrick/evo"
 )
 
@@ -8,27 +15,220 @@ import (
 // process. Pool selectors reset after each competition and must be closed when
 // they are no longer needed.
 type Pool struct {
-	in    chan evo.Genome
-	out   chan evo.Genome
-	close chan chan struct{}
+	in     chan evo.Genome
+	out    chan evo.Genome
+	lambda chan int
+	close  chan chan struct{}
+	state  *poolState
+}
+
+// newPool allocates the channels of a pool selector for µ winners of λ
+// competitors. Winners are buffered if buffered is true.
+func newPool(µ, λ int, buffered bool) (p Pool) {
+	p.in = make(chan evo.Genome)
+	if buffered {
+		p.out = make(chan evo.Genome, µ)
+	} else {
+		p.out = make(chan evo.Genome)
+	}
+	p.lambda = make(chan int)
+	p.close = make(chan chan struct{})
+	p.state = &poolState{mu: µ, lambda: λ, timeout: DefaultWatchdog}
+	return p
 }
 
 // Put adds a competitor to the pool.
 // Put blocks until all winners of the previous competition have been retrieved.
 func (p Pool) Put(val evo.Genome) {
-	p.in <- val
+	p.PutCtx(context.Background(), val)
 }
 
 // Get retrieves a winner from the most current competition.
 // Get blocks until all competitors have been added.
 func (p Pool) Get() (val evo.Genome) {
-	val = <-p.out
+	val, _ = p.GetCtx(context.Background())
 	return val
 }
 
+// PutCtx is like Put, but gives up when the context is done. It returns the
+// error of the context if the competitor was not added. A deadline guards
+// against deadlocks when a caller fails to contribute its competitors.
+func (p Pool) PutCtx(ctx context.Context, val evo.Genome) error {
+	defer p.state.watch("Put")()
+	select {
+	case p.in <- val:
+		p.state.put()
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// GetCtx is like Get, but gives up when the context is done. It returns the
+// error of the context if no winner was retrieved.
+func (p Pool) GetCtx(ctx context.Context) (val evo.Genome, err error) {
+	defer p.state.watch("Get")()
+	select {
+	case val = <-p.out:
+		p.state.get()
+		return val, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// DefaultWatchdog is the timeout of the watchdog of new pools. By default, a
+// call which blocks for longer panics with a *MisuseError, since a pool which
+// is misused deadlocks silently otherwise.
+const DefaultWatchdog = time.Minute
+
+// SetWatchdog configures the deadlock watchdog. When a call to Put or Get
+// blocks for longer than the timeout, the watchdog reports a *MisuseError
+// diagnosing the likely misuse of the pool, e.g. more than λ competitors put
+// before the winners were drained, or more than µ winners retrieved from a
+// competition. If report is nil, the watchdog panics with the error instead.
+// The blocked call keeps waiting after the report. The watchdog is enabled by
+// default with the DefaultWatchdog timeout; a timeout of 0 disables it, e.g.
+// when competitors may legitimately take longer to arrive. SetWatchdog must be
+// called before the pool is used.
+func (p Pool) SetWatchdog(timeout time.Duration, report func(error)) {
+	p.state.Lock()
+	defer p.state.Unlock()
+	p.state.timeout = timeout
+	p.state.report = report
+}
+
+// SetLambda changes the number of competitors per competition. If the current
+// competition already has at least λ competitors, it starts immediately.
+// Otherwise the change takes effect for the current competition. When called
+// while winners are waiting to be retrieved, the change takes effect for the
+// next competition. λ must be at least the number of winners.
+func (p Pool) SetLambda(λ int) {
+	p.lambda <- λ
+	p.state.setLambda(λ)
+}
+
 // Close stops the pool selector.
 func (p Pool) Close() {
 	ch := make(chan struct{})
 	p.close <- ch
 	<-ch
 }
+
+// poolState tracks the use of a pool to diagnose misuse. The counts mirror the
+// progress of the pool goroutine as seen by callers.
+type poolState struct {
+	sync.Mutex
+	mu, lambda int // the number of winners and competitors
+	collected  int // competitors put into the current competition
+	pending    int // winners of the last competition not yet retrieved
+
+	timeout time.Duration
+	report  func(error)
+}
+
+func (s *poolState) put() {
+	s.Lock()
+	defer s.Unlock()
+	s.collected++
+	if s.lambda <= s.collected && s.pending == 0 {
+		s.collected = 0
+		s.pending = s.mu
+	}
+}
+
+func (s *poolState) get() {
+	s.Lock()
+	defer s.Unlock()
+	if 0 < s.pending {
+		s.pending--
+	}
+	if s.pending == 0 && s.lambda <= s.collected {
+		s.collected = 0
+		s.pending = s.mu
+	}
+}
+
+func (s *poolState) setLambda(λ int) {
+	s.Lock()
+	defer s.Unlock()
+	s.lambda = λ
+	if s.pending == 0 && λ <= s.collected {
+		s.collected = 0
+		s.pending = s.mu
+	}
+}
+
+// watch starts the watchdog for a call to op, if enabled. The returned function
+// stops the watchdog and must be called when the call returns.
+func (s *poolState) watch(op string) (stop func()) {
+	s.Lock()
+	timeout, report := s.timeout, s.report
+	s.Unlock()
+	if timeout <= 0 {
+		return func() {}
+	}
+	t := time.AfterFunc(timeout, func() {
+		s.Lock()
+		err := &MisuseError{
+			Op:        op,
+			Waited:    timeout,
+			Mu:        s.mu,
+			Lambda:    s.lambda,
+			Collected: s.collected,
+			Pending:   s.pending,
+			Stacks:    stuck(),
+		}
+		s.Unlock()
+		if report == nil {
+			panic(err)
+		}
+		report(err)
+	})
+	return func() { t.Stop() }
+}
+
+// stuck returns the stacks of the goroutines blocked in pool methods.
+func stuck() string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+	var stacks []string
+	for _, g := range strings.Split(string(buf), "\n\n") {
+		if strings.Contains(g, "sel.Pool.PutCtx") || strings.Contains(g, "sel.Pool.GetCtx") {
+			stacks = append(stacks, g)
+		}
+	}
+	return strings.Join(stacks, "\n\n")
+}
+
+// A MisuseError reports a call to a pool selector which blocked for longer than
+// the timeout of the watchdog.
+type MisuseError struct {
+	Op         string        // "Put" or "Get"
+	Waited     time.Duration // how long the call blocked
+	Mu, Lambda int           // the number of winners and competitors
+	Collected  int           // competitors put into the current competition
+	Pending    int           // winners not yet retrieved
+	Stacks     string        // stacks of the goroutines blocked in the pool
+}
+
+// Error describes the likely misuse.
+func (e *MisuseError) Error() string {
+	var why string
+	switch {
+	case e.Op == "Put" && 0 < e.Pending:
+		why = fmt.Sprintf("%d winners of the last competition were not retrieved; more than λ=%d competitors may have been put before the winners were drained", e.Pending, e.Lambda)
+	case e.Op == "Get" && e.Pending == 0:
+		why = fmt.Sprintf("only %d of λ=%d competitors were put; more than µ=%d winners may have been retrieved from a competition", e.Collected, e.Lambda, e.Mu)
+	default:
+		why = fmt.Sprintf("%d of λ=%d competitors put, %d of µ=%d winners pending", e.Collected, e.Lambda, e.Pending, e.Mu)
+	}
+	return fmt.Sprintf("sel: %s blocked for %v: %s", e.Op, e.Waited, why)
+}
@@ -0,0 +1,103 @@
+package sel
+
+import (
+	"container/heap"
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Reservoir selects µ winners from a stream of competitors of unknown length.
+// Each competitor is kept with probability proportional to its weight, by the
+// weighted reservoir sampling of Efraimidis and Spirakis: a competitor of
+// weight w draws the key u^(1/w) for a uniform u, and the µ largest keys win.
+//
+// Unlike a pool selector, a reservoir never blocks: Put returns at once, and
+// Take returns the winners of however many competitors were put since the last
+// Take. This suits asynchronous architectures where the number of offspring
+// per round is not fixed like the λ of a pool. Reservoirs are safe for
+// concurrent use; the fitness of a competitor is evaluated by the goroutine
+// which puts it.
+type Reservoir struct {
+	µ      int
+	weight func(fitness float64) float64
+
+	mu   sync.Mutex
+	keys reservoirHeap
+	seen int
+}
+
+// NewReservoir returns a reservoir of µ winners. The weight of a competitor is
+// the weight function of its fitness, e.g. math.Exp for Boltzmann selection, or
+// the fitness itself if the function is nil. Competitors with a weight of 0 or
+// less only win when fewer than µ competitors are put.
+func NewReservoir(µ int, weight func(fitness float64) float64) *Reservoir {
+	return &Reservoir{µ: µ, weight: weight}
+}
+
+// Put adds a competitor to the reservoir.
+func (r *Reservoir) Put(val evo.Genome) {
+	w := val.Fitness()
+	if r.weight != nil {
+		w = r.weight(w)
+	}
+	key := math.Inf(-1)
+	if 0 < w {
+		// compare log(u)/w rather than u^(1/w) to avoid underflow
+		key = math.Log(rand.Float64()) / w
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.seen++
+	if len(r.keys) < r.µ {
+		heap.Push(&r.keys, reservoirItem{val, key})
+	} else if 0 < len(r.keys) && r.keys[0].key < key {
+		r.keys[0] = reservoirItem{val, key}
+		heap.Fix(&r.keys, 0)
+	}
+}
+
+// Seen returns the number of competitors put since the last Take.
+func (r *Reservoir) Seen() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.seen
+}
+
+// Take returns the winners among the competitors put since the last Take, and
+// empties the reservoir. There are fewer than µ winners if fewer competitors
+// were put.
+func (r *Reservoir) Take() (winners []evo.Genome) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	winners = make([]evo.Genome, len(r.keys))
+	for i := range r.keys {
+		winners[i] = r.keys[i].Genome
+	}
+	r.keys = r.keys[:0]
+	r.seen = 0
+	return winners
+}
+
+// A reservoirItem is a competitor with its sampling key.
+type reservoirItem struct {
+	evo.Genome
+	key float64
+}
+
+// reservoirHeap implements heap.Interface, smallest key first.
+type reservoirHeap []reservoirItem
+
+func (h reservoirHeap) Len() int            { return len(h) }
+func (h reservoirHeap) Less(i, j int) bool  { return h[i].key < h[j].key }
+func (h reservoirHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *reservoirHeap) Push(x interface{}) { *h = append(*h, x.(reservoirItem)) }
+func (h *reservoirHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
@@ -98,10 +98,7 @@ func RoundRobin(µ, rounds int, genomes ...evo.Genome) (winners []evo.Genome) {
 // competitors must then be retrieved from the pool. Once the winners are
 // retrieved, the pool starts accepting competitors for another tournamnent.
 func RoundRobinPool(µ, λ, rounds int) Pool {
-	var p Pool
-	p.in = make(chan evo.Genome)
-	p.out = make(chan evo.Genome)
-	p.close = make(chan chan struct{})
+	p := newPool(µ, λ, false)
 
 	go func() {
 		// the competitors, memory shared accross iterations
@@ -115,26 +112,31 @@ func RoundRobinPool(µ, λ, rounds int) Pool {
 					ch <- struct{}{}
 					return
 
+				case λ = <-p.lambda:
+
 				case val := <-p.in:
 					pool = append(pool, rrcomp{val, 0})
 				}
 			}
 
 			// do the tournament
-			if λ%2 != 0 {
+			if len(pool)%2 != 0 {
 				pool = append(pool, rrcomp{dummy{}, -1})
 			}
 			pool.tourney(rounds)
 
 			// send out the µ genomes that won the most
 			pool = pool[:µ]
-			for i := range pool {
+			for i := 0; i < len(pool); {
 				select {
 				case ch := <-p.close:
 					ch <- struct{}{}
 					return
 
+				case λ = <-p.lambda:
+
 				case p.out <- pool[i].Genome:
+					i++
 				}
 			}
 			pool = pool[:0]
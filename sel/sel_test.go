@@ -1,7 +1,10 @@
 package sel_test
 
 import (
+	"context"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/cbarrick/evo"
 	"github.com/cbarrick/evo/sel"
@@ -37,6 +40,139 @@ func search(ds []evo.Genome, d float64) bool {
 	return false
 }
 
+// interface.go
+// -------------------------
+
+func TestPoolCtx(t *testing.T) {
+	pool := sel.ElitePool(1, 2)
+	defer pool.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := pool.PutCtx(ctx, dummy(1)); err != nil {
+		t.Fail()
+	}
+	if _, err := pool.GetCtx(ctx); err != context.DeadlineExceeded {
+		t.Fail()
+	}
+	if err := pool.PutCtx(context.Background(), dummy(2)); err != nil {
+		t.Fail()
+	}
+	if val, err := pool.GetCtx(context.Background()); err != nil || val != dummy(2) {
+		t.Fail()
+	}
+}
+
+func TestSetLambda(t *testing.T) {
+	pool := sel.ElitePool(2, 4)
+	defer pool.Close()
+	pool.Put(dummy(1))
+	pool.Put(dummy(3))
+	pool.Put(dummy(2))
+	pool.SetLambda(3)
+	if pool.Get() != dummy(3) || pool.Get() != dummy(2) {
+		t.Fail()
+	}
+	pool.SetLambda(2)
+	pool.Put(dummy(5))
+	pool.Put(dummy(4))
+	if pool.Get() != dummy(5) || pool.Get() != dummy(4) {
+		t.Fail()
+	}
+}
+
+func TestWatchdog(t *testing.T) {
+	pool := sel.ElitePool(1, 2)
+	defer pool.Close()
+	errs := make(chan error, 1)
+	pool.SetWatchdog(10*time.Millisecond, func(err error) { errs <- err })
+	pool.Put(dummy(1))
+	pool.Put(dummy(2))
+	pool.Get()
+
+	// a second winner is retrieved from a competition of one winner
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go pool.GetCtx(ctx)
+	select {
+	case err := <-errs:
+		misuse, ok := err.(*sel.MisuseError)
+		if !ok || misuse.Op != "Get" || misuse.Pending != 0 {
+			t.Error(err)
+		}
+		if !strings.Contains(misuse.Stacks, "GetCtx") {
+			t.Error("missing stack of the blocked goroutine")
+		}
+	case <-ctx.Done():
+		t.Error("watchdog did not report")
+	}
+}
+
+func TestWatchdogPut(t *testing.T) {
+	pool := sel.RoundRobinPool(1, 2, 1)
+	defer pool.Close()
+	errs := make(chan error, 1)
+	pool.SetWatchdog(10*time.Millisecond, func(err error) { errs <- err })
+	pool.Put(dummy(1))
+	pool.Put(dummy(2))
+
+	// a competitor is put before the winner is drained
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	go pool.PutCtx(ctx, dummy(3))
+	select {
+	case err := <-errs:
+		misuse, ok := err.(*sel.MisuseError)
+		if !ok || misuse.Op != "Put" || misuse.Pending != 1 {
+			t.Error(err)
+		}
+	case <-ctx.Done():
+		t.Error("watchdog did not report")
+	}
+	pool.Get()
+}
+
+func TestWatchdogDisabled(t *testing.T) {
+	pool := sel.ElitePool(1, 2)
+	defer pool.Close()
+	errs := make(chan error, 1)
+	pool.SetWatchdog(0, func(err error) { errs <- err })
+
+	// a Get which blocks is not reported
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.GetCtx(ctx); err != context.DeadlineExceeded {
+		t.Error("Get did not block:", err)
+	}
+	select {
+	case err := <-errs:
+		t.Error("disabled watchdog reported:", err)
+	default:
+	}
+}
+
+// ep.go
+// -------------------------
+
+func TestEP(t *testing.T) {
+	pop := dummies()
+	elite := sel.EP(1, 100, pop...)
+	if elite[0] != dummy(9) {
+		t.Fail()
+	}
+}
+
+func TestEPPool(t *testing.T) {
+	pop := dummies()
+	pool := sel.EPPool(1, 10, 100)
+	defer pool.Close()
+	for i := range pop {
+		pool.Put(pop[i])
+	}
+	if pool.Get() != dummy(9) {
+		t.Fail()
+	}
+}
+
 // elite.go
 // -------------------------
 
@@ -119,3 +255,124 @@ func TestBinaryTournament(t *testing.T) {
 		t.Fail()
 	}
 }
+
+func TestTournamentPool(t *testing.T) {
+	pop := dummies()
+	pool := sel.TournamentPool(5, 10, 10)
+	defer pool.Close()
+	for i := range pop {
+		pool.Put(pop[i])
+	}
+	for i := 0; i < 5; i++ {
+		if !search(pop, float64(pool.Get().(dummy))) {
+			t.Fail()
+		}
+	}
+}
+
+// afpo.go
+// -------------------------
+
+// aged is a genome with an age.
+type aged struct {
+	fit float64
+	age int
+}
+
+func (a aged) Fitness() float64 { return a.fit }
+func (a aged) Age() int         { return a.age }
+
+func TestAFPO(t *testing.T) {
+	genomes := []evo.Genome{
+		aged{fit: 9, age: 9}, // front 0, the fittest
+		aged{fit: 5, age: 1}, // front 0, young and fairly fit
+		aged{fit: 4, age: 5}, // dominated by {5, 1}
+		aged{fit: 1, age: 0}, // front 0, the youngest
+		aged{fit: 8, age: 9}, // dominated by {9, 9}
+	}
+	newcomer := aged{fit: 0, age: 0}
+	random := func() evo.Genome { return newcomer }
+
+	winners := sel.AFPO(4, 1, random, genomes...)
+	want := []evo.Genome{genomes[0], genomes[1], genomes[3], newcomer}
+	for i := range want {
+		if winners[i] != want[i] {
+			t.Fatal(winners)
+		}
+	}
+
+	pool := sel.AFPOPool(2, 5, 0, random)
+	defer pool.Close()
+	for i := range genomes {
+		pool.Put(genomes[i])
+	}
+	if pool.Get() != genomes[0] || pool.Get() != genomes[1] {
+		t.Fail()
+	}
+}
+
+// clearing.go
+// -------------------------
+
+func TestClear(t *testing.T) {
+	dist := func(a, b evo.Genome) float64 {
+		d := a.Fitness() - b.Fitness()
+		if d < 0 {
+			return -d
+		}
+		return d
+	}
+
+	// niches around 9 and 4, keeping the best 2 of each
+	genomes := []evo.Genome{dummy(9), dummy(8.5), dummy(8), dummy(4), dummy(3.5), dummy(3)}
+	cleared := sel.Clear(1.5, 2, dist, genomes...)
+	for i, g := range cleared {
+		c := g.(*sel.Cleared)
+		if c.Genome != genomes[i] {
+			t.Fatal("wrong order")
+		}
+		if keep := i != 2 && i != 5; keep != (c.Fitness() == genomes[i].Fitness()) {
+			t.Error("wrong clearing", i, c.Fitness())
+		}
+	}
+
+	pool := sel.ClearingPool(4, 6, 1.5, 1, dist)
+	defer pool.Close()
+	for i := range genomes {
+		pool.Put(genomes[i])
+	}
+	if pool.Get() != dummy(9) || pool.Get() != dummy(4) {
+		t.Error("dominants not selected first")
+	}
+	pool.Get()
+	pool.Get()
+}
+
+// reservoir.go
+// -------------------------
+
+func TestReservoir(t *testing.T) {
+	r := sel.NewReservoir(3, nil)
+	r.Put(dummy(1))
+	if w := r.Take(); len(w) != 1 || w[0] != dummy(1) {
+		t.Error("underfull reservoir", w)
+	}
+
+	// heavy competitors win far more often than light ones
+	var heavy int
+	for round := 0; round < 100; round++ {
+		for i := 0; i < 20; i++ {
+			r.Put(dummy(1))
+		}
+		r.Put(dummy(1000))
+		if r.Seen() != 21 {
+			t.Fatal("seen", r.Seen())
+		}
+		if search(r.Take(), 1000) {
+			heavy++
+		}
+	}
+	if heavy < 95 {
+		t.Error("heavy competitor won", heavy, "of 100 rounds")
+	}
+}
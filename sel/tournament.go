@@ -39,3 +39,61 @@ func BinaryTournament(suitors ...evo.Genome) evo.Genome {
 	}
 	return suitors[x]
 }
+
+// TournamentPool creates a tournament pool selector. Once λ competitors have
+// been put into the pool, µ tournaments are held, each between k competitors
+// chosen at random. The winners of the tournaments must then be retrieved from
+// the pool. A competitor may win more than one tournament. Once the winners are
+// retrieved, the pool starts accepting competitors for another competition.
+// Smaller k gives softer selection pressure than an elite pool.
+func TournamentPool(µ, λ, k int) Pool {
+	p := newPool(µ, λ, true)
+
+	go func() {
+		// the competitors and winners, memory shared accross iterations
+		pool := make([]evo.Genome, 0, λ)
+		winners := make([]evo.Genome, µ)
+		suitors := make([]evo.Genome, k)
+
+		for {
+			// wait to receive all competitors
+			for len(pool) < λ {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case val := <-p.in:
+					pool = append(pool, val)
+				}
+			}
+
+			// hold the tournaments
+			for i := range winners {
+				for j := range suitors {
+					suitors[j] = pool[rand.Intn(len(pool))]
+				}
+				winners[i] = Tournament(suitors...)
+			}
+
+			// send out the winners
+			for i := 0; i < len(winners); {
+				select {
+				case ch := <-p.close:
+					ch <- struct{}{}
+					return
+
+				case λ = <-p.lambda:
+
+				case p.out <- winners[i]:
+					i++
+				}
+			}
+			pool = pool[:0]
+		}
+	}()
+
+	return p
+}
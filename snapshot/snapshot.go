@@ -0,0 +1,260 @@
+// Package snapshot exports the members of a population to files while it
+// evolves, for post-hoc analysis of population dynamics and for recovering
+// intermediate solutions.
+//
+// A snapshot is a JSON document holding the fitness and serialized form of
+// every member of a population at some moment. Genomes are serialized with
+// encoding/json, so genomes implementing json.Marshaler or
+// encoding.TextMarshaler control their own encoding. Other genomes implementing
+// fmt.Stringer are written as their string; the remaining genomes are encoded
+// from their exported fields.
+//
+// An Exporter writes snapshots periodically:
+//
+//	exp := &snapshot.Exporter{Dir: "snapshots", Period: 10 * time.Second}
+//	exp.Watch(pop)
+//	pop.Wait()
+//	if err := exp.Err(); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// WarmStart seeds a new population from a snapshot, e.g. the last snapshot of
+// a previous run, optionally mixed with fresh random genomes.
+package snapshot
+
+import (
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Snapshot is a serialized copy of the members of a population.
+type Snapshot struct {
+	Seq         int       `json:"seq"`         // the number of snapshots taken before
+	Time        time.Time `json:"time"`        // when the snapshot was taken
+	Generations float64   `json:"generations"` // generations evolved, if known
+	Members     []Member  `json:"members"`
+
+	Info *evo.RunInfo `json:"info,omitempty"` // the run which produced the snapshot
+}
+
+// A Member is a serialized genome. A fitness which is not finite, e.g. the -Inf
+// fitness of an infeasible genome, is written as a string; see evo.Float.
+type Member struct {
+	Fitness float64         `json:"fitness"`
+	Genome  json.RawMessage `json:"genome"`
+}
+
+// member is a Member whose fitness is written as an evo.Float.
+type member struct {
+	Fitness evo.Float       `json:"fitness"`
+	Genome  json.RawMessage `json:"genome"`
+}
+
+// MarshalJSON writes the member, with a non-finite fitness as a string.
+func (m Member) MarshalJSON() ([]byte, error) {
+	return json.Marshal(member{evo.Float(m.Fitness), m.Genome})
+}
+
+// UnmarshalJSON reads a member written by MarshalJSON.
+func (m *Member) UnmarshalJSON(b []byte) error {
+	var rec member
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return err
+	}
+	*m = Member{float64(rec.Fitness), rec.Genome}
+	return nil
+}
+
+// Take serializes the members of a view.
+func Take(v evo.View) (s Snapshot, err error) {
+	s.Time = time.Now()
+	s.Members = make([]Member, v.Len())
+	for i, g := range v.Members() {
+		s.Members[i].Fitness = g.Fitness()
+		s.Members[i].Genome, err = marshal(g)
+		if err != nil {
+			return s, fmt.Errorf("snapshot: member %d: %v", i, err)
+		}
+	}
+	return s, nil
+}
+
+// marshal serializes a genome.
+func marshal(g evo.Genome) ([]byte, error) {
+	switch g := g.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return json.Marshal(g)
+	case fmt.Stringer:
+		return json.Marshal(g.String())
+	}
+	return json.Marshal(g)
+}
+
+// Save writes a snapshot to a file. The file is replaced atomically, so readers
+// never observe a partial snapshot.
+func Save(path string, s Snapshot) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// Load reads a snapshot from a file. The genomes are left serialized; callers
+// decode them into their own genome type.
+func Load(path string) (s Snapshot, err error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return s, err
+	}
+	err = json.Unmarshal(b, &s)
+	if err != nil {
+		err = fmt.Errorf("snapshot: %s: %v", path, err)
+	}
+	return s, err
+}
+
+// An Exporter periodically writes snapshots of a population to a directory.
+// Snapshots are written to files named <prefix>-<seq>.json, where seq counts
+// from 0. The zero value of each field takes a default.
+type Exporter struct {
+	Dir    string        // the directory of the files, default "."
+	Prefix string        // the prefix of the file names, default "snapshot"
+	Period time.Duration // the time between snapshots
+
+	// The number of generations between snapshots. Generations are estimated
+	// from the throughput of the population as the number of calls to the
+	// EvolveFn per member, so the population must provide a Throughput
+	// method, as do generational and graph populations. When both Period and
+	// Generations are set, a snapshot is taken when either has elapsed.
+	Generations int
+
+	// If non-nil, Info is attached to every snapshot.
+	Info *evo.RunInfo
+
+	mu   sync.Mutex
+	seq  int
+	err  error
+	last struct {
+		time time.Time
+		gens float64
+	}
+}
+
+// throughputer is a population which measures its throughput.
+type throughputer interface {
+	Throughput() evo.Throughput
+}
+
+// Watch writes snapshots of the population for the duration of the current
+// optimization. A final snapshot is not taken automatically; call Snapshot
+// after the population stops to record the final state. Watch panics if
+// neither Period nor Generations is set.
+func (e *Exporter) Watch(pop evo.Population) {
+	if e.Period <= 0 && e.Generations <= 0 {
+		panic("no snapshot period")
+	}
+	freq := e.Period
+	if e.Generations > 0 {
+		// poll quickly enough to notice each generation
+		freq = 10 * time.Millisecond
+	}
+	e.mu.Lock()
+	e.last.time = time.Now()
+	e.mu.Unlock()
+	pop.Poll(freq, func() bool {
+		if e.due(pop) {
+			e.Snapshot(pop)
+		}
+		return false
+	})
+}
+
+// due reports whether a snapshot is due.
+func (e *Exporter) due(pop evo.Population) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if e.Period > 0 && e.Period <= time.Since(e.last.time) {
+		return true
+	}
+	if e.Generations > 0 {
+		return float64(e.Generations) <= generations(pop)-e.last.gens
+	}
+	return false
+}
+
+// generations estimates the generations evolved by a population.
+func generations(pop evo.Population) float64 {
+	t, ok := pop.(throughputer)
+	if !ok {
+		return 0
+	}
+	v := pop.View()
+	n := v.Len()
+	v.Close()
+	if n == 0 {
+		return 0
+	}
+	return float64(t.Throughput().Iterations) / float64(n)
+}
+
+// Snapshot immediately writes a snapshot of the population. Errors are
+// recorded and returned by Err.
+func (e *Exporter) Snapshot(pop evo.Population) error {
+	gens := generations(pop)
+	v := pop.View()
+	s, err := Take(v)
+	v.Close()
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	if err == nil {
+		dir, prefix := e.Dir, e.Prefix
+		if dir == "" {
+			dir = "."
+		}
+		if prefix == "" {
+			prefix = "snapshot"
+		}
+		s.Seq = e.seq
+		s.Generations = gens
+		s.Info = e.Info
+		name := fmt.Sprintf("%s-%06d.json", prefix, e.seq)
+		err = Save(filepath.Join(dir, name), s)
+	}
+	e.last.time = time.Now()
+	e.last.gens = gens
+	if err != nil {
+		if e.err == nil {
+			e.err = err
+		}
+		return err
+	}
+	e.seq++
+	return nil
+}
+
+// Count returns the number of snapshots written.
+func (e *Exporter) Count() int {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.seq
+}
+
+// Err returns the first error encountered while writing snapshots, if any.
+func (e *Exporter) Err() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.err
+}
@@ -0,0 +1,173 @@
+package snapshot_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/snapshot"
+)
+
+type counter struct {
+	evo.Cache
+	n int
+}
+
+func (c *counter) Fitness() float64 {
+	return c.Cache.Fitness(func() float64 { return float64(c.n) })
+}
+
+func (c *counter) String() string {
+	return fmt.Sprint(c.n)
+}
+
+type point struct {
+	X, Y float64
+}
+
+func (p point) Fitness() float64 {
+	return p.X + p.Y
+}
+
+// infeasible is a genome violating its constraints.
+type infeasible struct{}
+
+func (infeasible) Fitness() float64 { return math.Inf(-1) }
+
+func (infeasible) String() string { return "infeasible" }
+
+func increment(current evo.Genome, _ []evo.Genome) evo.Genome {
+	time.Sleep(time.Millisecond)
+	return &counter{n: current.(*counter).n + 1}
+}
+
+func TestTake(t *testing.T) {
+	s, err := snapshot.Take(evo.NewView([]evo.Genome{&counter{n: 3}, point{1, 2}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(s.Members) != 2 || s.Members[0].Fitness != 3 || s.Members[1].Fitness != 3 {
+		t.Fatal("wrong members")
+	}
+	if string(s.Members[0].Genome) != `"3"` {
+		t.Errorf("stringer encoded as %s", s.Members[0].Genome)
+	}
+	var p point
+	if err := json.Unmarshal(s.Members[1].Genome, &p); err != nil || p != (point{1, 2}) {
+		t.Errorf("struct encoded as %s", s.Members[1].Genome)
+	}
+}
+
+func TestSaveNonFinite(t *testing.T) {
+	s, err := snapshot.Take(evo.NewView([]evo.Genome{point{1, 2}, infeasible{}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "snapshot.json")
+	if err := snapshot.Save(path, s); err != nil {
+		t.Fatal(err)
+	}
+	s, err = snapshot.Load(path)
+	if err != nil || len(s.Members) != 2 || s.Members[0].Fitness != 3 || !math.IsInf(s.Members[1].Fitness, -1) {
+		t.Fatal(s, err)
+	}
+}
+
+func TestExporter(t *testing.T) {
+	for _, exp := range []*snapshot.Exporter{
+		{Dir: t.TempDir(), Period: 5 * time.Millisecond},
+		{Dir: t.TempDir(), Prefix: "gens", Generations: 2},
+	} {
+		seed := make([]evo.Genome, 4)
+		for i := range seed {
+			seed[i] = &counter{}
+		}
+		pop := new(gen.Population)
+		pop.Evolve(seed, increment)
+		exp.Watch(pop)
+		time.Sleep(50 * time.Millisecond)
+		pop.Stop()
+		if err := exp.Snapshot(pop); err != nil {
+			t.Fatal(err)
+		}
+		if exp.Err() != nil {
+			t.Fatal(exp.Err())
+		}
+
+		n := exp.Count()
+		if n < 2 {
+			t.Fatalf("only %d snapshots", n)
+		}
+		prefix := exp.Prefix
+		if prefix == "" {
+			prefix = "snapshot"
+		}
+		first, err := snapshot.Load(filepath.Join(exp.Dir, fmt.Sprintf("%s-%06d.json", prefix, 0)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		last, err := snapshot.Load(filepath.Join(exp.Dir, fmt.Sprintf("%s-%06d.json", prefix, n-1)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(last.Members) != 4 || last.Seq != n-1 {
+			t.Error("wrong snapshot")
+		}
+		if last.Members[0].Fitness <= first.Members[0].Fitness || last.Generations <= first.Generations {
+			t.Error("population did not progress between snapshots")
+		}
+	}
+}
+
+func TestExporterError(t *testing.T) {
+	exp := &snapshot.Exporter{Dir: filepath.Join(t.TempDir(), "missing"), Period: time.Second}
+	pop := new(gen.Population)
+	pop.Evolve([]evo.Genome{&counter{}, &counter{}}, increment)
+	pop.Stop()
+	if exp.Snapshot(pop) == nil || exp.Err() == nil || exp.Count() != 0 {
+		t.Fail()
+	}
+}
+
+// warm.go
+// -------------------------
+
+func TestWarmStart(t *testing.T) {
+	s, err := snapshot.Take(evo.NewView([]evo.Genome{point{1, 0}, point{3, 0}, point{2, 0}}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	decode := func(data json.RawMessage) (evo.Genome, error) {
+		var p point
+		err := json.Unmarshal(data, &p)
+		return p, err
+	}
+	random := func() evo.Genome { return point{-1, 0} }
+
+	seed, err := snapshot.WarmStart(s, 4, 0.5, decode, random)
+	want := []float64{3, 2, -1, -1}
+	if err != nil || len(seed) != len(want) {
+		t.Fatal(seed, err)
+	}
+	for i := range want {
+		if seed[i].Fitness() != want[i] {
+			t.Fatal("wrong seed:", seed)
+		}
+	}
+
+	// the shortfall of a small snapshot is fresh
+	seed, err = snapshot.WarmStart(s, 5, 0, decode, random)
+	if err != nil || seed[2].Fitness() != 1 || seed[3].Fitness() != -1 || seed[4].Fitness() != -1 {
+		t.Fatal(seed, err)
+	}
+
+	s.Members[0].Genome = json.RawMessage(`"bad"`)
+	if _, err := snapshot.WarmStart(s, 3, 0, decode, nil); err == nil {
+		t.Error("invalid member decoded")
+	}
+}
@@ -0,0 +1,52 @@
+package snapshot
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Decoder deserializes a genome of a snapshot, the inverse of the encoding
+// described in the package documentation.
+type Decoder func(data json.RawMessage) (evo.Genome, error)
+
+// WarmStart returns n genomes to initialize a new population from the members
+// of a snapshot, e.g. of a previous run, so that each run of an iterative
+// workflow continues from the best results of the last. The given fraction of
+// the genomes, rounded to the nearest integer, are fresh genomes from the
+// random function, which restores the diversity lost by the previous run; the
+// others are the best members of the snapshot by recorded fitness, decoded in
+// order. If the snapshot has too few members, the shortfall is fresh as well.
+// The random function may be nil if no fresh genome is needed.
+//
+//	snap, err := snapshot.Load("snapshots/snapshot-000042.json")
+//	seed, err := snapshot.WarmStart(snap, 100, 0.2, decode, random)
+//	pop.Evolve(seed, body)
+func WarmStart(s Snapshot, n int, fresh float64, decode Decoder, random func() evo.Genome) ([]evo.Genome, error) {
+	if fresh < 0 || 1 < fresh || math.IsNaN(fresh) {
+		panic(fmt.Sprintf("snapshot: fresh ratio %v not in [0,1]", fresh))
+	}
+	members := make([]Member, len(s.Members))
+	copy(members, s.Members)
+	sort.SliceStable(members, func(i, j int) bool {
+		return members[i].Fitness > members[j].Fitness
+	})
+	k := min(n-int(math.Round(fresh*float64(n))), len(members))
+
+	seed := make([]evo.Genome, n)
+	for i := range seed {
+		if i < k {
+			g, err := decode(members[i].Genome)
+			if err != nil {
+				return nil, fmt.Errorf("snapshot: member %d: %v", i, err)
+			}
+			seed[i] = g
+		} else {
+			seed[i] = random()
+		}
+	}
+	return seed, nil
+}
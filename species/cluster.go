@@ -0,0 +1,226 @@
+package species
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Cluster is a group of similar members of a population, as found by KMeans
+// or Medoids. Clusters are diagnostics of niching: the number and sizes of the
+// clusters show how many optima a population is tracking, and the best member
+// of each cluster extracts one solution per optimum.
+type Cluster struct {
+	Members  []evo.Genome
+	Best     evo.Genome // the most fit member
+	Centroid []float64  // the mean of the members, by KMeans only
+	Medoid   evo.Genome // the member closest to the others, by Medoids only
+}
+
+// Size returns the number of members of the cluster.
+func (c Cluster) Size() int {
+	return len(c.Members)
+}
+
+// KMeans partitions the members into at most k clusters of nearby vectors with
+// Lloyd's algorithm, starting from centroids chosen by k-means++. The vector
+// of each genome is given by vec, e.g. the real.Vector underlying it. Empty
+// clusters are dropped, and the clusters are returned from the best to the
+// worst by the fitness of their best member.
+func KMeans(members []evo.Genome, k int, vec func(evo.Genome) []float64) []Cluster {
+	if len(members) == 0 || k <= 0 {
+		return nil
+	}
+	points := make([][]float64, len(members))
+	for i, g := range members {
+		points[i] = vec(g)
+	}
+	sqdist := func(x, y []float64) float64 {
+		var d float64
+		for i := range x {
+			d += (x[i] - y[i]) * (x[i] - y[i])
+		}
+		return d
+	}
+
+	seeds := seed(len(members), k, func(i, j int) float64 {
+		return sqdist(points[i], points[j])
+	})
+	centroids := make([][]float64, len(seeds))
+	for c, i := range seeds {
+		centroids[c] = append([]float64(nil), points[i]...)
+	}
+
+	assign := make([]int, len(members))
+	for iter := 0; iter < 100; iter++ {
+		changed := iter == 0
+		for i, p := range points {
+			best, min := 0, math.Inf(1)
+			for c := range centroids {
+				if d := sqdist(p, centroids[c]); d < min {
+					best, min = c, d
+				}
+			}
+			changed = changed || assign[i] != best
+			assign[i] = best
+		}
+		if !changed {
+			break
+		}
+
+		// move each centroid to the mean of its members
+		counts := make([]float64, len(centroids))
+		sums := make([][]float64, len(centroids))
+		for c := range sums {
+			sums[c] = make([]float64, len(centroids[c]))
+		}
+		for i, p := range points {
+			c := assign[i]
+			counts[c]++
+			for j := range p {
+				sums[c][j] += p[j]
+			}
+		}
+		for c := range centroids {
+			if counts[c] == 0 {
+				continue
+			}
+			for j := range sums[c] {
+				centroids[c][j] = sums[c][j] / counts[c]
+			}
+		}
+	}
+
+	clusters := group(members, assign, len(centroids))
+	for c := range clusters {
+		clusters[c].Centroid = centroids[c]
+	}
+	return sorted(clusters)
+}
+
+// Medoids partitions the members into at most k clusters under a distance
+// function, for representations without a mean, e.g. permutations or trees.
+// Each cluster is represented by its medoid, the member with the least total
+// distance to the other members of the cluster. The medoids start from
+// members chosen like k-means++, and alternate with the assignment of members
+// to their nearest medoid until the clusters are stable. The clusters are
+// returned from the best to the worst by the fitness of their best member. The
+// cost is quadratic in the size of the largest cluster per round.
+func Medoids(members []evo.Genome, k int, dist Distance) []Cluster {
+	if len(members) == 0 || k <= 0 {
+		return nil
+	}
+	medoids := seed(len(members), k, func(i, j int) float64 {
+		d := dist(members[i], members[j])
+		return d * d
+	})
+
+	assign := make([]int, len(members))
+	for iter := 0; iter < 100; iter++ {
+		for i, g := range members {
+			best, min := 0, math.Inf(1)
+			for c, m := range medoids {
+				if d := dist(g, members[m]); d < min {
+					best, min = c, d
+				}
+			}
+			assign[i] = best
+		}
+
+		changed := false
+		for c := range medoids {
+			best, min := medoids[c], math.Inf(1)
+			for i := range members {
+				if assign[i] != c {
+					continue
+				}
+				var total float64
+				for j := range members {
+					if assign[j] == c {
+						total += dist(members[i], members[j])
+					}
+				}
+				if total < min {
+					best, min = i, total
+				}
+			}
+			changed = changed || best != medoids[c]
+			medoids[c] = best
+		}
+		if !changed {
+			break
+		}
+	}
+
+	clusters := group(members, assign, len(medoids))
+	for c := range clusters {
+		clusters[c].Medoid = members[medoids[c]]
+	}
+	return sorted(clusters)
+}
+
+// seed chooses up to k distinct indices of n points, the first at random and
+// each next with probability proportional to the least squared distance sqdist
+// to the chosen points.
+func seed(n, k int, sqdist func(i, j int) float64) []int {
+	chosen := []int{rand.Intn(n)}
+	min := make([]float64, n)
+	for i := range min {
+		min[i] = sqdist(i, chosen[0])
+	}
+	for len(chosen) < k {
+		var total float64
+		for _, d := range min {
+			total += d
+		}
+		if total == 0 {
+			break // every point coincides with a chosen one
+		}
+		x := rand.Float64() * total
+		next := 0
+		for next < n-1 && min[next] <= x {
+			x -= min[next]
+			next++
+		}
+		for min[next] == 0 {
+			next--
+		}
+		chosen = append(chosen, next)
+		for i := range min {
+			if d := sqdist(i, next); d < min[i] {
+				min[i] = d
+			}
+		}
+	}
+	return chosen
+}
+
+// group collects the members of each of n clusters and their best members.
+func group(members []evo.Genome, assign []int, n int) []Cluster {
+	clusters := make([]Cluster, n)
+	best := make([]float64, n)
+	for i, g := range members {
+		c := assign[i]
+		clusters[c].Members = append(clusters[c].Members, g)
+		if fit := g.Fitness(); clusters[c].Best == nil || best[c] < fit {
+			clusters[c].Best, best[c] = g, fit
+		}
+	}
+	return clusters
+}
+
+// sorted drops the empty clusters and sorts the others by their best member.
+func sorted(clusters []Cluster) []Cluster {
+	nonempty := clusters[:0]
+	for _, c := range clusters {
+		if c.Best != nil {
+			nonempty = append(nonempty, c)
+		}
+	}
+	sort.SliceStable(nonempty, func(i, j int) bool {
+		return nonempty[i].Best.Fitness() > nonempty[j].Best.Fitness()
+	})
+	return nonempty
+}
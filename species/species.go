@@ -0,0 +1,135 @@
+// Package species provides speciation with explicit fitness sharing.
+//
+// Speciation clusters a population into species of similar genomes using a
+// distance function. Within each species, fitness is shared: the fitness of
+// each member is divided by the size of its species. This prevents any one
+// species from taking over the population and maintains diversity across
+// niches. Young species are additionally protected for a grace period, giving
+// new innovations time to be optimized before they must compete.
+//
+// The package is agnostic to the representation. For example, NEAT networks can
+// be speciated with neat.Compat.Distance.
+//
+// KMeans and Medoids cluster a population without tracking species, to
+// diagnose niching and to extract one solution per optimum.
+package species
+
+import (
+	"math"
+	"math/rand"
+	"sync"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Distance measures the dissimilarity of two genomes.
+type Distance func(a, b evo.Genome) float64
+
+// A Species is a cluster of similar genomes.
+type Species struct {
+	ID       int          // unique identifier
+	Rep      evo.Genome   // the representative used to test membership
+	Members  []evo.Genome // members in the current generation
+	Age      int          // generations since the species was founded
+	Best     float64      // best raw fitness ever achieved by a member
+	Stagnant int          // generations since Best improved
+}
+
+// A Speciator tracks species across generations. Speciators are safe for
+// concurrent use.
+type Speciator struct {
+	Dist      Distance // the distance function
+	Threshold float64  // the maximum distance to the representative of a species
+	Grace     int      // generations during which new species are protected
+	Boost     float64  // fitness multiplier for protected species, 1 if 0
+
+	mu      sync.Mutex
+	species []*Species
+	nextID  int
+}
+
+// A Shared genome wraps a genome, replacing its fitness with shared fitness.
+type Shared struct {
+	evo.Genome
+	Species *Species
+	fit     float64
+}
+
+// Fitness returns the shared fitness.
+func (s *Shared) Fitness() float64 {
+	return s.fit
+}
+
+// Update assigns a generation of genomes to species and returns the genomes
+// wrapped with their shared fitness, in the same order.
+//
+// Each genome joins the first existing species whose representative is within
+// the threshold, or else founds a new species. Species without members go
+// extinct, and each surviving species chooses a random member as its new
+// representative. The shared fitness of a genome is its raw fitness divided by
+// the size of its species, and multiplied by the boost while the species is
+// younger than the grace period.
+func (sp *Speciator) Update(genomes []evo.Genome) (shared []evo.Genome) {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+
+	for _, s := range sp.species {
+		s.Members = s.Members[:0]
+		s.Age++
+	}
+	assigned := make([]*Species, len(genomes))
+	for i, g := range genomes {
+		for _, s := range sp.species {
+			if sp.Dist(g, s.Rep) < sp.Threshold {
+				assigned[i] = s
+				break
+			}
+		}
+		if assigned[i] == nil {
+			s := &Species{ID: sp.nextID, Rep: g, Best: math.Inf(-1)}
+			sp.nextID++
+			sp.species = append(sp.species, s)
+			assigned[i] = s
+		}
+		assigned[i].Members = append(assigned[i].Members, g)
+	}
+
+	live := sp.species[:0]
+	for _, s := range sp.species {
+		if len(s.Members) == 0 {
+			continue
+		}
+		s.Rep = s.Members[rand.Intn(len(s.Members))]
+		s.Stagnant++
+		for _, g := range s.Members {
+			if fit := g.Fitness(); fit > s.Best {
+				s.Best = fit
+				s.Stagnant = 0
+			}
+		}
+		live = append(live, s)
+	}
+	sp.species = live
+
+	boost := sp.Boost
+	if boost == 0 {
+		boost = 1
+	}
+	shared = make([]evo.Genome, len(genomes))
+	for i, g := range genomes {
+		s := assigned[i]
+		fit := g.Fitness() / float64(len(s.Members))
+		if s.Age < sp.Grace {
+			fit *= boost
+		}
+		shared[i] = &Shared{Genome: g, Species: s, fit: fit}
+	}
+	return shared
+}
+
+// Species returns the species of the most recent generation.
+func (sp *Speciator) Species() []*Species {
+	sp.mu.Lock()
+	defer sp.mu.Unlock()
+	return append([]*Species(nil), sp.species...)
+}
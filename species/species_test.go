@@ -0,0 +1,71 @@
+package species_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/species"
+)
+
+type dummy float64
+
+func (d dummy) Fitness() float64 { return float64(d) }
+
+func dist(a, b evo.Genome) float64 {
+	return math.Abs(float64(a.(dummy) - b.(dummy)))
+}
+
+func TestUpdate(t *testing.T) {
+	sp := species.Speciator{Dist: dist, Threshold: 1.5, Grace: 2, Boost: 2}
+	shared := sp.Update([]evo.Genome{dummy(1), dummy(2), dummy(10)})
+	if len(sp.Species()) != 2 {
+		t.Fail()
+	}
+	if shared[0].Fitness() != 1 || shared[1].Fitness() != 2 || shared[2].Fitness() != 20 {
+		t.Fail()
+	}
+	if shared[0].(*species.Shared).Genome != dummy(1) {
+		t.Fail()
+	}
+
+	sp.Update([]evo.Genome{dummy(1), dummy(2), dummy(10)})
+	shared = sp.Update([]evo.Genome{dummy(1), dummy(2)})
+	if len(sp.Species()) != 1 || shared[1].Fitness() != 1 {
+		t.Fail()
+	}
+	if s := sp.Species()[0]; s.Age != 2 || s.Stagnant != 2 || s.Best != 2 {
+		t.Fail()
+	}
+}
+
+func TestMedoids(t *testing.T) {
+	members := []evo.Genome{dummy(1), dummy(2), dummy(3), dummy(11), dummy(12), dummy(13)}
+	clusters := species.Medoids(members, 2, dist)
+	if len(clusters) != 2 || clusters[0].Size() != 3 || clusters[1].Size() != 3 {
+		t.Fatal(clusters)
+	}
+	if clusters[0].Best != dummy(13) || clusters[0].Medoid != dummy(12) || clusters[1].Medoid != dummy(2) {
+		t.Error(clusters)
+	}
+}
+
+func TestKMeans(t *testing.T) {
+	members := []evo.Genome{dummy(1), dummy(2), dummy(3), dummy(11), dummy(12), dummy(13), dummy(14)}
+	clusters := species.KMeans(members, 2, func(g evo.Genome) []float64 {
+		return []float64{float64(g.(dummy))}
+	})
+	if len(clusters) != 2 || clusters[0].Size() != 4 || clusters[1].Size() != 3 {
+		t.Fatal(clusters)
+	}
+	if clusters[0].Centroid[0] != 12.5 || clusters[1].Centroid[0] != 2 || clusters[0].Best != dummy(14) {
+		t.Error(clusters)
+	}
+
+	// no more clusters than distinct points
+	if clusters := species.KMeans([]evo.Genome{dummy(1), dummy(1)}, 3, func(g evo.Genome) []float64 {
+		return []float64{float64(g.(dummy))}
+	}); len(clusters) != 1 || clusters[0].Size() != 2 {
+		t.Error(clusters)
+	}
+}
@@ -0,0 +1,62 @@
+package evo
+
+import (
+	"math"
+	"time"
+)
+
+// A Stall describes a lack of progress after which an optimization should
+// stop. Progress is an improvement of the best fitness by more than Delta. The
+// optimization stalls once there is no progress within the given number of
+// evaluations or the given duration, whichever comes first. A zero limit is
+// ignored.
+type Stall struct {
+	Delta       float64       // the minimum improvement that counts as progress
+	Evaluations int           // evaluations without progress, as by Throughput
+	Duration    time.Duration // time without progress
+	Clock       Clock         // measures the duration, SystemClock if nil
+}
+
+// A Tracked population reports the best genome it has observed and its
+// throughput, e.g. a gen.Population or a graph.Graph.
+type Tracked interface {
+	Best() Record
+	Throughput() Throughput
+}
+
+// Condition returns a ConditionFn which is true once the best-so-far fitness
+// of the population has stalled. Evaluations are counted by the throughput of
+// the population, so concurrent runs do not count each other's evaluations.
+// The returned function keeps state and must not be shared between polls.
+func (s Stall) Condition(pop Tracked) ConditionFn {
+	clock := s.Clock
+	if clock == nil {
+		clock = SystemClock
+	}
+	var (
+		started bool
+		mark    float64   // the fitness of the last progress
+		evals   int       // the evaluation count at the last progress
+		at      time.Time // the time of the last progress
+	)
+	return func() bool {
+		fit := math.Inf(-1)
+		if best := pop.Best(); best.Genome != nil {
+			fit = best.Fitness
+		}
+		now := clock.Now()
+		n := pop.Throughput().Evaluations
+		if !started || mark+s.Delta < fit {
+			started = true
+			mark, evals, at = fit, n, now
+			return false
+		}
+		if 0 < s.Evaluations && s.Evaluations <= n-evals {
+			return true
+		}
+		if 0 < s.Duration && s.Duration <= now.Sub(at) {
+			return true
+		}
+		return false
+	}
+}
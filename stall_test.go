@@ -0,0 +1,87 @@
+package evo_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// tracked is a population whose best fitness and evaluations are set by the
+// test.
+type tracked struct {
+	fit   float64
+	evals int
+}
+
+func (p *tracked) Best() evo.Record {
+	return evo.Record{Genome: point(p.fit), Fitness: p.fit}
+}
+
+func (p *tracked) Throughput() evo.Throughput {
+	return evo.Throughput{Evaluations: p.evals}
+}
+
+func TestStallDuration(t *testing.T) {
+	pop := new(tracked)
+	cond := evo.Stall{Delta: 0.5, Duration: 20 * time.Millisecond}.Condition(pop)
+	if cond() {
+		t.Fail()
+	}
+	time.Sleep(10 * time.Millisecond)
+	pop.fit = 1 // progress
+	if cond() {
+		t.Fail()
+	}
+	time.Sleep(15 * time.Millisecond)
+	pop.fit = 1.2 // not enough progress
+	if cond() {
+		t.Fail()
+	}
+	time.Sleep(10 * time.Millisecond)
+	if !cond() {
+		t.Fail()
+	}
+}
+
+func TestStallEvaluations(t *testing.T) {
+	pop := new(tracked)
+	cond := evo.Stall{Evaluations: 3}.Condition(pop)
+	if cond() {
+		t.Fail()
+	}
+	pop.evals = 2
+	if cond() {
+		t.Fail()
+	}
+
+	// progress resets the count
+	pop.fit, pop.evals = 1, 4
+	if cond() {
+		t.Fail()
+	}
+	pop.evals = 6
+	if cond() {
+		t.Fail()
+	}
+	pop.evals = 7
+	if !cond() {
+		t.Fail()
+	}
+}
+
+func TestStallClock(t *testing.T) {
+	clock := evo.NewFakeClock(time.Unix(0, 0))
+	cond := evo.Stall{Duration: time.Minute, Clock: clock}.Condition(new(tracked))
+	if cond() {
+		t.Fail()
+	}
+	clock.Advance(59 * time.Second)
+	if cond() {
+		t.Fail()
+	}
+	clock.Advance(time.Second)
+	if !cond() {
+		t.Fail()
+	}
+}
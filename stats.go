@@ -8,62 +8,89 @@ import (
 // A Stats object is a statistics collector. A common source of Stats objects is
 // the return value of Population.Stats() which gives statistics about the
 // fitness of genomes in the population.
+//
+// Stats are accumulated incrementally with Welford's algorithm, and merged with
+// the parallel algorithm of Chan et al. The sum of squared deviations is
+// tracked directly rather than as the difference of the sum of squares and the
+// squared sum, so the variance remains accurate for data of large magnitude.
 type Stats struct {
 	max, min float64
 	mean     float64
 	sumsq    float64 // sum of squares of deviation from the mean
+	weight   float64 // sum of weights
 	count    float64
+	gen      int // the generation of the data, 0 if unknown
 }
 
 // Put inserts a new value into the data.
 func (s Stats) Put(x float64) Stats {
+	return s.PutWeighted(x, 1)
+}
+
+// PutWeighted inserts a new value into the data with the given weight. The
+// weights act as frequencies: a value of weight 2 counts as much towards the
+// mean and variance as two values of weight 1, but is only counted once by
+// Count. Values with non-positive weight are ignored.
+func (s Stats) PutWeighted(x, w float64) Stats {
+	if w <= 0 {
+		return s
+	}
 	if s.count == 0 {
 		s.max = math.Inf(-1)
 		s.min = math.Inf(+1)
 	}
 
 	delta := x - s.mean
-	newcount := s.count + 1
+	newweight := s.weight + w
 
 	// max & min
 	s.max = math.Max(s.max, x)
 	s.min = math.Min(s.min, x)
 
 	// mean
-	s.mean += delta / newcount
+	s.mean += delta * (w / newweight)
 
 	// sum of squares
-	s.sumsq += delta * delta * (s.count / newcount)
+	s.sumsq += w * delta * delta * (s.weight / newweight)
 
-	// count
-	s.count = newcount
+	// weight & count
+	s.weight = newweight
+	s.count++
 
 	return s
 }
 
 // Merge merges the data of two Stats objects.
 func (s Stats) Merge(t Stats) Stats {
+	if t.count == 0 {
+		return s
+	}
 	if s.count == 0 {
-		s.max = math.Inf(-1)
-		s.min = math.Inf(+1)
+		return t
 	}
 
 	delta := t.mean - s.mean
-	newcount := t.count + s.count
+	newweight := t.weight + s.weight
 
 	// max & min
 	s.max = math.Max(s.max, t.max)
 	s.min = math.Min(s.min, t.min)
 
 	// mean
-	s.mean += delta * (t.count / newcount)
+	s.mean += delta * (t.weight / newweight)
 
 	// sum of squares
 	s.sumsq += t.sumsq
-	s.sumsq += delta * delta * (t.count * s.count / newcount)
+	s.sumsq += delta * delta * (t.weight * s.weight / newweight)
+
+	// weight & count
+	s.weight = newweight
+	s.count += t.count
 
-	// count
-	s.count = newcount
+	// generation
+	if s.gen < t.gen {
+		s.gen = t.gen
+	}
 
 	return s
 }
@@ -90,12 +117,12 @@ func (s Stats) Mean() float64 {
 
 // Var returns the population variance of the data.
 func (s Stats) Var() float64 {
-	return s.sumsq / s.count
+	return s.sumsq / s.weight
 }
 
 // SD returns the population standard deviation of the data.
 func (s Stats) SD() float64 {
-	return math.Sqrt(s.sumsq / s.count)
+	return math.Sqrt(s.Var())
 }
 
 // RSD returns the population relative standard deviation of the data, also
@@ -109,6 +136,19 @@ func (s Stats) Count() int {
 	return int(s.count)
 }
 
+// Generation returns the number of generations the population had evolved when
+// the statistics were taken, or 0 if unknown. Merged statistics report the
+// latest generation of either.
+func (s Stats) Generation() int {
+	return s.gen
+}
+
+// WithGeneration returns a copy of the statistics tagged with a generation.
+func (s Stats) WithGeneration(gen int) Stats {
+	s.gen = gen
+	return s
+}
+
 // String returns a string listing a summary of the statistics.
 func (s Stats) String() string {
 	return fmt.Sprintf("Max: %f | Min: %f | SD: %f",
@@ -116,3 +156,48 @@ func (s Stats) String() string {
 		s.Min(),
 		s.SD())
 }
+
+// A Window collects statistics over only the most recent values inserted. This
+// is useful, e.g., to detect plateaus without the statistics being dominated by
+// values from early in the run.
+type Window struct {
+	xs, ws []float64 // ring buffers of values and weights
+	next   int       // the next position to overwrite
+}
+
+// NewWindow returns an empty window of the given size.
+func NewWindow(size int) *Window {
+	return &Window{
+		xs: make([]float64, 0, size),
+		ws: make([]float64, 0, size),
+	}
+}
+
+// Put inserts a new value, evicting the oldest if the window is full.
+func (w *Window) Put(x float64) {
+	w.PutWeighted(x, 1)
+}
+
+// PutWeighted inserts a new weighted value, evicting the oldest if the window
+// is full.
+func (w *Window) PutWeighted(x, weight float64) {
+	if len(w.xs) < cap(w.xs) {
+		w.xs = append(w.xs, x)
+		w.ws = append(w.ws, weight)
+		return
+	}
+	if len(w.xs) == 0 {
+		return
+	}
+	w.xs[w.next] = x
+	w.ws[w.next] = weight
+	w.next = (w.next + 1) % len(w.xs)
+}
+
+// Stats returns the statistics of the values in the window.
+func (w *Window) Stats() (s Stats) {
+	for i := range w.xs {
+		s = s.PutWeighted(w.xs[i], w.ws[i])
+	}
+	return s
+}
@@ -23,6 +23,47 @@ func TestMerge(t *testing.T) {
 	}
 }
 
+func TestMergeEmpty(t *testing.T) {
+	var empty evo.Stats
+	stats := data()
+	if stats.Merge(empty) != stats || empty.Merge(stats) != stats {
+		t.Fail()
+	}
+	if empty.Merge(empty).Count() != 0 {
+		t.Fail()
+	}
+}
+
+func TestLargeMagnitude(t *testing.T) {
+	var stats evo.Stats
+	for _, x := range []float64{1e9 + 4, 1e9 + 7, 1e9 + 13, 1e9 + 16} {
+		stats = stats.Put(x)
+	}
+	if stats.Var() != 22.5 {
+		t.Fail()
+	}
+}
+
+func TestPutWeighted(t *testing.T) {
+	var a, b evo.Stats
+	a = a.Put(1).Put(1).Put(4)
+	b = b.PutWeighted(1, 2).PutWeighted(4, 1).PutWeighted(7, 0)
+	if a.Mean() != b.Mean() || a.Var() != b.Var() || b.Count() != 2 {
+		t.Fail()
+	}
+}
+
+func TestWindow(t *testing.T) {
+	w := evo.NewWindow(3)
+	for i := float64(0); i < 10; i++ {
+		w.Put(i)
+	}
+	stats := w.Stats()
+	if stats.Count() != 3 || stats.Min() != 7 || stats.Mean() != 8 {
+		t.Fail()
+	}
+}
+
 func TestMax(t *testing.T) {
 	stats := data()
 	if stats.Max() != 855 {
@@ -79,6 +120,17 @@ func TestCount(t *testing.T) {
 	}
 }
 
+func TestGeneration(t *testing.T) {
+	a := data().WithGeneration(3)
+	b := data().WithGeneration(5)
+	if a.Generation() != 3 || a.Merge(b).Generation() != 5 || b.Merge(a).Generation() != 5 {
+		t.Fail()
+	}
+	if data().Generation() != 0 {
+		t.Fail()
+	}
+}
+
 func data() (s evo.Stats) {
 	s = s.Put(810)
 	s = s.Put(820)
@@ -0,0 +1,389 @@
+// Package store records optimization runs for later analysis.
+//
+// A Store is a directory holding one subdirectory per run, keyed by a run ID.
+// Each run records the statistics of every generation, periodic snapshots of
+// the population, and its final result. Records are plain files: statistics
+// are appended as JSON lines, so a crashed run keeps everything recorded up to
+// the crash, and snapshots and results are replaced atomically. This makes the
+// store safe for long unattended runs without an embedded database, and easy
+// to inspect with standard tools.
+//
+//	s, err := store.Open("runs")
+//	run, err := s.Create("ackley-ipop-1")
+//	pop.OnGeneration(run.Generation)
+//	pop.Evolve(seed, body)
+//	pop.Wait()
+//	err = run.Finish(pop.Best())
+//
+// The query API reads runs back, e.g. to compare experiments:
+//
+//	ids, err := s.Runs()
+//	gens, err := s.Stats(ids[0])
+//	result, err := s.Result(ids[0])
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/snapshot"
+)
+
+// A Store is a directory of runs.
+type Store struct {
+	dir string
+}
+
+// Open opens the store in the directory, creating the directory if needed.
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// A Generation is the record of the statistics of one generation. Statistics
+// which are not finite, e.g. the -Inf minimum of a population with infeasible
+// members, are written as strings; see evo.Float.
+type Generation struct {
+	Generation int       `json:"generation"`
+	Time       time.Time `json:"time"`
+	Count      int       `json:"count"`
+	Max        float64   `json:"max"`
+	Min        float64   `json:"min"`
+	Mean       float64   `json:"mean"`
+	SD         float64   `json:"sd"`
+}
+
+// A Result is the record of the outcome of a run. Like the statistics of a
+// Generation, a fitness which is not finite is written as a string.
+type Result struct {
+	Fitness     float64         `json:"fitness"`     // the best fitness
+	Genome      json.RawMessage `json:"genome"`      // the best genome, serialized
+	Evaluations int             `json:"evaluations"` // evaluations to find the best
+	Elapsed     time.Duration   `json:"elapsed"`     // time to find the best
+	Finished    time.Time       `json:"finished"`
+
+	Info *evo.RunInfo `json:"info,omitempty"` // the run, if recorded
+}
+
+// generation is a Generation whose statistics are written as evo.Floats.
+type generation struct {
+	Generation int       `json:"generation"`
+	Time       time.Time `json:"time"`
+	Count      int       `json:"count"`
+	Max        evo.Float `json:"max"`
+	Min        evo.Float `json:"min"`
+	Mean       evo.Float `json:"mean"`
+	SD         evo.Float `json:"sd"`
+}
+
+// MarshalJSON writes the generation, with non-finite statistics as strings.
+func (g Generation) MarshalJSON() ([]byte, error) {
+	return json.Marshal(generation{g.Generation, g.Time, g.Count,
+		evo.Float(g.Max), evo.Float(g.Min), evo.Float(g.Mean), evo.Float(g.SD)})
+}
+
+// UnmarshalJSON reads a generation written by MarshalJSON.
+func (g *Generation) UnmarshalJSON(b []byte) error {
+	var rec generation
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return err
+	}
+	*g = Generation{rec.Generation, rec.Time, rec.Count,
+		float64(rec.Max), float64(rec.Min), float64(rec.Mean), float64(rec.SD)}
+	return nil
+}
+
+// result is a Result whose fitness is written as an evo.Float.
+type result struct {
+	Fitness evo.Float `json:"fitness"`
+	*resultFields
+}
+
+// resultFields is a Result without its methods, so that result embeds its
+// fields without recursing into Result.MarshalJSON.
+type resultFields Result
+
+// MarshalJSON writes the result, with a non-finite fitness as a string.
+func (r Result) MarshalJSON() ([]byte, error) {
+	return json.Marshal(result{evo.Float(r.Fitness), (*resultFields)(&r)})
+}
+
+// UnmarshalJSON reads a result written by MarshalJSON.
+func (r *Result) UnmarshalJSON(b []byte) error {
+	rec := result{resultFields: (*resultFields)(r)}
+	if err := json.Unmarshal(b, &rec); err != nil {
+		return err
+	}
+	r.Fitness = float64(rec.Fitness)
+	return nil
+}
+
+// ErrExists is returned when creating a run whose ID is taken.
+var ErrExists = errors.New("store: run exists")
+
+// Create starts recording a new run with the given ID.
+func (s *Store) Create(id string) (*Run, error) {
+	dir := filepath.Join(s.dir, id)
+	if err := os.Mkdir(dir, 0755); err != nil {
+		if os.IsExist(err) {
+			return nil, ErrExists
+		}
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	f, err := os.Create(filepath.Join(dir, "stats.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	return &Run{ID: id, dir: dir, stats: f}, nil
+}
+
+// Runs returns the IDs of the runs in the store, sorted.
+func (s *Store) Runs() ([]string, error) {
+	entries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	var ids []string
+	for _, e := range entries {
+		if e.IsDir() {
+			ids = append(ids, e.Name())
+		}
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// Stats returns the statistics recorded by a run, in order. A truncated last
+// line, as left by a crash, is ignored.
+func (s *Store) Stats(id string) ([]Generation, error) {
+	f, err := os.Open(filepath.Join(s.dir, id, "stats.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	defer f.Close()
+	var gens []Generation
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		var g Generation
+		if err := json.Unmarshal(sc.Bytes(), &g); err != nil {
+			break
+		}
+		gens = append(gens, g)
+	}
+	if err := sc.Err(); err != nil {
+		return gens, fmt.Errorf("store: %v", err)
+	}
+	return gens, nil
+}
+
+// Snapshots returns the snapshots recorded by a run, in order.
+func (s *Store) Snapshots(id string) ([]snapshot.Snapshot, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, id, "snapshot-*.json"))
+	if err != nil {
+		return nil, fmt.Errorf("store: %v", err)
+	}
+	sort.Strings(paths)
+	snaps := make([]snapshot.Snapshot, len(paths))
+	for i, path := range paths {
+		if snaps[i], err = snapshot.Load(path); err != nil {
+			return nil, err
+		}
+	}
+	return snaps, nil
+}
+
+// Archive returns the members recorded by a run, to warm-start a new run with
+// snapshot.WarmStart: the last snapshot of the run, with the best genome of its
+// result added if the run finished, since the best genome may have been lost
+// before the last snapshot. It fails if the run recorded neither.
+func (s *Store) Archive(id string) (snap snapshot.Snapshot, err error) {
+	snaps, err := s.Snapshots(id)
+	if err != nil {
+		return snap, err
+	}
+	if len(snaps) != 0 {
+		snap = snaps[len(snaps)-1]
+	}
+	if _, err := os.Stat(filepath.Join(s.dir, id, "result.json")); err == nil {
+		res, err := s.Result(id)
+		if err != nil {
+			return snap, err
+		}
+		if len(res.Genome) != 0 {
+			snap.Members = append(snap.Members, snapshot.Member{Fitness: res.Fitness, Genome: res.Genome})
+		}
+	}
+	if len(snap.Members) == 0 {
+		return snap, fmt.Errorf("store: %s: no members recorded", id)
+	}
+	return snap, nil
+}
+
+// Info returns the RunInfo recorded by a run.
+func (s *Store) Info(id string) (info evo.RunInfo, err error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, id, "info.json"))
+	if err != nil {
+		return info, fmt.Errorf("store: %v", err)
+	}
+	if err = json.Unmarshal(b, &info); err != nil {
+		err = fmt.Errorf("store: %s: %v", id, err)
+	}
+	return info, err
+}
+
+// Result returns the result of a finished run.
+func (s *Store) Result(id string) (r Result, err error) {
+	b, err := os.ReadFile(filepath.Join(s.dir, id, "result.json"))
+	if err != nil {
+		return r, fmt.Errorf("store: %v", err)
+	}
+	if err = json.Unmarshal(b, &r); err != nil {
+		err = fmt.Errorf("store: %s: %v", id, err)
+	}
+	return r, err
+}
+
+// A Run records a single run. Runs are safe for concurrent use.
+type Run struct {
+	ID string
+
+	mu    sync.Mutex
+	dir   string
+	stats *os.File
+	snaps int
+	info  *evo.RunInfo
+	err   error
+}
+
+// SetInfo records the RunInfo of the run. The info is also attached to the
+// snapshots and the result recorded afterwards.
+func (r *Run) SetInfo(info evo.RunInfo) error {
+	b, err := json.Marshal(info)
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.info = &info
+	if err := writeFile(filepath.Join(r.dir, "info.json"), b); err != nil {
+		r.fail(err)
+		return err
+	}
+	return nil
+}
+
+// Generation records the statistics of a generation. Its signature matches
+// gen.Population.OnGeneration and graph.Graph.OnIteration, so it can be
+// installed directly. Errors are reported by Err.
+func (r *Run) Generation(generation int, stats evo.Stats) {
+	rec := Generation{
+		Generation: generation,
+		Time:       time.Now(),
+		Count:      stats.Count(),
+		Max:        stats.Max(),
+		Min:        stats.Min(),
+		Mean:       stats.Mean(),
+		SD:         stats.SD(),
+	}
+	b, err := json.Marshal(rec)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if err == nil {
+		_, err = r.stats.Write(append(b, '\n'))
+	}
+	r.fail(err)
+}
+
+// Snapshot records a snapshot of the population.
+func (r *Run) Snapshot(pop evo.Population) error {
+	v := pop.View()
+	snap, err := snapshot.Take(v)
+	v.Close()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	snap.Seq = r.snaps
+	snap.Info = r.info
+	path := filepath.Join(r.dir, fmt.Sprintf("snapshot-%06d.json", r.snaps))
+	if err := snapshot.Save(path, snap); err != nil {
+		r.fail(err)
+		return err
+	}
+	r.snaps++
+	return nil
+}
+
+// Watch records a snapshot of the population at the given period until the
+// population stops.
+func (r *Run) Watch(pop evo.Population, period time.Duration) {
+	pop.Poll(period, func() bool {
+		r.Snapshot(pop)
+		return false
+	})
+}
+
+// Finish records the result of the run, e.g. the best-so-far record of the
+// population, and closes the run.
+func (r *Run) Finish(best evo.Record) error {
+	res := Result{
+		Fitness:     best.Fitness,
+		Evaluations: best.Evaluations,
+		Elapsed:     best.Elapsed,
+		Finished:    time.Now(),
+	}
+	if best.Genome != nil {
+		snap, err := snapshot.Take(evo.NewView([]evo.Genome{best.Genome}))
+		if err != nil {
+			return err
+		}
+		res.Genome = snap.Members[0].Genome
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	res.Info = r.info
+	b, err := json.Marshal(res)
+	if err != nil {
+		return err
+	}
+	if err := writeFile(filepath.Join(r.dir, "result.json"), b); err != nil {
+		return err
+	}
+	if err := r.stats.Close(); err != nil {
+		return err
+	}
+	return r.err
+}
+
+// Err returns the first error encountered while recording, if any.
+func (r *Run) Err() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// fail records the first error.
+func (r *Run) fail(err error) {
+	if err != nil && r.err == nil {
+		r.err = fmt.Errorf("store: %s: %v", r.ID, err)
+	}
+}
+
+// writeFile replaces a file atomically.
+func writeFile(path string, b []byte) error {
+	if err := os.WriteFile(path+".tmp", b, 0644); err != nil {
+		return err
+	}
+	return os.Rename(path+".tmp", path)
+}
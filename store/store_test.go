@@ -0,0 +1,148 @@
+package store_test
+
+import (
+	"encoding/json"
+	"math"
+	"os"
+	"testing"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/snapshot"
+	"github.com/cbarrick/evo/store"
+)
+
+// number is a genome which is its own fitness.
+type number float64
+
+func (x number) Fitness() float64 { return float64(x) }
+
+// store.go
+// -------------------------
+
+func TestStore(t *testing.T) {
+	s, err := store.Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	run, err := s.Create("run-1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Create("run-1"); err != store.ErrExists {
+		t.Error("duplicate run:", err)
+	}
+
+	if err := run.SetInfo(evo.Capture("test", map[string]int{"size": 2})); err != nil {
+		t.Fatal(err)
+	}
+
+	var pop gen.Population
+	pop.OnGeneration(run.Generation)
+	pop.Evolve([]evo.Genome{number(0), number(1)}, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current.(number) + 1
+	})
+	pop.Poll(0, func() bool { return 10 <= pop.Generation() })
+	pop.Wait()
+	if err := run.Snapshot(&pop); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Finish(pop.Best()); err != nil {
+		t.Fatal(err)
+	}
+
+	ids, err := s.Runs()
+	if err != nil || len(ids) != 1 || ids[0] != "run-1" {
+		t.Fatal(ids, err)
+	}
+	gens, err := s.Stats("run-1")
+	if err != nil || len(gens) < 10 {
+		t.Fatal(len(gens), err)
+	}
+	for i := range gens {
+		if gens[i].Generation != i+1 || gens[i].Max != float64(i+2) || gens[i].Count != 2 {
+			t.Fatal(gens[i])
+		}
+	}
+	snaps, err := s.Snapshots("run-1")
+	if err != nil || len(snaps) != 1 || len(snaps[0].Members) != 2 {
+		t.Fatal(snaps, err)
+	}
+	res, err := s.Result("run-1")
+	if err != nil || res.Fitness != pop.Best().Fitness || string(res.Genome) == "" {
+		t.Fatal(res, err)
+	}
+	info, err := s.Info("run-1")
+	if err != nil || info.Tag != "test" || res.Info == nil || snaps[0].Info == nil {
+		t.Fatal(info, err)
+	}
+}
+
+func TestTruncated(t *testing.T) {
+	dir := t.TempDir()
+	s, _ := store.Open(dir)
+	run, _ := s.Create("crashed")
+	var stats evo.Stats
+	run.Generation(1, stats.Put(1))
+	f, err := os.OpenFile(dir+"/crashed/stats.jsonl", os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"generation":2,"ti`)
+	f.Close()
+	gens, err := s.Stats("crashed")
+	if err != nil || len(gens) != 1 {
+		t.Error(gens, err)
+	}
+}
+
+func TestArchive(t *testing.T) {
+	s, _ := store.Open(t.TempDir())
+	if _, err := s.Archive("missing"); err == nil {
+		t.Error("archive of a missing run")
+	}
+
+	run, _ := s.Create("run-1")
+	var pop gen.Population
+	pop.Evolve([]evo.Genome{number(1), number(2)}, func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		return current
+	})
+	pop.Stop()
+	if err := run.Snapshot(&pop); err != nil {
+		t.Fatal(err)
+	}
+	if err := run.Finish(evo.Record{Genome: number(5), Fitness: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	archive, err := s.Archive("run-1")
+	if err != nil || len(archive.Members) != 3 {
+		t.Fatal(archive, err)
+	}
+	seed, err := snapshot.WarmStart(archive, 2, 0, func(data json.RawMessage) (evo.Genome, error) {
+		var x number
+		err := json.Unmarshal(data, &x)
+		return x, err
+	}, nil)
+	if err != nil || seed[0] != number(5) || seed[1] != number(2) {
+		t.Error(seed, err)
+	}
+}
+
+func TestNonFinite(t *testing.T) {
+	s, _ := store.Open(t.TempDir())
+	run, _ := s.Create("infeasible")
+	var stats evo.Stats
+	run.Generation(1, stats.Put(math.Inf(-1)))
+	if err := run.Finish(evo.Record{Genome: number(0), Fitness: math.Inf(-1)}); err != nil {
+		t.Fatal(err)
+	}
+	gens, err := s.Stats("infeasible")
+	if err != nil || len(gens) != 1 || gens[0].Count != 1 || !math.IsInf(gens[0].Min, -1) {
+		t.Fatal(gens, err)
+	}
+	res, err := s.Result("infeasible")
+	if err != nil || !math.IsInf(res.Fitness, -1) || res.Evaluations != 0 {
+		t.Fatal(res, err)
+	}
+}
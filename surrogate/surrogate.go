@@ -0,0 +1,164 @@
+// Package surrogate provides surrogate-assisted evaluation.
+//
+// When the fitness function is expensive, e.g. a simulation, a cheap surrogate
+// model can be trained on the genomes evaluated so far and used to pre-screen
+// offspring. Only the offspring predicted to be the most promising are sent to
+// the true fitness function, and their true fitness is used to further train
+// the model.
+//
+// Genomes are described to the model by real vectors. Genomes represented by
+// real vectors can simply return their gene; other genomes may return any
+// numeric feature vector.
+package surrogate
+
+import (
+	"math"
+	"sort"
+	"sync"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/real"
+)
+
+// A Featurer is a genome which can be described by a real vector.
+type Featurer interface {
+	evo.Genome
+	Features() real.Vector
+}
+
+// A Model predicts fitness from features. Models must be safe for concurrent
+// use.
+type Model interface {
+	// Train adds an observation of the true fitness of some features.
+	Train(x real.Vector, fit float64)
+
+	// Predict returns the predicted fitness of some features.
+	Predict(x real.Vector) float64
+
+	// Len returns the number of observations used by the model.
+	Len() int
+}
+
+// A KNN is a k-nearest-neighbors model. The prediction is the mean fitness of
+// the k nearest observations, weighted by inverse Euclidean distance.
+type KNN struct {
+	k, capacity int
+
+	mu   sync.RWMutex
+	xs   []real.Vector
+	fits []float64
+	next int // next position to overwrite once at capacity
+}
+
+// NewKNN returns an empty k-nearest-neighbors model. Once the model holds the
+// given capacity of observations, the oldest are forgotten. A capacity of 0
+// means unbounded.
+func NewKNN(k, capacity int) *KNN {
+	return &KNN{k: k, capacity: capacity}
+}
+
+// Train adds an observation to the model.
+func (m *KNN) Train(x real.Vector, fit float64) {
+	x = x.Copy()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.capacity <= 0 || len(m.xs) < m.capacity {
+		m.xs = append(m.xs, x)
+		m.fits = append(m.fits, fit)
+		return
+	}
+	m.xs[m.next] = x
+	m.fits[m.next] = fit
+	m.next = (m.next + 1) % m.capacity
+}
+
+// Predict returns the predicted fitness of x. The prediction is 0 if the model
+// has no observations.
+func (m *KNN) Predict(x real.Vector) float64 {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	type neighbor struct{ dist, fit float64 }
+	nbrs := make([]neighbor, len(m.xs))
+	for i := range m.xs {
+		var sum float64
+		for j := range x {
+			d := x[j] - m.xs[i][j]
+			sum += d * d
+		}
+		nbrs[i] = neighbor{math.Sqrt(sum), m.fits[i]}
+	}
+	sort.Slice(nbrs, func(i, j int) bool { return nbrs[i].dist < nbrs[j].dist })
+	if m.k < len(nbrs) {
+		nbrs = nbrs[:m.k]
+	}
+	var sum, weights float64
+	for _, n := range nbrs {
+		if n.dist == 0 {
+			return n.fit
+		}
+		sum += n.fit / n.dist
+		weights += 1 / n.dist
+	}
+	if weights == 0 {
+		return 0
+	}
+	return sum / weights
+}
+
+// Len returns the number of observations held by the model.
+func (m *KNN) Len() int {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return len(m.xs)
+}
+
+// A Screener pre-screens genomes with a surrogate model.
+type Screener struct {
+	model  Model
+	warmup int
+}
+
+// NewScreener returns a screener using the given model. Until the model holds
+// warmup observations, screening evaluates every candidate with the true
+// fitness function to train the model.
+func NewScreener(model Model, warmup int) *Screener {
+	return &Screener{model: model, warmup: warmup}
+}
+
+// Observe evaluates the true fitness of a genome and trains the model with it.
+func (s *Screener) Observe(g Featurer) float64 {
+	fit := g.Fitness()
+	s.model.Train(g.Features(), fit)
+	return fit
+}
+
+// Screen returns the n candidates with the best predicted fitness. The chosen
+// candidates are evaluated with the true fitness function, which trains the
+// model. The other candidates are never evaluated.
+func (s *Screener) Screen(n int, candidates ...Featurer) (chosen []Featurer) {
+	type prediction struct {
+		g   Featurer
+		fit float64
+	}
+	preds := make([]prediction, len(candidates))
+	warm := s.warmup <= s.model.Len()
+	for i, g := range candidates {
+		if warm {
+			preds[i] = prediction{g, s.model.Predict(g.Features())}
+		} else {
+			preds[i] = prediction{g, s.Observe(g)}
+		}
+	}
+	sort.SliceStable(preds, func(i, j int) bool { return preds[i].fit > preds[j].fit })
+	if n > len(preds) {
+		n = len(preds)
+	}
+	chosen = make([]Featurer, n)
+	for i := range chosen {
+		chosen[i] = preds[i].g
+		if warm {
+			s.Observe(chosen[i])
+		}
+	}
+	return chosen
+}
@@ -0,0 +1,51 @@
+package surrogate_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo/real"
+	"github.com/cbarrick/evo/surrogate"
+)
+
+// point is a genome maximizing -x^2 which counts its evaluations.
+type point struct {
+	x     real.Vector
+	evals *int
+}
+
+func (p point) Features() real.Vector { return p.x }
+
+func (p point) Fitness() float64 {
+	*p.evals++
+	return -p.x[0] * p.x[0]
+}
+
+func TestKNN(t *testing.T) {
+	m := surrogate.NewKNN(2, 3)
+	m.Train(real.Vector{0}, 0)
+	m.Train(real.Vector{2}, 2)
+	if m.Predict(real.Vector{0}) != 0 || m.Predict(real.Vector{1}) != 1 {
+		t.Fail()
+	}
+	m.Train(real.Vector{4}, 4)
+	m.Train(real.Vector{6}, 6)
+	if m.Len() != 3 || m.Predict(real.Vector{0}) != 2+2.0/3 {
+		t.Fail()
+	}
+}
+
+func TestScreen(t *testing.T) {
+	var evals int
+	s := surrogate.NewScreener(surrogate.NewKNN(1, 0), 3)
+	for _, x := range []float64{-2, 0, 2} {
+		s.Observe(point{real.Vector{x}, &evals})
+	}
+	evals = 0
+	chosen := s.Screen(1,
+		point{real.Vector{-1.9}, &evals},
+		point{real.Vector{0.1}, &evals},
+		point{real.Vector{2.1}, &evals})
+	if chosen[0].Features()[0] != 0.1 || evals != 1 {
+		t.Fail()
+	}
+}
@@ -0,0 +1,71 @@
+package evo
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// A Meter measures the throughput of a population. Each population owns its
+// meter, so concurrent runs in the same process do not count each other's
+// evaluations. Meters are safe for concurrent use.
+type Meter struct {
+	start time.Time
+	evals atomic.Int64
+	iters atomic.Int64
+}
+
+// NewMeter returns a meter which starts measuring immediately.
+func NewMeter() *Meter {
+	return &Meter{start: time.Now()}
+}
+
+// Iterate records an iteration, i.e. a call to an EvolveFn.
+func (m *Meter) Iterate() {
+	m.iters.Add(1)
+}
+
+// Evaluate records a fitness evaluation. Populations record an evaluation for
+// each genome returned by their EvolveFn, which they evaluate to track the best
+// genome, whether or not the genome caches its fitness.
+func (m *Meter) Evaluate() {
+	m.evals.Add(1)
+}
+
+// Evaluations returns the number of evaluations recorded so far. It returns 0
+// for a nil meter, e.g. that of a population which was never evolved.
+func (m *Meter) Evaluations() int {
+	if m == nil {
+		return 0
+	}
+	return int(m.evals.Load())
+}
+
+// Throughput returns the throughput since the meter was created, or a zero
+// throughput for a nil meter.
+func (m *Meter) Throughput() Throughput {
+	if m == nil {
+		return Throughput{}
+	}
+	return Throughput{
+		Evaluations: int(m.evals.Load()),
+		Iterations:  int(m.iters.Load()),
+		Elapsed:     time.Since(m.start),
+	}
+}
+
+// A Throughput summarizes the performance of a population over some period.
+type Throughput struct {
+	Evaluations int           // fitness evaluations recorded by the meter
+	Iterations  int           // calls to the EvolveFn
+	Elapsed     time.Duration // length of the period
+}
+
+// EvalsPerSec returns the number of fitness evaluations per second.
+func (t Throughput) EvalsPerSec() float64 {
+	return float64(t.Evaluations) / t.Elapsed.Seconds()
+}
+
+// ItersPerSec returns the number of iterations per second.
+func (t Throughput) ItersPerSec() float64 {
+	return float64(t.Iterations) / t.Elapsed.Seconds()
+}
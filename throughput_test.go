@@ -0,0 +1,27 @@
+package evo_test
+
+import (
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestMeter(t *testing.T) {
+	meter := evo.NewMeter()
+	meter.Iterate()
+	meter.Iterate()
+	meter.Evaluate()
+	tp := meter.Throughput()
+	if tp.Evaluations != 1 || tp.Iterations != 2 || tp.ItersPerSec() <= 0 {
+		t.Fail()
+	}
+
+	// each meter counts its own evaluations
+	if evo.NewMeter().Throughput().Evaluations != 0 {
+		t.Fail()
+	}
+	var none *evo.Meter
+	if none.Throughput() != (evo.Throughput{}) || none.Evaluations() != 0 {
+		t.Fail()
+	}
+}
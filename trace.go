@@ -0,0 +1,66 @@
+package evo
+
+import "context"
+
+// A Tracer records the phases of an optimization as spans, e.g. each generation
+// of a population or each migration of an island model. The method set mirrors
+// the OpenTelemetry tracing API, so that an OpenTelemetry tracer can be adapted
+// in a few lines without Evo depending on it:
+//
+//	type otelTracer struct{ trace.Tracer }
+//
+//	func (t otelTracer) Start(ctx context.Context, name string, attrs ...evo.Attr) (context.Context, evo.Span) {
+//		ctx, span := t.Tracer.Start(ctx, name)
+//		s := otelSpan{span}
+//		s.SetAttributes(attrs...)
+//		return ctx, s
+//	}
+//
+//	type otelSpan struct{ trace.Span }
+//
+//	func (s otelSpan) SetAttributes(attrs ...evo.Attr) {
+//		for _, a := range attrs {
+//			s.Span.SetAttributes(attribute.Float64(a.Key, a.Value))
+//		}
+//	}
+//
+//	func (s otelSpan) End() { s.Span.End() }
+//
+// Tracers must be safe for concurrent use.
+type Tracer interface {
+	Start(ctx context.Context, name string, attrs ...Attr) (context.Context, Span)
+}
+
+// A Span is a phase of the optimization started by a Tracer.
+type Span interface {
+	// SetAttributes annotates the span.
+	SetAttributes(attrs ...Attr)
+
+	// End completes the span.
+	End()
+}
+
+// An Attr is a numeric annotation of a span.
+type Attr struct {
+	Key   string
+	Value float64
+}
+
+// StartSpan starts a span with the tracer. If the tracer is nil, StartSpan
+// returns the context unchanged and a span which does nothing, so that
+// populations can be instrumented without checking whether tracing is enabled.
+func StartSpan(ctx context.Context, t Tracer, name string, attrs ...Attr) (context.Context, Span) {
+	if t == nil {
+		return ctx, nopSpan{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return t.Start(ctx, name, attrs...)
+}
+
+// nopSpan is the span of a nil tracer.
+type nopSpan struct{}
+
+func (nopSpan) SetAttributes(...Attr) {}
+func (nopSpan) End()                  {}
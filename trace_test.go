@@ -0,0 +1,49 @@
+package evo_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/cbarrick/evo"
+)
+
+type recorder struct {
+	sync.Mutex
+	spans []*span
+}
+
+type span struct {
+	name  string
+	attrs []evo.Attr
+	ended bool
+}
+
+func (r *recorder) Start(ctx context.Context, name string, attrs ...evo.Attr) (context.Context, evo.Span) {
+	r.Lock()
+	defer r.Unlock()
+	s := &span{name: name, attrs: attrs}
+	r.spans = append(r.spans, s)
+	return ctx, s
+}
+
+func (s *span) SetAttributes(attrs ...evo.Attr) { s.attrs = append(s.attrs, attrs...) }
+func (s *span) End()                            { s.ended = true }
+
+func TestStartSpan(t *testing.T) {
+	ctx := context.Background()
+	if got, s := evo.StartSpan(ctx, nil, "nop"); got != ctx || s == nil {
+		t.Fail()
+	} else {
+		s.SetAttributes(evo.Attr{"x", 1})
+		s.End()
+	}
+
+	var r recorder
+	_, s := evo.StartSpan(ctx, &r, "generation", evo.Attr{"generation", 1})
+	s.SetAttributes(evo.Attr{"best", 2})
+	s.End()
+	if len(r.spans) != 1 || r.spans[0].name != "generation" || len(r.spans[0].attrs) != 2 || !r.spans[0].ended {
+		t.Fail()
+	}
+}
@@ -0,0 +1,190 @@
+package evo
+
+import (
+	"math"
+	"sync"
+	"time"
+)
+
+// A Trend estimates whether an optimization is still improving from the recent
+// history of its best and mean fitness. Each series is smoothed by a moving
+// average and fitted by a least-squares line over a window of observations;
+// the slope of the line is the rate of progress. Comparing the slopes of the
+// older and newer halves of the window extrapolates when the progress of the
+// best fitness will fall below the threshold, assuming that it decays
+// geometrically, as it typically does when a run converges.
+//
+//	trend := evo.NewTrend(50, 1e-3)
+//	pop.Poll(100*time.Millisecond, trend.Condition(pop))
+//
+// Trends are safe for concurrent use.
+type Trend struct {
+	mu        sync.Mutex
+	window    int
+	threshold float64
+	clock     Clock // times the observations of populations
+	times     []time.Time
+	best      []float64
+	mean      []float64
+}
+
+// A Progress is an estimate of a Trend.
+type Progress struct {
+	BestSlope float64       // the rate of progress of the best fitness, per second
+	MeanSlope float64       // the rate of progress of the mean fitness, per second
+	Improving bool          // whether the best fitness progresses above the threshold
+	Plateau   time.Duration // the estimated time to plateau, negative if unknown
+}
+
+// Attrs returns the estimate as span attributes, to annotate a span of the
+// optimization with its progress. An unknown time to plateau is omitted.
+func (p Progress) Attrs() []Attr {
+	improving := 0.0
+	if p.Improving {
+		improving = 1
+	}
+	attrs := []Attr{
+		{Key: "trend.best_slope", Value: p.BestSlope},
+		{Key: "trend.mean_slope", Value: p.MeanSlope},
+		{Key: "trend.improving", Value: improving},
+	}
+	if 0 <= p.Plateau {
+		attrs = append(attrs, Attr{Key: "trend.plateau_seconds", Value: p.Plateau.Seconds()})
+	}
+	return attrs
+}
+
+// NewTrend returns a trend fitted over the given number of observations, at
+// least 4, where the best fitness plateaus once it progresses by no more than
+// the threshold per second.
+func NewTrend(window int, threshold float64) *Trend {
+	if window < 4 {
+		window = 4
+	}
+	return &Trend{window: window, threshold: threshold, clock: SystemClock}
+}
+
+// SetClock sets the clock which times the observations of Observe, by default
+// the system clock, e.g. to the clock of the observed population or to a
+// FakeClock in tests. SetClock must be called before Observe.
+func (t *Trend) SetClock(c Clock) {
+	t.clock = c
+}
+
+// Put records an observation of the best and mean fitness at some time. Only
+// the most recent observations of the window are kept.
+func (t *Trend) Put(at time.Time, best, mean float64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.times) == t.window {
+		t.times = t.times[1:]
+		t.best = t.best[1:]
+		t.mean = t.mean[1:]
+	}
+	t.times = append(t.times, at)
+	t.best = append(t.best, best)
+	t.mean = append(t.mean, mean)
+}
+
+// Observe records the statistics of a population at the current time of the
+// clock of the trend.
+func (t *Trend) Observe(pop Population) {
+	s := pop.Stats()
+	t.Put(t.clock.Now(), s.Max(), s.Mean())
+}
+
+// Record records the statistics of a GenerationDone event, so that a trend can
+// follow a population through its Subscribe method. Other events are ignored.
+func (t *Trend) Record(ev Event) {
+	if ev.Kind == GenerationDone {
+		t.Put(ev.Time, ev.Stats.Max(), ev.Stats.Mean())
+	}
+}
+
+// Full returns true once the window of observations is full.
+func (t *Trend) Full() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return len(t.times) == t.window
+}
+
+// Estimate returns the estimate of the current observations. With fewer than 4
+// observations, the run is taken to be improving at an unknown rate.
+func (t *Trend) Estimate() (p Progress) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p.Plateau = -1
+	n := len(t.times)
+	if n < 4 {
+		p.Improving = true
+		return p
+	}
+	secs := make([]float64, n)
+	for i := range secs {
+		secs[i] = t.times[i].Sub(t.times[0]).Seconds()
+	}
+	best := smooth(t.best)
+	p.BestSlope = slope(secs, best)
+	p.MeanSlope = slope(secs, smooth(t.mean))
+	p.Improving = t.threshold < p.BestSlope
+	if !p.Improving {
+		p.Plateau = 0
+		return p
+	}
+
+	// extrapolate the decay of the slope from the older to the newer half
+	h := n / 2
+	older, newer := slope(secs[:h], best[:h]), slope(secs[n-h:], best[n-h:])
+	span := (secs[n-1] - secs[n-h]) // the duration of a half
+	if 0 < newer && newer < older && 0 < span {
+		halves := math.Log(t.threshold/newer) / math.Log(newer/older)
+		p.Plateau = time.Duration(math.Max(halves, 0) * span * float64(time.Second))
+	}
+	return p
+}
+
+// Condition returns a ConditionFn which observes the population each time it
+// is called, and is true once the window is full and the best fitness is no
+// longer improving.
+func (t *Trend) Condition(pop Population) ConditionFn {
+	return func() bool {
+		t.Observe(pop)
+		return t.Full() && !t.Estimate().Improving
+	}
+}
+
+// smooth returns the moving average of xs over a quarter of its length.
+func smooth(xs []float64) []float64 {
+	k := max(len(xs)/4, 1)
+	avg := make([]float64, len(xs))
+	var sum float64
+	for i, x := range xs {
+		sum += x
+		if k <= i {
+			sum -= xs[i-k]
+		}
+		avg[i] = sum / float64(min(i+1, k))
+	}
+	return avg
+}
+
+// slope returns the slope of the least-squares line through the points, or 0
+// if the xs are all equal.
+func slope(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var mx, my float64
+	for i := range xs {
+		mx += xs[i]
+		my += ys[i]
+	}
+	mx, my = mx/n, my/n
+	var sxy, sxx float64
+	for i := range xs {
+		sxy += (xs[i] - mx) * (ys[i] - my)
+		sxx += (xs[i] - mx) * (xs[i] - mx)
+	}
+	if sxx == 0 {
+		return 0
+	}
+	return sxy / sxx
+}
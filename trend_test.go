@@ -0,0 +1,66 @@
+package evo_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+func TestTrend(t *testing.T) {
+	start := time.Now()
+	trend := evo.NewTrend(20, 0.01)
+	if p := trend.Estimate(); !p.Improving || 0 <= p.Plateau {
+		t.Error("empty trend not improving at an unknown rate:", p)
+	}
+
+	// the best fitness converges to 1 as 1-2^-t
+	for i := 0; i < 20; i++ {
+		at := start.Add(time.Duration(i) * time.Second / 4)
+		best := 1 - math.Pow(2, -float64(i)/4)
+		trend.Put(at, best, best/2)
+	}
+	p := trend.Estimate()
+	if !trend.Full() || !p.Improving || p.BestSlope <= p.MeanSlope || p.Plateau <= 0 {
+		t.Error("converging run not improving:", p)
+	}
+	if len(p.Attrs()) != 4 {
+		t.Error("missing attributes:", p.Attrs())
+	}
+
+	// the best fitness plateaus
+	for i := 20; i < 40; i++ {
+		trend.Record(evo.Event{
+			Kind:  evo.GenerationDone,
+			Time:  start.Add(time.Duration(i) * time.Second),
+			Stats: evo.Stats{}.Put(1),
+		})
+	}
+	if p := trend.Estimate(); p.Improving || p.Plateau != 0 {
+		t.Error("plateaued run still improving:", p)
+	}
+}
+
+// fixed is a population whose statistics are set by the test.
+type fixed struct {
+	evo.Population
+	stats evo.Stats
+}
+
+func (p *fixed) Stats() evo.Stats { return p.stats }
+
+func TestTrendClock(t *testing.T) {
+	clock := evo.NewFakeClock(time.Unix(0, 0))
+	trend := evo.NewTrend(8, 0.5)
+	trend.SetClock(clock)
+	pop := new(fixed)
+	for i := 0; i < 8; i++ {
+		pop.stats = evo.Stats{}.Put(float64(i))
+		trend.Observe(pop)
+		clock.Advance(time.Second)
+	}
+	if p := trend.Estimate(); math.Abs(p.BestSlope-1) > 0.1 || !p.Improving {
+		t.Error("wrong slope:", p)
+	}
+}
@@ -0,0 +1,333 @@
+// Package tsplib loads traveling salesman problems in the TSPLIB format.
+//
+// TSPLIB is the standard library of benchmark instances for the symmetric and
+// asymmetric traveling salesman problems, maintained by Gerhard Reinelt:
+// http://comopt.ifi.uni-heidelberg.de/software/TSPLIB95/
+//
+// Instances with node coordinates are supported for the EUC_2D, CEIL_2D, ATT,
+// and GEO edge weight types, as are instances with explicit edge weights in
+// full or triangular matrix formats. Distances are computed exactly as
+// specified by TSPLIB, so known optimal tour lengths can be reproduced.
+// Problems and the edge weight types implement perm.TourCost, for use with the
+// tour helpers of the perm package.
+package tsplib
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Problem is a traveling salesman problem.
+type Problem struct {
+	Name      string
+	Comment   string
+	Type      string       // TSP or ATSP
+	Dimension int          // the number of cities
+	Weights   string       // the edge weight type, e.g. EUC_2D or EXPLICIT
+	Coords    [][2]float64 // the coordinates of the cities, if any
+
+	dist [][]float64 // the distance matrix
+}
+
+// Load reads a problem from a file.
+func Load(path string) (*Problem, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse reads a problem in the TSPLIB format.
+func Parse(r io.Reader) (*Problem, error) {
+	var (
+		p       = new(Problem)
+		format  string    // the edge weight format
+		weights []float64 // the explicit edge weights
+		section string    // the current data section
+	)
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if line == "EOF" {
+			break
+		}
+
+		// specification lines are KEY : VALUE
+		if key, val, ok := strings.Cut(line, ":"); ok {
+			key = strings.TrimSpace(key)
+			val = strings.TrimSpace(val)
+			section = ""
+			switch key {
+			case "NAME":
+				p.Name = val
+			case "COMMENT":
+				p.Comment = val
+			case "TYPE":
+				p.Type = val
+			case "DIMENSION":
+				n, err := strconv.Atoi(val)
+				if err != nil {
+					return nil, fmt.Errorf("tsplib: bad dimension %q", val)
+				}
+				p.Dimension = n
+			case "EDGE_WEIGHT_TYPE":
+				p.Weights = val
+			case "EDGE_WEIGHT_FORMAT":
+				format = val
+			}
+			continue
+		}
+
+		fields := strings.Fields(line)
+		switch fields[0] {
+		case "NODE_COORD_SECTION", "EDGE_WEIGHT_SECTION", "DISPLAY_DATA_SECTION", "TOUR_SECTION":
+			section = fields[0]
+			continue
+		}
+
+		nums := make([]float64, len(fields))
+		for i := range fields {
+			x, err := strconv.ParseFloat(fields[i], 64)
+			if err != nil {
+				return nil, fmt.Errorf("tsplib: bad number %q", fields[i])
+			}
+			nums[i] = x
+		}
+		switch section {
+		case "NODE_COORD_SECTION":
+			if len(nums) < 3 {
+				return nil, fmt.Errorf("tsplib: bad coordinates %q", line)
+			}
+			p.Coords = append(p.Coords, [2]float64{nums[1], nums[2]})
+		case "EDGE_WEIGHT_SECTION":
+			weights = append(weights, nums...)
+		case "":
+			return nil, fmt.Errorf("tsplib: unexpected line %q", line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if p.Dimension <= 0 {
+		return nil, fmt.Errorf("tsplib: missing dimension")
+	}
+	var err error
+	if p.Weights == "EXPLICIT" {
+		p.dist, err = explicit(p.Dimension, format, weights)
+	} else {
+		p.dist, err = coordinates(p.Dimension, p.Weights, p.Coords)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// explicit builds the distance matrix from explicit edge weights.
+func explicit(n int, format string, weights []float64) ([][]float64, error) {
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+	}
+
+	// each format lists the entries (i,j) in some order
+	var entries [][2]int
+	switch format {
+	case "FULL_MATRIX":
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				entries = append(entries, [2]int{i, j})
+			}
+		}
+	case "UPPER_ROW", "UPPER_DIAG_ROW":
+		diag := format == "UPPER_DIAG_ROW"
+		for i := 0; i < n; i++ {
+			for j := i; j < n; j++ {
+				if i != j || diag {
+					entries = append(entries, [2]int{i, j})
+				}
+			}
+		}
+	case "LOWER_ROW", "LOWER_DIAG_ROW":
+		diag := format == "LOWER_DIAG_ROW"
+		for i := 0; i < n; i++ {
+			for j := 0; j <= i; j++ {
+				if i != j || diag {
+					entries = append(entries, [2]int{i, j})
+				}
+			}
+		}
+	default:
+		return nil, fmt.Errorf("tsplib: unsupported edge weight format %q", format)
+	}
+
+	if len(weights) != len(entries) {
+		return nil, fmt.Errorf("tsplib: expected %d edge weights, got %d", len(entries), len(weights))
+	}
+	for k, e := range entries {
+		dist[e[0]][e[1]] = weights[k]
+		if format != "FULL_MATRIX" {
+			dist[e[1]][e[0]] = weights[k]
+		}
+	}
+	return dist, nil
+}
+
+// coordinates builds the distance matrix from node coordinates.
+func coordinates(n int, kind string, coords [][2]float64) ([][]float64, error) {
+	var metric func(a, b [2]float64) float64
+	switch kind {
+	case "EUC_2D":
+		metric = euc2d
+	case "CEIL_2D":
+		metric = ceil2d
+	case "ATT":
+		metric = att
+	case "GEO":
+		metric = geo
+	default:
+		return nil, fmt.Errorf("tsplib: unsupported edge weight type %q", kind)
+	}
+	if len(coords) != n {
+		return nil, fmt.Errorf("tsplib: expected %d coordinates, got %d", n, len(coords))
+	}
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		for j := range dist[i] {
+			if i != j {
+				dist[i][j] = metric(coords[i], coords[j])
+			}
+		}
+	}
+	return dist, nil
+}
+
+// nint rounds to the nearest integer.
+func nint(x float64) float64 {
+	return math.Floor(x + 0.5)
+}
+
+func euc2d(a, b [2]float64) float64 {
+	return nint(math.Hypot(a[0]-b[0], a[1]-b[1]))
+}
+
+func ceil2d(a, b [2]float64) float64 {
+	return math.Ceil(math.Hypot(a[0]-b[0], a[1]-b[1]))
+}
+
+// att is the pseudo-Euclidean distance.
+func att(a, b [2]float64) float64 {
+	xd, yd := a[0]-b[0], a[1]-b[1]
+	r := math.Sqrt((xd*xd + yd*yd) / 10)
+	t := nint(r)
+	if t < r {
+		t++
+	}
+	return t
+}
+
+// geo is the geographical distance, with coordinates given as DDD.MM degrees
+// and minutes of latitude and longitude.
+func geo(a, b [2]float64) float64 {
+	const pi, radius = 3.141592, 6378.388
+	rad := func(x float64) float64 {
+		deg := math.Trunc(x)
+		min := x - deg
+		return pi * (deg + 5*min/3) / 180
+	}
+	lata, longa := rad(a[0]), rad(a[1])
+	latb, longb := rad(b[0]), rad(b[1])
+	q1 := math.Cos(longa - longb)
+	q2 := math.Cos(lata - latb)
+	q3 := math.Cos(lata + latb)
+	return math.Trunc(radius*math.Acos(0.5*((1+q1)*q2-(1-q1)*q3)) + 1)
+}
+
+// Dist returns the distance from city i to city j.
+func (p *Problem) Dist(i, j int) float64 {
+	return p.dist[i][j]
+}
+
+// Cost returns the distance from city i to city j, implementing perm.TourCost.
+func (p *Problem) Cost(i, j int) float64 {
+	return p.dist[i][j]
+}
+
+// The edge weight types of TSPLIB, as costs between cities at coordinates,
+// implementing perm.TourCost. Unlike a Problem, they compute each distance on
+// demand rather than storing a matrix, which suits large instances.
+type (
+	Euclidean [][2]float64 // EUC_2D, rounded to the nearest integer
+	Ceil      [][2]float64 // CEIL_2D, rounded up
+	ATT       [][2]float64 // ATT, the pseudo-Euclidean distance
+	Geo       [][2]float64 // GEO, given DDD.MM latitudes and longitudes
+)
+
+// Cost returns the distance from city i to city j.
+func (c Euclidean) Cost(i, j int) float64 { return euc2d(c[i], c[j]) }
+
+// Cost returns the distance from city i to city j.
+func (c Ceil) Cost(i, j int) float64 { return ceil2d(c[i], c[j]) }
+
+// Cost returns the distance from city i to city j.
+func (c ATT) Cost(i, j int) float64 { return att(c[i], c[j]) }
+
+// Cost returns the distance from city i to city j.
+func (c Geo) Cost(i, j int) float64 { return geo(c[i], c[j]) }
+
+// Length returns the length of a tour, given as a permutation of the cities.
+func (p *Problem) Length(tour []int) (length float64) {
+	for i := range tour {
+		length += p.dist[tour[i]][tour[(i+1)%len(tour)]]
+	}
+	return length
+}
+
+// A Tour is a genome for a traveling salesman problem. The gene is a
+// permutation of the cities, and the fitness is the negative length of the
+// tour, computed once.
+type Tour struct {
+	evo.Cache
+	Gene    []int
+	Problem *Problem
+}
+
+// New returns a genome for the given tour.
+func (p *Problem) New(gene []int) *Tour {
+	return &Tour{Gene: gene, Problem: p}
+}
+
+// Random returns a genome for a random tour.
+func (p *Problem) Random() *Tour {
+	return p.New(rand.Perm(p.Dimension))
+}
+
+// Fitness returns the negative length of the tour.
+func (t *Tour) Fitness() float64 {
+	return t.Cache.Fitness(func() float64 {
+		return -t.Problem.Length(t.Gene)
+	})
+}
+
+// Alleles returns the city at each position of the tour, implementing
+// evo.Discrete. Tours are invariant to rotation, so the positional entropy of a
+// population overstates its diversity unless tours start at a fixed city.
+func (t *Tour) Alleles() []int {
+	return t.Gene
+}
@@ -0,0 +1,136 @@
+package tsplib_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/cbarrick/evo/perm"
+	"github.com/cbarrick/evo/tsplib"
+)
+
+func TestCoords(t *testing.T) {
+	p, err := tsplib.Parse(strings.NewReader(`NAME : square
+TYPE : TSP
+DIMENSION : 4
+EDGE_WEIGHT_TYPE : EUC_2D
+NODE_COORD_SECTION
+1 0 0
+2 0 3
+3 4 3
+4 4 0
+EOF
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Name != "square" || p.Dist(0, 2) != 5 || p.Length([]int{0, 1, 2, 3}) != 14 {
+		t.Fail()
+	}
+	if p.New([]int{0, 2, 1, 3}).Fitness() != -18 {
+		t.Fail()
+	}
+}
+
+func TestATT(t *testing.T) {
+	p, err := tsplib.Parse(strings.NewReader(`DIMENSION: 2
+EDGE_WEIGHT_TYPE: ATT
+NODE_COORD_SECTION
+1 6734 1453
+2 2233 10
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Dist(0, 1) != 1495 {
+		t.Fail()
+	}
+}
+
+func TestGEO(t *testing.T) {
+	// the first two cities of burma14
+	p, err := tsplib.Parse(strings.NewReader(`DIMENSION: 2
+EDGE_WEIGHT_TYPE: GEO
+NODE_COORD_SECTION
+1 16.47 96.10
+2 16.47 94.44
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Dist(0, 1) != 153 {
+		t.Fail()
+	}
+}
+
+func TestExplicit(t *testing.T) {
+	p, err := tsplib.Parse(strings.NewReader(`TYPE: TSP
+DIMENSION: 3
+EDGE_WEIGHT_TYPE: EXPLICIT
+EDGE_WEIGHT_FORMAT: UPPER_ROW
+EDGE_WEIGHT_SECTION
+1 2
+3
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Dist(0, 1) != 1 || p.Dist(2, 0) != 2 || p.Dist(1, 2) != 3 {
+		t.Fail()
+	}
+
+	p, err = tsplib.Parse(strings.NewReader(`TYPE: ATSP
+DIMENSION: 2
+EDGE_WEIGHT_TYPE: EXPLICIT
+EDGE_WEIGHT_FORMAT: FULL_MATRIX
+EDGE_WEIGHT_SECTION
+0 5
+7 0
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if p.Dist(0, 1) != 5 || p.Dist(1, 0) != 7 {
+		t.Fail()
+	}
+
+	_, err = tsplib.Parse(strings.NewReader(`DIMENSION: 3
+EDGE_WEIGHT_TYPE: EXPLICIT
+EDGE_WEIGHT_FORMAT: UPPER_ROW
+EDGE_WEIGHT_SECTION
+1 2
+`))
+	if err == nil {
+		t.Fail()
+	}
+}
+
+func TestCost(t *testing.T) {
+	coords := [][2]float64{{6734, 1453}, {2233, 10}, {5530, 1424}}
+	for _, kind := range []string{"EUC_2D", "CEIL_2D", "ATT", "GEO"} {
+		var b strings.Builder
+		b.WriteString("DIMENSION: 3\nEDGE_WEIGHT_TYPE: " + kind + "\nNODE_COORD_SECTION\n")
+		for i, c := range coords {
+			fmt.Fprintf(&b, "%d %v %v\n", i+1, c[0], c[1])
+		}
+		p, err := tsplib.Parse(strings.NewReader(b.String()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var cost perm.TourCost
+		switch kind {
+		case "EUC_2D":
+			cost = tsplib.Euclidean(coords)
+		case "CEIL_2D":
+			cost = tsplib.Ceil(coords)
+		case "ATT":
+			cost = tsplib.ATT(coords)
+		case "GEO":
+			cost = tsplib.Geo(coords)
+		}
+		tour := []int{0, 1, 2}
+		if perm.TourLength(cost, tour) != p.Length(tour) || perm.TourLength(p, tour) != p.Length(tour) {
+			t.Errorf("%s: costs disagree with the problem", kind)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package tune recommends population sizes and concurrency from measured
+// throughput.
+//
+// Populations evolve each member in its own goroutine, so whether a run keeps
+// the cores of the machine busy depends on the cost of the EvolveFn, the size
+// of the population, and the synchronization between generations. A Tuner
+// measures the time spent in the EvolveFn against the time available on the
+// cores, and recommends a population size which saturates them, or fewer
+// cores when the calls are too cheap to be worth scheduling in parallel:
+//
+//	tuner := tune.New(16, 4096)
+//	pop.Evolve(seed, tuner.Wrap(body))
+//	stop := tuner.Run(time.Second, func() int { return len(seed) }, func(d tune.Decision) {
+//		runtime.GOMAXPROCS(d.Procs)
+//		log.Println(d)
+//	})
+//	defer stop()
+//
+// Each decision is published to subscribers as an evo.Tuned event.
+package tune
+
+import (
+	"fmt"
+	"math"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/cbarrick/evo"
+)
+
+// A Measurement summarizes the use of the cores by an EvolveFn over a period.
+type Measurement struct {
+	evo.Throughput
+	Busy  time.Duration // total time spent in the EvolveFn, by all goroutines
+	Procs int           // the number of cores available, as by GOMAXPROCS
+}
+
+// Utilization returns the fraction of the time available on the cores which was
+// spent in the EvolveFn.
+func (m Measurement) Utilization() float64 {
+	capacity := m.Elapsed.Seconds() * float64(m.Procs)
+	if capacity <= 0 {
+		return 0
+	}
+	return math.Min(m.Busy.Seconds()/capacity, 1)
+}
+
+// Cost returns the mean time spent in each call to the EvolveFn.
+func (m Measurement) Cost() time.Duration {
+	if m.Iterations == 0 {
+		return 0
+	}
+	return m.Busy / time.Duration(m.Iterations)
+}
+
+// Overhead returns the mean time the cores spent outside the EvolveFn for each
+// call, i.e. idle or scheduling and synchronizing the goroutines.
+func (m Measurement) Overhead() time.Duration {
+	if m.Iterations == 0 {
+		return 0
+	}
+	capacity := time.Duration(float64(m.Elapsed) * float64(m.Procs))
+	if capacity < m.Busy {
+		return 0
+	}
+	return (capacity - m.Busy) / time.Duration(m.Iterations)
+}
+
+// A Decision is a recommendation of a Tuner.
+type Decision struct {
+	Measurement
+	Size  int    // the recommended population size
+	Procs int    // the recommended number of cores
+	Why   string // the reason for the recommendation
+}
+
+// String describes the decision.
+func (d Decision) String() string {
+	return fmt.Sprintf("size %d, %d procs: %s (%.0f%% utilization, %.0f evals/s, %v per call, %v overhead)",
+		d.Size, d.Procs, d.Why, 100*d.Utilization(), d.EvalsPerSec(), d.Cost(), d.Overhead())
+}
+
+// A Tuner measures the throughput of an EvolveFn and recommends the size of the
+// population and the number of cores. Tuners are safe for concurrent use.
+type Tuner struct {
+	Min, Max int       // the bounds of the recommended size
+	Target   float64   // the target utilization of the cores, 0.9 if 0
+	Clock    evo.Clock // measures the period of Run, evo.SystemClock if nil
+
+	calls  atomic.Int64
+	evals  atomic.Int64 // genomes returned, each evaluated by the population
+	busy   atomic.Int64 // nanoseconds
+	events evo.Events
+
+	mu    sync.Mutex
+	start time.Time // the start of the current measurement
+	last  Decision
+}
+
+// New returns a tuner which recommends sizes between min and max.
+func New(min, max int) *Tuner {
+	t := &Tuner{Min: min, Max: max}
+	t.reset()
+	return t
+}
+
+// Subscribe registers a channel to receive an evo.Tuned event for each decision
+// of Run, with the decision described by the Note of the event. Events are
+// dropped when the channel is full.
+func (t *Tuner) Subscribe(ch chan<- evo.Event) {
+	t.events.Subscribe(ch)
+}
+
+// Wrap returns an EvolveFn which evolves with body and measures it.
+func (t *Tuner) Wrap(body evo.EvolveFn) evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		start := time.Now()
+		next := body(current, suitors)
+		t.busy.Add(int64(time.Since(start)))
+		t.calls.Add(1)
+		if next != nil {
+			t.evals.Add(1)
+		}
+		return next
+	}
+}
+
+// Measure returns the measurement since the last call to Measure or Recommend,
+// or since the tuner was created, and starts a new one.
+func (t *Tuner) Measure() Measurement {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	m := Measurement{
+		Throughput: evo.Throughput{
+			Evaluations: int(t.evals.Swap(0)),
+			Iterations:  int(t.calls.Swap(0)),
+			Elapsed:     time.Since(t.start),
+		},
+		Busy:  time.Duration(t.busy.Swap(0)),
+		Procs: runtime.GOMAXPROCS(0),
+	}
+	t.reset()
+	return m
+}
+
+// reset starts a new measurement.
+func (t *Tuner) reset() {
+	t.start = time.Now()
+}
+
+// Recommend measures the EvolveFn and recommends a size for a population
+// currently of the given size, and a number of cores:
+//
+// When each call costs less than its overhead, the goroutines cost more than
+// they gain, so the tuner recommends only as many cores as were busy, keeping
+// the size. Otherwise, when the utilization is below the target, the cores are
+// starved between generations, so the tuner recommends all the cores of the
+// machine and grows the size in proportion to the shortfall, rounded up to a
+// multiple of the cores. At or above the target, the size is kept, since a
+// larger population would only slow each generation. The size is clamped to
+// the bounds of the tuner.
+func (t *Tuner) Recommend(size int) Decision {
+	m := t.Measure()
+	target := t.Target
+	if target == 0 {
+		target = 0.9
+	}
+	d := Decision{Measurement: m, Size: size, Procs: m.Procs}
+	u := m.Utilization()
+	switch {
+	case m.Iterations == 0:
+		d.Why = "no calls measured"
+	case m.Cost() < m.Overhead():
+		d.Procs = max(1, int(math.Ceil(u*float64(m.Procs))))
+		d.Why = "calls are cheaper than their overhead"
+	case u < target:
+		d.Procs = runtime.NumCPU()
+		grown := int(math.Ceil(float64(size) * target / math.Max(u, 1e-3)))
+		d.Size = (grown + d.Procs - 1) / d.Procs * d.Procs
+		d.Why = "cores are underused"
+	default:
+		d.Why = "cores are saturated"
+	}
+	if 0 < t.Max && t.Max < d.Size {
+		d.Size = t.Max
+	}
+	if d.Size < t.Min {
+		d.Size = t.Min
+	}
+
+	t.mu.Lock()
+	t.last = d
+	t.mu.Unlock()
+	return d
+}
+
+// Last returns the last decision of the tuner.
+func (t *Tuner) Last() Decision {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.last
+}
+
+// Run makes a recommendation at the given frequency for a population whose
+// current size is given by size, publishes it as an evo.Tuned event, and passes
+// it to apply, which may adjust the population, e.g. through GOMAXPROCS or a
+// restart with a new size; apply may be nil to only report decisions. The
+// returned function stops the tuner.
+func (t *Tuner) Run(freq time.Duration, size func() int, apply func(Decision)) (stop func()) {
+	clock := t.Clock
+	if clock == nil {
+		clock = evo.SystemClock
+	}
+	t.Measure()
+	quit := make(chan struct{})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-clock.After(freq):
+				d := t.Recommend(size())
+				t.events.Publish(evo.Event{Kind: evo.Tuned, Note: d.String()})
+				if apply != nil {
+					apply(d)
+				}
+			case <-quit:
+				return
+			}
+		}
+	}()
+	return func() {
+		close(quit)
+		<-done
+	}
+}
@@ -0,0 +1,82 @@
+package tune_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/tune"
+)
+
+type num float64
+
+func (n num) Fitness() float64 { return float64(n) }
+
+func TestMeasurement(t *testing.T) {
+	m := tune.Measurement{
+		Throughput: evo.Throughput{Iterations: 100, Elapsed: time.Second},
+		Busy:       2 * time.Second,
+		Procs:      4,
+	}
+	if u := m.Utilization(); u != 0.5 {
+		t.Errorf("utilization is %v, want 0.5", u)
+	}
+	if c := m.Cost(); c != 20*time.Millisecond {
+		t.Errorf("cost is %v, want 20ms", c)
+	}
+	if o := m.Overhead(); o != 20*time.Millisecond {
+		t.Errorf("overhead is %v, want 20ms", o)
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	tuner := tune.New(4, 64)
+	body := tuner.Wrap(func(current evo.Genome, _ []evo.Genome) evo.Genome {
+		time.Sleep(5 * time.Millisecond)
+		return current
+	})
+
+	// a single slow call at a time leaves the other cores idle
+	for i := 0; i < 4; i++ {
+		body(num(0), nil)
+		time.Sleep(5 * time.Millisecond)
+	}
+	d := tuner.Recommend(8)
+	if d.Iterations != 4 {
+		t.Errorf("measured %d calls, want 4", d.Iterations)
+	}
+	if d.Why != "cores are underused" && d.Why != "calls are cheaper than their overhead" {
+		t.Errorf("unexpected reason %q", d.Why)
+	}
+	if d.Size < 4 || 64 < d.Size {
+		t.Errorf("size %d is out of bounds", d.Size)
+	}
+	if tuner.Last().Size != d.Size {
+		t.Error("last decision was not recorded")
+	}
+
+	if d := tuner.Recommend(8); d.Why != "no calls measured" || d.Size != 8 {
+		t.Errorf("without calls, got size %d for %q", d.Size, d.Why)
+	}
+}
+
+func TestRun(t *testing.T) {
+	clock := evo.NewFakeClock(time.Now())
+	tuner := tune.New(1, 100)
+	tuner.Clock = clock
+	events := make(chan evo.Event, 1)
+	tuner.Subscribe(events)
+	decisions := make(chan tune.Decision, 1)
+	stop := tuner.Run(time.Second, func() int { return 10 }, func(d tune.Decision) {
+		decisions <- d
+	})
+	defer stop()
+
+	clock.BlockUntil(1)
+	clock.Advance(time.Second)
+	d := <-decisions
+	ev := <-events
+	if ev.Kind != evo.Tuned || ev.Note != d.String() {
+		t.Errorf("got event %v %q, want Tuned %q", ev.Kind, ev.Note, d.String())
+	}
+}
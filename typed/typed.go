@@ -0,0 +1,119 @@
+// Package typed provides a type-safe API over populations of a single genome
+// type.
+//
+// Populations are shared by every representation, so an evo.EvolveFn receives
+// its genomes as evo.Genome values and must assert them to the representation
+// of the problem. A typed EvolveFn receives them as the type of the genomes
+// instead, and the compiler rejects bodies and seeds of the wrong type:
+//
+//	pop := typed.New[*tour](new(gen.Population))
+//	pop.Evolve(seed, func(current *tour, suitors []*tour) *tour {
+//		child := &tour{gene: make([]int, dim)}
+//		perm.PMX(child.gene, suitors[0].gene, suitors[1].gene)
+//		return child
+//	})
+//
+// The adapters between typed and untyped functions, Untyped and Of, allow typed
+// bodies to be given to any population, and untyped operators, such as those
+// of package op, to be used where typed ones are expected. Since populations
+// are genomes of a different type than their members, island models remain
+// untyped between islands, but each island may be typed.
+package typed
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/cbarrick/evo"
+)
+
+// An EvolveFn is an evo.EvolveFn for genomes of type G.
+type EvolveFn[G evo.Genome] func(current G, suitors []G) (replacement G)
+
+// Untyped adapts the function to an evo.EvolveFn. The suitors are copied into a
+// new slice for each call. The returned function panics if it receives a genome
+// which is not a G.
+func (fn EvolveFn[G]) Untyped() evo.EvolveFn {
+	return func(current evo.Genome, suitors []evo.Genome) evo.Genome {
+		return fn(as[G](current), Members[G](suitors))
+	}
+}
+
+// Of adapts an evo.EvolveFn to genomes of type G. The returned function panics
+// if fn returns a genome which is not a G.
+func Of[G evo.Genome](fn evo.EvolveFn) EvolveFn[G] {
+	return func(current G, suitors []G) G {
+		return as[G](fn(current, Genomes(suitors)))
+	}
+}
+
+// Genomes returns the members as a slice of evo.Genome.
+func Genomes[G evo.Genome](members []G) []evo.Genome {
+	genomes := make([]evo.Genome, len(members))
+	for i, g := range members {
+		genomes[i] = g
+	}
+	return genomes
+}
+
+// Members returns the genomes as a slice of G. It panics if a genome is not a G.
+func Members[G evo.Genome](genomes []evo.Genome) []G {
+	members := make([]G, len(genomes))
+	for i, g := range genomes {
+		members[i] = as[G](g)
+	}
+	return members
+}
+
+// as asserts that the genome is a G, with a descriptive panic otherwise.
+func as[G evo.Genome](g evo.Genome) G {
+	val, ok := g.(G)
+	if !ok {
+		panic(fmt.Sprintf("typed: genome of type %T is not a %v", g, reflect.TypeFor[G]()))
+	}
+	return val
+}
+
+// A Population is a population of genomes of type G. It wraps an untyped
+// population, which remains accessible through the embedded field, e.g. to
+// configure it or to use it as an island.
+type Population[G evo.Genome] struct {
+	evo.Population
+}
+
+// New returns a typed population wrapping pop.
+func New[G evo.Genome](pop evo.Population) Population[G] {
+	return Population[G]{pop}
+}
+
+// Evolve starts the evolution of the members with the body in a separate
+// goroutine. The members are copied into the untyped population, so the slice
+// is not evolved in place; use Members to read the current members.
+func (p Population[G]) Evolve(members []G, body EvolveFn[G]) {
+	p.Population.Evolve(Genomes(members), body.Untyped())
+}
+
+// Members returns a snapshot of the members of the population.
+func (p Population[G]) Members() []G {
+	v := p.View()
+	defer v.Close()
+	return Members[G](v.Members())
+}
+
+// Best returns the most fit genome evolved so far, if the underlying population
+// tracks it, or the most fit current member otherwise. The result is false if
+// the population has no members.
+func (p Population[G]) Best() (best G, ok bool) {
+	if t, ok := p.Population.(interface{ Best() evo.Record }); ok {
+		if r := t.Best(); r.Genome != nil {
+			return as[G](r.Genome), true
+		}
+	}
+	v := p.View()
+	defer v.Close()
+	top := v.TopK(1)
+	if len(top) == 0 {
+		return best, false
+	}
+	return as[G](top[0]), true
+}
@@ -0,0 +1,77 @@
+package typed_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+	"github.com/cbarrick/evo/typed"
+)
+
+type num float64
+
+func (n num) Fitness() float64 { return float64(n) }
+
+type other struct{}
+
+func (other) Fitness() float64 { return 0 }
+
+// best converges to the best suitor
+func best(current num, suitors []num) num {
+	b := current
+	for _, s := range suitors {
+		if b < s {
+			b = s
+		}
+	}
+	return b
+}
+
+func TestPopulation(t *testing.T) {
+	seed := make([]num, 16)
+	for i := range seed {
+		seed[i] = num(i)
+	}
+	pop := typed.New[num](new(gen.Population))
+	pop.Evolve(seed, best)
+	pop.Poll(0, func() bool { return pop.Stats().Min() == 15 })
+	select {
+	case <-time.After(5 * time.Second):
+		pop.Stop()
+		t.Fatal("population did not converge")
+	case <-waitc(pop):
+	}
+
+	for _, m := range pop.Members() {
+		if m != 15 {
+			t.Errorf("member %v did not converge to 15", m)
+		}
+	}
+	if b, ok := pop.Best(); !ok || b != 15 {
+		t.Errorf("best is %v, %v; want 15, true", b, ok)
+	}
+}
+
+func waitc(pop typed.Population[num]) <-chan struct{} {
+	ch := make(chan struct{})
+	go func() {
+		pop.Wait()
+		close(ch)
+	}()
+	return ch
+}
+
+func TestAdapters(t *testing.T) {
+	body := typed.Of[num](typed.EvolveFn[num](best).Untyped())
+	if got := body(1, []num{3, 2}); got != 3 {
+		t.Errorf("got %v, want 3", got)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("mismatched genome did not panic")
+		}
+	}()
+	typed.EvolveFn[num](best).Untyped()(num(1), []evo.Genome{other{}})
+}
@@ -0,0 +1,342 @@
+package evo
+
+import (
+	"container/heap"
+	"math"
+	"sort"
+	"sync"
+)
+
+// A Differ is a genome which can measure how different it is from another
+// genome. Populations of Differs report diversity statistics through views.
+type Differ interface {
+	Genome
+
+	// Difference returns a non-negative distance to the other genome. The
+	// difference between identical genomes must be 0.
+	Difference(other Genome) float64
+}
+
+// A Discrete genome is a fixed-length string of discrete alleles, e.g. a bit
+// string, a vector of integers, or a permutation read as the element at each
+// position. Populations of Discrete genomes report per-locus statistics
+// through views.
+type Discrete interface {
+	Genome
+
+	// Alleles returns the allele at each locus. The slice must not be
+	// modified.
+	Alleles() []int
+}
+
+// A View is a snapshot of the members of a population. A common source of
+// views is the return value of Population.View().
+type View struct {
+	members []Genome
+	buf     *[]Genome // the pooled backing storage of members
+}
+
+// viewPool recycles the backing storage of closed views. Populations take a
+// view whenever statistics are polled, so recycling keeps tight termination
+// loops from allocating a new slice every few milliseconds.
+var viewPool sync.Pool
+
+// NewView returns a view of the given members. The slice is copied.
+func NewView(members []Genome) View {
+	v := BuildView(len(members), nil)
+	copy(v.members, members)
+	return v
+}
+
+// BuildView returns a view of n members, where the ith member is given by
+// member(i). If member is nil, the members are left nil for the caller to
+// fill through Members. Populations use BuildView to construct views without
+// an intermediate slice.
+func BuildView(n int, member func(i int) Genome) View {
+	buf, _ := viewPool.Get().(*[]Genome)
+	if buf == nil {
+		buf = new([]Genome)
+	}
+	if cap(*buf) < n {
+		*buf = make([]Genome, n)
+	}
+	*buf = (*buf)[:n]
+	if member != nil {
+		for i := range *buf {
+			(*buf)[i] = member(i)
+		}
+	}
+	return View{members: *buf, buf: buf}
+}
+
+// Members returns the members of the view. The slice must not be modified,
+// except to fill the members of a view from BuildView, and must not be retained
+// after the view is closed.
+func (v View) Members() []Genome {
+	return v.members
+}
+
+// Len returns the number of members in the view.
+func (v View) Len() int {
+	return len(v.members)
+}
+
+// Stats returns statistics on the fitness of the members.
+func (v View) Stats() (s Stats) {
+	for i := range v.members {
+		s = s.Put(v.members[i].Fitness())
+	}
+	return s
+}
+
+// Subviews returns a view of each member which is itself a population, in the
+// order of the members, e.g. the islands of an island model. Other members are
+// skipped. Each subview is taken from its population when Subviews is called,
+// and should be closed by the caller.
+func (v View) Subviews() []View {
+	var subs []View
+	for _, g := range v.members {
+		if pop, ok := g.(Population); ok {
+			subs = append(subs, pop.View())
+		}
+	}
+	return subs
+}
+
+// SubStats returns the statistics of each member which is itself a population,
+// in the order of Subviews, e.g. the islands of an island model. Other members
+// are skipped. Each is given by the Stats method of the population, tagged with
+// its generation, so the breakdown shows which islands lead and which have
+// stopped making progress, unlike the Stats of the view, which only sees the
+// fitness of each island.
+func (v View) SubStats() []Stats {
+	var stats []Stats
+	for _, g := range v.members {
+		if pop, ok := g.(Population); ok {
+			stats = append(stats, pop.Stats())
+		}
+	}
+	return stats
+}
+
+// Flatten returns a view of the leaves of a hierarchy of populations: members
+// which are themselves populations are replaced by the members of their views,
+// recursively. Statistics of the flattened view describe the individuals of
+// all sub-populations, rather than the best of each.
+func (v View) Flatten() View {
+	flat := BuildView(0, nil)
+	*flat.buf = flatten(*flat.buf, v.members)
+	flat.members = *flat.buf
+	return flat
+}
+
+// flatten appends the leaves of the members to buf.
+func flatten(buf, members []Genome) []Genome {
+	for _, g := range members {
+		pop, ok := g.(Population)
+		if !ok {
+			buf = append(buf, g)
+			continue
+		}
+		sub := pop.View()
+		buf = flatten(buf, sub.members)
+		sub.Close()
+	}
+	return buf
+}
+
+// SortByFitness returns the members of the view sorted from most to least fit.
+// Members of equal fitness keep their order in the view. The returned slice is
+// new and may be modified by the caller.
+func (v View) SortByFitness() []Genome {
+	ranked := rank(v.members)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return ranked[i].fit > ranked[j].fit
+	})
+	sorted := make([]Genome, len(ranked))
+	for i := range ranked {
+		sorted[i] = ranked[i].Genome
+	}
+	return sorted
+}
+
+// TopK returns the k most fit members of the view, from most to least fit. If
+// k is at least the size of the view, every member is returned. The cost is
+// O(n log k), cheaper than sorting the whole view when k is small. The returned
+// slice is new and may be modified by the caller.
+func (v View) TopK(k int) []Genome {
+	if len(v.members) <= k {
+		return v.SortByFitness()
+	}
+	if k <= 0 {
+		return nil
+	}
+
+	// keep the best k in a min-heap, so the least fit of them is at the root
+	h := make(minHeap, 0, k)
+	for _, r := range rank(v.members) {
+		if len(h) < k {
+			heap.Push(&h, r)
+		} else if h[0].less(r) {
+			h[0] = r
+			heap.Fix(&h, 0)
+		}
+	}
+	top := make([]Genome, k)
+	for i := k - 1; 0 <= i; i-- {
+		top[i] = heap.Pop(&h).(ranked).Genome
+	}
+	return top
+}
+
+// ranked is a genome paired with its fitness and position in a view.
+type ranked struct {
+	Genome
+	fit float64
+	idx int
+}
+
+// less orders ranked genomes by fitness, breaking ties in favor of earlier
+// positions.
+func (r ranked) less(s ranked) bool {
+	if r.fit != s.fit {
+		return r.fit < s.fit
+	}
+	return r.idx > s.idx
+}
+
+// rank computes the fitness of each member once.
+func rank(members []Genome) []ranked {
+	r := make([]ranked, len(members))
+	for i, g := range members {
+		r[i] = ranked{g, g.Fitness(), i}
+	}
+	return r
+}
+
+// minHeap implements heap.Interface for ranked genomes, least fit first.
+type minHeap []ranked
+
+func (h minHeap) Len() int            { return len(h) }
+func (h minHeap) Less(i, j int) bool  { return h[i].less(h[j]) }
+func (h minHeap) Swap(i, j int)       { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x interface{}) { *h = append(*h, x.(ranked)) }
+func (h *minHeap) Pop() interface{} {
+	old := *h
+	x := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return x
+}
+
+// Diversity returns statistics on the pairwise differences between members,
+// e.g. the mean pairwise distance is given by Diversity().Mean(). Members that
+// do not implement Differ are ignored. The cost is quadratic in the size of
+// the view.
+func (v View) Diversity() (s Stats) {
+	for i := range v.members {
+		a, ok := v.members[i].(Differ)
+		if !ok {
+			continue
+		}
+		for j := i + 1; j < len(v.members); j++ {
+			if _, ok := v.members[j].(Differ); ok {
+				s = s.Put(a.Difference(v.members[j]))
+			}
+		}
+	}
+	return s
+}
+
+// Entropy returns the Shannon entropy, in bits, of the distribution of
+// distinct genomes in the view. Two members are the same genome if their
+// difference is 0. The entropy is 0 when the population has converged to a
+// single genome and log2(n) when all n members are distinct. Members that do
+// not implement Differ are each counted as distinct.
+func (v View) Entropy() (h float64) {
+	var (
+		reps   []Genome // one representative per class of identical genomes
+		counts []float64
+	)
+	for _, g := range v.members {
+		found := false
+		if d, ok := g.(Differ); ok {
+			for i, r := range reps {
+				if _, ok := r.(Differ); ok && d.Difference(r) == 0 {
+					counts[i]++
+					found = true
+					break
+				}
+			}
+		}
+		if !found {
+			reps = append(reps, g)
+			counts = append(counts, 1)
+		}
+	}
+	n := float64(len(v.members))
+	for _, c := range counts {
+		p := c / n
+		h -= p * math.Log2(p)
+	}
+	return h
+}
+
+// Frequencies returns the frequency of each allele at each locus, i.e. the
+// fraction of members carrying the allele at that position. Members that do
+// not implement Discrete are ignored. For genomes of differing lengths, the
+// frequencies at a locus are over the members long enough to have it.
+func (v View) Frequencies() []map[int]float64 {
+	var (
+		freqs []map[int]float64
+		n     []float64 // the number of members with each locus
+	)
+	for _, g := range v.members {
+		d, ok := g.(Discrete)
+		if !ok {
+			continue
+		}
+		for i, a := range d.Alleles() {
+			if len(freqs) <= i {
+				freqs = append(freqs, make(map[int]float64))
+				n = append(n, 0)
+			}
+			freqs[i][a]++
+			n[i]++
+		}
+	}
+	for i := range freqs {
+		for a := range freqs[i] {
+			freqs[i][a] /= n[i]
+		}
+	}
+	return freqs
+}
+
+// LocusEntropy returns the Shannon entropy, in bits, of the distribution of
+// alleles at each locus, e.g. the positional entropy of the cities of a tour.
+// A locus where the population has converged has entropy 0. Unlike the
+// entropy of whole genomes, the entropy of the loci measures convergence even
+// while no two members are identical. Members that do not implement Discrete
+// are ignored.
+func (v View) LocusEntropy() []float64 {
+	freqs := v.Frequencies()
+	h := make([]float64, len(freqs))
+	for i := range freqs {
+		for _, p := range freqs[i] {
+			h[i] -= p * math.Log2(p)
+		}
+	}
+	return h
+}
+
+// Close releases the view. Closing a view is optional, but allows its memory to
+// be reused by later views. A view must be closed at most once, and neither it
+// nor any copy of it may be used after Close.
+func (v View) Close() {
+	if v.buf == nil {
+		return
+	}
+	// drop the references to the members so they can be collected
+	clear(*v.buf)
+	viewPool.Put(v.buf)
+}
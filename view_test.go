@@ -0,0 +1,153 @@
+package evo_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/cbarrick/evo"
+	"github.com/cbarrick/evo/pop/gen"
+)
+
+type point float64
+
+func (p point) Fitness() float64 { return float64(p) }
+
+func (p point) Difference(q evo.Genome) float64 {
+	return math.Abs(float64(p - q.(point)))
+}
+
+func TestView(t *testing.T) {
+	members := []evo.Genome{point(0), point(0), point(1), point(3)}
+	view := evo.NewView(members)
+	members[0] = point(10)
+	if view.Len() != 4 || view.Members()[0] != point(0) {
+		t.Fail()
+	}
+	if view.Stats().Max() != 3 {
+		t.Fail()
+	}
+	if div := view.Diversity(); div.Count() != 6 || math.Abs(div.Mean()-10.0/6) > 1e-9 {
+		t.Fail()
+	}
+	if view.Entropy() != 1.5 {
+		t.Fail()
+	}
+	if evo.NewView([]evo.Genome{point(1), point(1)}).Entropy() != 0 {
+		t.Fail()
+	}
+}
+
+func TestSortByFitness(t *testing.T) {
+	view := evo.NewView([]evo.Genome{point(2), point(5), point(1), point(5), point(3)})
+	sorted := view.SortByFitness()
+	want := []evo.Genome{point(5), point(5), point(3), point(2), point(1)}
+	for i := range want {
+		if sorted[i] != want[i] {
+			t.Fatalf("got %v, want %v", sorted, want)
+		}
+	}
+	if view.Members()[0] != point(2) {
+		t.Error("view was modified")
+	}
+}
+
+func TestTopK(t *testing.T) {
+	members := make([]evo.Genome, 100)
+	for i := range members {
+		members[i] = point((i * 37) % 100)
+	}
+	view := evo.NewView(members)
+	top := view.TopK(5)
+	want := []evo.Genome{point(99), point(98), point(97), point(96), point(95)}
+	if len(top) != len(want) {
+		t.Fatalf("got %v, want %v", top, want)
+	}
+	for i := range want {
+		if top[i] != want[i] {
+			t.Fatalf("got %v, want %v", top, want)
+		}
+	}
+	if len(view.TopK(0)) != 0 || len(view.TopK(200)) != 100 {
+		t.Fail()
+	}
+}
+
+func TestViewRecycling(t *testing.T) {
+	members := make([]evo.Genome, 1000)
+	for i := range members {
+		members[i] = point(i)
+	}
+	allocs := testing.AllocsPerRun(100, func() {
+		v := evo.NewView(members)
+		v.Stats()
+		v.Close()
+	})
+	if allocs >= 1 {
+		t.Errorf("%v allocations per view", allocs)
+	}
+
+	// recycled views hold only their own members
+	evo.NewView(members).Close()
+	v := evo.BuildView(3, func(i int) evo.Genome { return point(-i) })
+	if v.Len() != 3 || v.Stats().Max() != 0 || v.Stats().Min() != -2 {
+		t.Fail()
+	}
+	v.Close()
+}
+
+func TestSubviews(t *testing.T) {
+	// two stopped generational islands
+	islands := make([]evo.Genome, 2)
+	for i := range islands {
+		isl := new(gen.Population)
+		isl.Evolve([]evo.Genome{point(i), point(10 * i)}, func(g evo.Genome, _ []evo.Genome) evo.Genome {
+			return g
+		})
+		isl.Stop()
+		islands[i] = isl
+	}
+	view := evo.NewView(append(islands, point(100)))
+	defer view.Close()
+
+	subs := view.Subviews()
+	if len(subs) != 2 {
+		t.Fatal("wrong number of subviews")
+	}
+	if subs[0].Stats().Max() != 0 || subs[1].Stats().Max() != 10 || subs[1].Stats().Min() != 1 {
+		t.Error("wrong subview statistics")
+	}
+	for _, sub := range subs {
+		sub.Close()
+	}
+
+	stats := view.SubStats()
+	if len(stats) != 2 || stats[0].Max() != 0 || stats[1].Max() != 10 || stats[1].Count() != 2 {
+		t.Errorf("wrong substatistics %v", stats)
+	}
+
+	flat := view.Flatten()
+	if flat.Len() != 5 || flat.Stats().Max() != 100 || math.Abs(flat.Stats().Mean()-111.0/5) > 1e-9 {
+		t.Errorf("wrong flattened view %v", flat.Members())
+	}
+	flat.Close()
+}
+
+// word is a discrete genome.
+type word []int
+
+func (w word) Fitness() float64 { return 0 }
+
+func (w word) Alleles() []int { return w }
+
+func TestLocusEntropy(t *testing.T) {
+	view := evo.NewView([]evo.Genome{word{0, 1, 2}, word{0, 1, 3}, word{0, 2, 4}, word{0, 2, 5}, point(1)})
+	defer view.Close()
+	freqs := view.Frequencies()
+	if len(freqs) != 3 || freqs[0][0] != 1 || freqs[1][2] != 0.5 || freqs[2][5] != 0.25 {
+		t.Error("frequencies", freqs)
+	}
+	h := view.LocusEntropy()
+	if h[0] != 0 || math.Abs(h[1]-1) > 1e-9 || math.Abs(h[2]-2) > 1e-9 {
+		t.Error("entropy", h)
+	}
+}